@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"project-management/config"
+	"project-management/handlers"
+	"project-management/models"
+	"project-management/repositories"
+	"project-management/routes"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// TestProjectAuthorization checks that project roles and system role permissions are enforced
+// on every mutation and that users outside a private project cannot tell its tasks exist.
+// Needs a migrated database in TEST_DATABASE_URL.
+func TestProjectAuthorization(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping authorization integration tests")
+	}
+
+	t.Setenv("DATABASE_URL", databaseURL)
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	t.Setenv("JWT_SECRET", "authorization-test-secret")
+	if err := config.InitDB(); err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	defer config.CloseDB()
+	if err := config.InitJWTKeys(); err != nil {
+		t.Fatalf("InitJWTKeys: %v", err)
+	}
+
+	ctx := context.Background()
+	userRepo := repositories.NewUserRepository(config.DB)
+	projectRepo := repositories.NewProjectRepository(config.DB)
+	memberRepo := repositories.NewProjectMemberRepository(config.DB)
+	taskRepo := repositories.NewTaskRepository(config.DB)
+	timeLogRepo := repositories.NewTimeLogRepository(config.DB)
+	commentRepo := repositories.NewCommentRepository(config.DB)
+	roleRepo := repositories.NewRoleRepository(config.DB)
+	groupRepo := repositories.NewGroupRepository(config.DB)
+
+	suffix := uuid.New().String()[:8]
+	newUser := func(name, role string) *models.User {
+		now := time.Now()
+		user := &models.User{
+			Username:        name + "_" + suffix,
+			Email:           name + "_" + suffix + "@example.com",
+			EmailVerifiedAt: &now,
+			Role:            role,
+			IsActive:        true,
+		}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", name, err)
+		}
+		return user
+	}
+	// A custom role that may edit tasks in any project its holders can see
+	editorRole := &models.Role{Name: "editor_" + suffix, Permissions: []string{models.PermissionTaskEdit}}
+	if _, err := roleRepo.Create(ctx, editorRole); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+
+	owner := newUser("owner", models.SystemRoleUser)
+	viewer := newUser("viewer", models.SystemRoleUser)
+	outsider := newUser("outsider", models.SystemRoleUser)
+	editor := newUser("editor", editorRole.Name)
+	grouped := newUser("grouped", models.SystemRoleUser)
+
+	project, err := projectRepo.Create(ctx, models.CreateProjectRequest{
+		Title:      "Private project",
+		Status:     "active",
+		Identifier: "authz-" + suffix,
+	}, &owner.ID)
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	defer projectRepo.Delete(ctx, project.ID, owner.ID)
+	for _, user := range []*models.User{viewer, editor} {
+		if _, err := memberRepo.Add(ctx, project.ID, user.ID, models.ProjectRoleViewer); err != nil {
+			t.Fatalf("add viewer: %v", err)
+		}
+	}
+
+	// Group members get the group's project role
+	group := &models.Group{Name: "Developers " + suffix}
+	if err := groupRepo.Create(ctx, group); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	defer groupRepo.Delete(ctx, group.ID)
+	if _, err := groupRepo.AddMember(ctx, group.ID, grouped.ID); err != nil {
+		t.Fatalf("add group member: %v", err)
+	}
+	if _, err := memberRepo.AddGroup(ctx, project.ID, group.ID, models.ProjectRoleDeveloper); err != nil {
+		t.Fatalf("add project group: %v", err)
+	}
+
+	// A group without access to the project cannot be assigned its tasks
+	otherGroup := &models.Group{Name: "Outsiders " + suffix}
+	if err := groupRepo.Create(ctx, otherGroup); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	defer groupRepo.Delete(ctx, otherGroup.ID)
+	if _, err := groupRepo.AddMember(ctx, otherGroup.ID, outsider.ID); err != nil {
+		t.Fatalf("add group member: %v", err)
+	}
+
+	task, err := taskRepo.Create(ctx, project.ID, models.CreateTaskRequest{Title: "Task", Priority: "Medium"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	// Moving a task is refused, and leaves it where it was, when its group cannot work in the target project
+	targetProject, err := projectRepo.Create(ctx, models.CreateProjectRequest{
+		Title:      "Target project",
+		Status:     "active",
+		Identifier: "authz-target-" + suffix,
+	}, &owner.ID)
+	if err != nil {
+		t.Fatalf("create target project: %v", err)
+	}
+	defer projectRepo.Delete(ctx, targetProject.ID, owner.ID)
+	groupTask, err := taskRepo.Create(ctx, project.ID, models.CreateTaskRequest{Title: "Group task", Priority: "Low", AssigneeGroupID: &group.ID})
+	if err != nil {
+		t.Fatalf("create group task: %v", err)
+	}
+	timeLog, err := timeLogRepo.Create(ctx, task.ID, models.CreateTimeLogRequest{Date: time.Now(), DurationMinutes: 30})
+	if err != nil {
+		t.Fatalf("create time log: %v", err)
+	}
+	comment, err := commentRepo.Create(ctx, task.ID, owner.ID, models.CreateCommentRequest{Content: "Comment"})
+	if err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+
+	// A task in the trash is hidden until restored, and only its deleter may restore it
+	trashedTask, err := taskRepo.Create(ctx, project.ID, models.CreateTaskRequest{Title: "Trashed", Priority: "Low"})
+	if err != nil {
+		t.Fatalf("create trashed task: %v", err)
+	}
+	if err := taskRepo.Delete(ctx, trashedTask.ID, owner.ID); err != nil {
+		t.Fatalf("delete trashed task: %v", err)
+	}
+
+	// Only those who could still delete it may restore it; here the deleter has since lost access
+	formerTask, err := taskRepo.Create(ctx, project.ID, models.CreateTaskRequest{Title: "Former member's", Priority: "Low"})
+	if err != nil {
+		t.Fatalf("create former member's task: %v", err)
+	}
+	if err := taskRepo.Delete(ctx, formerTask.ID, outsider.ID); err != nil {
+		t.Fatalf("delete former member's task: %v", err)
+	}
+	formerSubproject, err := projectRepo.Create(ctx, models.CreateProjectRequest{
+		Title:      "Former member's subproject",
+		Status:     "active",
+		Identifier: "authz-former-" + suffix,
+		ParentID:   &project.ID,
+	}, &owner.ID)
+	if err != nil {
+		t.Fatalf("create former member's subproject: %v", err)
+	}
+	if err := projectRepo.Delete(ctx, formerSubproject.ID, outsider.ID); err != nil {
+		t.Fatalf("delete former member's subproject: %v", err)
+	}
+
+	app := newAuthorizationTestApp(userRepo, roleRepo, groupRepo, projectRepo, memberRepo, taskRepo, timeLogRepo, commentRepo)
+
+	taskBody := `{"title":"Changed","priority":"High"}`
+	timeLogBody := `{"date":"2024-01-01T00:00:00Z","duration_minutes":15}`
+	commentBody := `{"content":"Hello"}`
+	groupTaskBody := func(groupID uuid.UUID) string {
+		return fmt.Sprintf(`{"title":"Group task","priority":"Low","assignee_group_id":"%s"}`, groupID)
+	}
+	projectBody := fmt.Sprintf(`{"title":"Changed","status":"active","identifier":"authz-%s"}`, suffix)
+
+	cases := []struct {
+		name   string
+		user   *models.User
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		// Users outside a private project get 404 everywhere
+		{"outsider reads task", outsider, "GET", "/api/tasks/" + task.ID.String(), "", 404},
+		{"outsider creates task", outsider, "POST", "/api/projects/" + project.ID.String() + "/tasks", taskBody, 404},
+		{"outsider updates task", outsider, "PUT", "/api/tasks/" + task.ID.String(), taskBody, 404},
+		{"outsider toggles task", outsider, "PATCH", "/api/tasks/" + task.ID.String() + "/complete", "", 404},
+		{"outsider deletes task", outsider, "DELETE", "/api/tasks/" + task.ID.String(), "", 404},
+		{"outsider logs time", outsider, "POST", "/api/tasks/" + task.ID.String() + "/timelogs", timeLogBody, 404},
+		{"outsider deletes time log", outsider, "DELETE", "/api/timelogs/" + timeLog.ID.String(), "", 404},
+		{"outsider comments", outsider, "POST", "/api/tasks/" + task.ID.String() + "/comments", commentBody, 404},
+		{"outsider deletes comment", outsider, "DELETE", "/api/comments/" + comment.ID.String(), "", 404},
+		{"outsider updates project", outsider, "PUT", "/api/projects/" + project.ID.String(), projectBody, 404},
+		{"outsider deletes project", outsider, "DELETE", "/api/projects/" + project.ID.String(), "", 404},
+
+		// Viewers can read but not change anything
+		{"viewer reads task", viewer, "GET", "/api/tasks/" + task.ID.String(), "", 200},
+		{"viewer creates task", viewer, "POST", "/api/projects/" + project.ID.String() + "/tasks", taskBody, 403},
+		{"viewer updates task", viewer, "PUT", "/api/tasks/" + task.ID.String(), taskBody, 403},
+		{"viewer toggles task", viewer, "PATCH", "/api/tasks/" + task.ID.String() + "/complete", "", 403},
+		{"viewer deletes task", viewer, "DELETE", "/api/tasks/" + task.ID.String(), "", 403},
+		{"viewer logs time", viewer, "POST", "/api/tasks/" + task.ID.String() + "/timelogs", timeLogBody, 403},
+		{"viewer deletes time log", viewer, "DELETE", "/api/timelogs/" + timeLog.ID.String(), "", 403},
+		{"viewer comments", viewer, "POST", "/api/tasks/" + task.ID.String() + "/comments", commentBody, 403},
+		{"viewer deletes comment", viewer, "DELETE", "/api/comments/" + comment.ID.String(), "", 403},
+		{"viewer updates project", viewer, "PUT", "/api/projects/" + project.ID.String(), projectBody, 403},
+		{"viewer deletes project", viewer, "DELETE", "/api/projects/" + project.ID.String(), "", 403},
+
+		// System role permissions extend a low project role
+		{"editor updates task", editor, "PUT", "/api/tasks/" + task.ID.String(), taskBody, 200},
+		{"editor deletes task", editor, "DELETE", "/api/tasks/" + task.ID.String(), "", 403},
+
+		// Group membership grants the group's role
+		{"group developer logs time", grouped, "POST", "/api/tasks/" + task.ID.String() + "/timelogs", timeLogBody, 201},
+		{"group developer deletes task", grouped, "DELETE", "/api/tasks/" + task.ID.String(), "", 403},
+
+		// Tasks can only be assigned to groups that are members of the project
+		{"manager assigns project group", owner, "POST", "/api/projects/" + project.ID.String() + "/tasks", groupTaskBody(group.ID), 201},
+		{"manager assigns outside group", owner, "POST", "/api/projects/" + project.ID.String() + "/tasks", groupTaskBody(otherGroup.ID), 400},
+		{"manager reassigns to outside group", owner, "PUT", "/api/tasks/" + task.ID.String(), groupTaskBody(otherGroup.ID), 400},
+
+		{"manager moves group task", owner, "PUT", "/api/tasks/" + groupTask.ID.String(),
+			fmt.Sprintf(`{"title":"Group task","priority":"Low","assignee_group_id":"%s","project_id":"%s"}`, group.ID, targetProject.ID), 400},
+		{"group task keeps its key", owner, "GET", "/api/tasks/by-key/" + groupTask.Key, "", 200},
+
+		// Managers keep full control
+		{"manager toggles task", owner, "PATCH", "/api/tasks/" + task.ID.String() + "/complete", "", 200},
+		{"manager logs time", owner, "POST", "/api/tasks/" + task.ID.String() + "/timelogs", timeLogBody, 201},
+
+		// Deleted tasks are gone until their deleter restores them
+		{"manager reads deleted task", owner, "GET", "/api/tasks/" + trashedTask.ID.String(), "", 404},
+		{"viewer restores task", viewer, "POST", "/api/trash/tasks/" + trashedTask.ID.String() + "/restore", "", 404},
+		{"manager restores task", owner, "POST", "/api/trash/tasks/" + trashedTask.ID.String() + "/restore", "", 204},
+		{"manager reads restored task", owner, "GET", "/api/tasks/" + trashedTask.ID.String(), "", 200},
+		{"former member restores task", outsider, "POST", "/api/trash/tasks/" + formerTask.ID.String() + "/restore", "", 404},
+		{"former member restores subproject", outsider, "POST", "/api/trash/projects/" + formerSubproject.ID.String() + "/restore", "", 404},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: accessTokenFor(t, tc.user)})
+
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tc.want {
+				t.Errorf("%s %s: got status %d, want %d", tc.method, tc.path, resp.StatusCode, tc.want)
+			}
+		})
+	}
+
+	// An update that leaves out parent_id keeps the subproject under its parent
+	subproject, err := projectRepo.Create(ctx, models.CreateProjectRequest{
+		Title:      "Subproject",
+		Status:     "active",
+		Identifier: "authz-sub-" + suffix,
+		ParentID:   &project.ID,
+	}, &owner.ID)
+	if err != nil {
+		t.Fatalf("create subproject: %v", err)
+	}
+	defer projectRepo.Delete(ctx, subproject.ID, owner.ID)
+	req := httptest.NewRequest("PUT", "/api/projects/"+subproject.ID.String(), bytes.NewBufferString(fmt.Sprintf(`{"title":"Renamed","status":"active","identifier":"authz-sub-%s"}`, suffix)))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: accessTokenFor(t, owner)})
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("update subproject: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("update subproject: got status %d, want 200", resp.StatusCode)
+	}
+	updated, err := projectRepo.GetByID(ctx, subproject.ID)
+	if err != nil {
+		t.Fatalf("get subproject: %v", err)
+	}
+	if updated.ParentID == nil || *updated.ParentID != project.ID {
+		t.Errorf("parent after update = %v, want %s", updated.ParentID, project.ID)
+	}
+}
+
+// newAuthorizationTestApp wires the real project, task, time log and comment stack
+func newAuthorizationTestApp(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	groupRepo repositories.GroupRepository,
+	projectRepo *repositories.ProjectRepository,
+	memberRepo *repositories.ProjectMemberRepository,
+	taskRepo *repositories.TaskRepository,
+	timeLogRepo *repositories.TimeLogRepository,
+	commentRepo *repositories.CommentRepository,
+) *fiber.App {
+	statusRepo := repositories.NewProjectStatusRepository(config.DB)
+	access := services.NewProjectAccess(memberRepo, roleRepo, statusRepo)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	routes.SetupRoutes(app,
+		handlers.NewProjectHandler(services.NewProjectService(projectRepo, statusRepo, repositories.NewAttachmentRepository(config.DB), services.NewFileStorageService(), access)),
+		handlers.NewTaskHandler(services.NewTaskService(taskRepo, projectRepo, userRepo, groupRepo, repositories.NewVersionRepository(config.DB), access, nil)),
+		handlers.NewTimeLogHandler(services.NewTimeLogService(timeLogRepo, access)),
+		handlers.NewAuthHandler(nil, nil),
+		handlers.NewUserHandler(nil),
+		handlers.NewCommentHandler(services.NewCommentService(commentRepo, taskRepo, access)),
+		handlers.NewDashboardHandler(nil),
+		handlers.NewMeetingHandler(nil),
+		handlers.NewAttachmentHandler(nil),
+		handlers.NewPersonalAccessTokenHandler(nil),
+		handlers.NewInvitationHandler(nil),
+		handlers.NewAuditHandler(nil),
+		handlers.NewProjectMemberHandler(services.NewProjectMemberService(memberRepo, userRepo, groupRepo, access)),
+		handlers.NewRoleHandler(nil),
+		handlers.NewGroupHandler(nil),
+		handlers.NewShareLinkHandler(nil),
+		handlers.NewVersionHandler(nil),
+		handlers.NewProjectStatusHandler(nil),
+		handlers.NewTrashHandler(services.NewTrashService(repositories.NewTrashRepository(config.DB), access, nil, config.TrashRetentionDays)),
+	)
+	return app
+}
+
+func accessTokenFor(t *testing.T, user *models.User) string {
+	token, err := config.SignJWT(jwt.MapClaims{
+		"user_id": user.ID.String(),
+		"role":    user.Role,
+		"type":    "access",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"iat":     time.Now().Unix(),
+	})
+	if err != nil {
+		t.Fatalf("sign access token: %v", err)
+	}
+	return token
+}
@@ -7,7 +7,6 @@ import (
 
 // JWT Configuration
 var (
-	JWTSecret        = getEnv("JWT_SECRET", "")
 	JWTAccessExpiry  = parseDuration(getEnv("JWT_ACCESS_EXPIRY", "15m"))
 	JWTRefreshExpiry = parseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h"))
 )
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey is a key that verifies tokens, and signs them when the private half is known
+type JWTKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey // nil for verification-only keys
+	PublicKey  crypto.PublicKey  // []byte secret for HS256
+}
+
+var (
+	// JWTSigningKey signs every token issued by the API
+	JWTSigningKey *JWTKey
+
+	// JWTVerificationKeys holds the signing key plus retired or upcoming keys, by kid
+	JWTVerificationKeys map[string]*JWTKey
+
+	// JWTStateSecret signs short-lived OIDC state cookies
+	JWTStateSecret string
+)
+
+// InitJWTKeys loads the signing key and the extra verification keys kept for rotation.
+//
+// JWT_PRIVATE_KEY_FILE is a PEM RSA (RS256) or Ed25519 (EdDSA) private key.
+// JWT_VERIFICATION_KEY_FILES lists PEM public keys, comma separated, that are
+// still accepted and published in the JWKS. JWT_SECRET alone keeps the legacy
+// HS256 signing; next to a private key it only verifies tokens issued before
+// the switch.
+func InitJWTKeys() error {
+	privateKeyFile := os.Getenv("JWT_PRIVATE_KEY_FILE")
+	secret := os.Getenv("JWT_SECRET")
+
+	JWTVerificationKeys = map[string]*JWTKey{}
+
+	switch {
+	case privateKeyFile != "":
+		key, err := loadPrivateJWTKey(privateKeyFile)
+		if err != nil {
+			return err
+		}
+		JWTSigningKey = key
+		if secret != "" {
+			JWTVerificationKeys[""] = hmacJWTKey(secret)
+		}
+	case secret != "":
+		log.Println("Warning: JWT_PRIVATE_KEY_FILE is not set, signing with HS256; the JWKS endpoint will be empty")
+		JWTSigningKey = hmacJWTKey(secret)
+	default:
+		return errors.New("no JWT signing key configured: set JWT_PRIVATE_KEY_FILE (or JWT_SECRET for HS256)")
+	}
+	JWTVerificationKeys[JWTSigningKey.ID] = JWTSigningKey
+
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		key, err := loadPublicJWTKey(path)
+		if err != nil {
+			return err
+		}
+		JWTVerificationKeys[key.ID] = key
+	}
+
+	JWTStateSecret = secret
+	if JWTStateSecret == "" {
+		// A login may start on one replica and return to another, or span a restart
+		stateSecret, err := stateSecretFromKey(JWTSigningKey.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("deriving the OIDC state secret: %w", err)
+		}
+		JWTStateSecret = stateSecret
+	}
+
+	return nil
+}
+
+// stateSecretFromKey derives the OIDC state secret from the private signing key, so every process
+// holding the key accepts the state cookies the others issue
+func stateSecretFromKey(privateKey crypto.PrivateKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, der)
+	mac.Write([]byte("oidc-state"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// SignJWT signs the claims with the current signing key and sets its kid header
+func SignJWT(claims jwt.Claims) (string, error) {
+	if JWTSigningKey == nil {
+		return "", errors.New("JWT signing key not initialized")
+	}
+
+	token := jwt.NewWithClaims(JWTSigningKey.Method, claims)
+	if JWTSigningKey.ID != "" {
+		token.Header["kid"] = JWTSigningKey.ID
+	}
+	return token.SignedString(JWTSigningKey.PrivateKey)
+}
+
+// JWTKeyFunc picks the verification key by kid and rejects any other algorithm for it
+func JWTKeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := JWTVerificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.PublicKey, nil
+}
+
+// JWKS returns the public verification keys in JSON Web Key format
+func JWKS() []map[string]string {
+	keys := []map[string]string{}
+	for _, key := range JWTVerificationKeys {
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": key.Method.Alg(),
+				"kid": key.ID,
+				"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "OKP",
+				"use": "sig",
+				"alg": key.Method.Alg(),
+				"kid": key.ID,
+				"crv": "Ed25519",
+				"x":   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i]["kid"] < keys[j]["kid"] })
+	return keys
+}
+
+func hmacJWTKey(secret string) *JWTKey {
+	return &JWTKey{Method: jwt.SigningMethodHS256, PrivateKey: []byte(secret), PublicKey: []byte(secret)}
+}
+
+func loadPrivateJWTKey(path string) (*JWTKey, error) {
+	der, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateKey crypto.PrivateKey
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		privateKey = key
+	} else if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		privateKey = key
+	} else {
+		return nil, fmt.Errorf("%s: unsupported private key", path)
+	}
+
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return newAsymmetricJWTKey(jwt.SigningMethodRS256, key, &key.PublicKey)
+	case ed25519.PrivateKey:
+		return newAsymmetricJWTKey(jwt.SigningMethodEdDSA, key, key.Public())
+	default:
+		return nil, fmt.Errorf("%s: only RSA and Ed25519 keys are supported", path)
+	}
+}
+
+func loadPublicJWTKey(path string) (*JWTKey, error) {
+	der, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKey crypto.PublicKey
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		publicKey = key
+	} else if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		publicKey = key
+	} else {
+		return nil, fmt.Errorf("%s: unsupported public key", path)
+	}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return newAsymmetricJWTKey(jwt.SigningMethodRS256, nil, key)
+	case ed25519.PublicKey:
+		return newAsymmetricJWTKey(jwt.SigningMethodEdDSA, nil, key)
+	default:
+		return nil, fmt.Errorf("%s: only RSA and Ed25519 keys are supported", path)
+	}
+}
+
+// newAsymmetricJWTKey derives the kid from the public key so every service computes the same one
+func newAsymmetricJWTKey(method jwt.SigningMethod, privateKey crypto.PrivateKey, publicKey crypto.PublicKey) (*JWTKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+
+	return &JWTKey{
+		ID:         base64.RawURLEncoding.EncodeToString(sum[:12]),
+		Method:     method,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}, nil
+}
+
+func readPEM(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read JWT key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block.Bytes, nil
+}
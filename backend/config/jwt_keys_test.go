@@ -0,0 +1,136 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func parseWithKeys(tokenString string) error {
+	_, err := jwt.Parse(tokenString, JWTKeyFunc)
+	return err
+}
+
+func TestInitJWTKeysRequiresSigningKey(t *testing.T) {
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	t.Setenv("JWT_SECRET", "")
+
+	if err := InitJWTKeys(); err == nil {
+		t.Fatal("InitJWTKeys should fail without a signing key")
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	// The retired RSA key only verifies; the new Ed25519 key signs
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldPublicDER, _ := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	oldPublicPath := writePEM(t, dir, "old.pub.pem", "PUBLIC KEY", oldPublicDER)
+
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	newPrivateDER, _ := x509.MarshalPKCS8PrivateKey(newKey)
+	newPrivatePath := writePEM(t, dir, "new.pem", "PRIVATE KEY", newPrivateDER)
+
+	t.Setenv("JWT_PRIVATE_KEY_FILE", newPrivatePath)
+	t.Setenv("JWT_VERIFICATION_KEY_FILES", oldPublicPath)
+	t.Setenv("JWT_SECRET", "")
+	if err := InitJWTKeys(); err != nil {
+		t.Fatalf("InitJWTKeys: %v", err)
+	}
+
+	if JWTSigningKey.Method != jwt.SigningMethodEdDSA || JWTSigningKey.ID == "" {
+		t.Fatalf("signing key = %s/%q, want EdDSA with kid", JWTSigningKey.Method.Alg(), JWTSigningKey.ID)
+	}
+
+	signed, err := SignJWT(jwt.MapClaims{"sub": "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWithKeys(signed); err != nil {
+		t.Fatalf("token from current key rejected: %v", err)
+	}
+
+	oldJWK, err := newAsymmetricJWTKey(jwt.SigningMethodRS256, oldKey, &oldKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "old"})
+	oldToken.Header["kid"] = oldJWK.ID
+	oldSigned, _ := oldToken.SignedString(oldKey)
+	if err := parseWithKeys(oldSigned); err != nil {
+		t.Fatalf("token from retired key rejected: %v", err)
+	}
+
+	// Without a configured secret an HS256 token must not verify, with or without a kid
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "forged"})
+	forgedSigned, _ := forged.SignedString(oldPublicDER)
+	if err := parseWithKeys(forgedSigned); err == nil {
+		t.Fatal("HS256 token accepted without a legacy secret")
+	}
+	forged.Header["kid"] = oldJWK.ID
+	forgedSigned, _ = forged.SignedString(oldPublicDER)
+	if err := parseWithKeys(forgedSigned); err == nil {
+		t.Fatal("HS256 token accepted for an RSA kid")
+	}
+
+	jwks := JWKS()
+	if len(jwks) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(jwks))
+	}
+	for _, key := range jwks {
+		if key["kid"] == "" || key["use"] != "sig" {
+			t.Fatalf("incomplete JWK: %v", key)
+		}
+	}
+}
+
+func TestOIDCStateSecretFollowsSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	t.Setenv("JWT_PRIVATE_KEY_FILE", writePEM(t, dir, "key.pem", "PRIVATE KEY", der))
+	t.Setenv("JWT_VERIFICATION_KEY_FILES", "")
+	t.Setenv("JWT_SECRET", "")
+
+	// Replicas, and the same replica after a restart, load the same key and must agree
+	if err := InitJWTKeys(); err != nil {
+		t.Fatalf("InitJWTKeys: %v", err)
+	}
+	first := JWTStateSecret
+	if err := InitJWTKeys(); err != nil {
+		t.Fatalf("InitJWTKeys: %v", err)
+	}
+	if first == "" || JWTStateSecret != first {
+		t.Fatalf("state secret = %q after reload, want %q", JWTStateSecret, first)
+	}
+
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherDER, _ := x509.MarshalPKCS8PrivateKey(otherKey)
+	t.Setenv("JWT_PRIVATE_KEY_FILE", writePEM(t, dir, "other.pem", "PRIVATE KEY", otherDER))
+	if err := InitJWTKeys(); err != nil {
+		t.Fatalf("InitJWTKeys: %v", err)
+	}
+	if JWTStateSecret == first {
+		t.Fatal("state secret should differ for another signing key")
+	}
+}
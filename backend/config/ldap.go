@@ -0,0 +1,20 @@
+package config
+
+// LDAP / Active Directory configuration (disabled unless LDAP_URL is set)
+var (
+	LDAPURL                = getEnv("LDAP_URL", "")
+	LDAPStartTLS           = getEnv("LDAP_START_TLS", "false") == "true"
+	LDAPInsecureSkipVerify = getEnv("LDAP_INSECURE_SKIP_VERIFY", "false") == "true"
+	// Direct bind, e.g. "uid={username},ou=people,dc=example,dc=com" or "{username}@corp.example.com"
+	LDAPBindDNTemplate = getEnv("LDAP_BIND_DN_TEMPLATE", "")
+	// Search-then-bind (used when no template is set); service account may be empty for anonymous search
+	LDAPBindDN       = getEnv("LDAP_BIND_DN", "")
+	LDAPBindPassword = getEnv("LDAP_BIND_PASSWORD", "")
+	LDAPBaseDN       = getEnv("LDAP_BASE_DN", "")
+	LDAPUserFilter   = getEnv("LDAP_USER_FILTER", "(|(uid={username})(mail={username}))")
+	// Attribute mapping
+	LDAPUsernameAttribute = getEnv("LDAP_USERNAME_ATTRIBUTE", "uid")
+	LDAPEmailAttribute    = getEnv("LDAP_EMAIL_ATTRIBUTE", "mail")
+	LDAPGroupAttribute    = getEnv("LDAP_GROUP_ATTRIBUTE", "memberOf")
+	LDAPAdminGroups       = getEnv("LDAP_ADMIN_GROUPS", "")
+)
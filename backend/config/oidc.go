@@ -0,0 +1,14 @@
+package config
+
+// OIDC single sign-on configuration (disabled unless OIDC_ISSUER_URL is set)
+var (
+	OIDCIssuerURL     = getEnv("OIDC_ISSUER_URL", "")
+	OIDCClientID      = getEnv("OIDC_CLIENT_ID", "")
+	OIDCClientSecret  = getEnv("OIDC_CLIENT_SECRET", "")
+	OIDCRedirectURL   = getEnv("OIDC_REDIRECT_URL", APIURL+"/api/auth/oidc/callback")
+	OIDCScopes        = getEnv("OIDC_SCOPES", "openid,profile,email")
+	OIDCEmailClaim    = getEnv("OIDC_EMAIL_CLAIM", "email")
+	OIDCUsernameClaim = getEnv("OIDC_USERNAME_CLAIM", "preferred_username")
+	OIDCGroupsClaim   = getEnv("OIDC_GROUPS_CLAIM", "groups")
+	OIDCAdminGroups   = getEnv("OIDC_ADMIN_GROUPS", "")
+)
@@ -0,0 +1,28 @@
+package config
+
+import "strconv"
+
+// Password policy for local accounts
+var (
+	PasswordMinLength     = getEnvInt("PASSWORD_MIN_LENGTH", 8)
+	PasswordRequireUpper  = getEnv("PASSWORD_REQUIRE_UPPERCASE", "true") == "true"
+	PasswordRequireLower  = getEnv("PASSWORD_REQUIRE_LOWERCASE", "true") == "true"
+	PasswordRequireDigit  = getEnv("PASSWORD_REQUIRE_DIGIT", "true") == "true"
+	PasswordRequireSymbol = getEnv("PASSWORD_REQUIRE_SYMBOL", "false") == "true"
+	// Days before a password must be changed; 0 disables expiry
+	PasswordMaxAgeDays = getEnvInt("PASSWORD_MAX_AGE_DAYS", 0)
+	// Number of recent passwords (including the current one) that cannot be reused; 0 disables
+	PasswordHistorySize = getEnvInt("PASSWORD_HISTORY_SIZE", 5)
+	PasswordBcryptCost  = getEnvInt("PASSWORD_BCRYPT_COST", 10)
+	// Directory of k-anonymity range files (<SHA1 prefix>.txt with SUFFIX:COUNT lines); empty disables
+	PasswordBreachedListDir = getEnv("PASSWORD_BREACHED_LIST_DIR", "")
+)
+
+// Helper function to get an integer environment variable with default value
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,14 @@
+package config
+
+// Rate limit policies (requests per window, window in seconds)
+var (
+	// Every /api/auth route, per client IP
+	RateLimitAuthMax    = getEnvInt("RATE_LIMIT_AUTH_MAX", 10)
+	RateLimitAuthWindow = getEnvInt("RATE_LIMIT_AUTH_WINDOW_SECONDS", 60)
+	// Password and two-factor login attempts, per client IP
+	RateLimitLoginMax    = getEnvInt("RATE_LIMIT_LOGIN_MAX", 5)
+	RateLimitLoginWindow = getEnvInt("RATE_LIMIT_LOGIN_WINDOW_SECONDS", 300)
+	// Authenticated API routes, per user
+	RateLimitAPIMax    = getEnvInt("RATE_LIMIT_API_MAX", 300)
+	RateLimitAPIWindow = getEnvInt("RATE_LIMIT_API_WINDOW_SECONDS", 60)
+)
@@ -0,0 +1,4 @@
+package config
+
+// Trash retention: deleted projects and tasks are purged for good after this many days
+var TrashRetentionDays = getEnvInt("TRASH_RETENTION_DAYS", 30)
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"errors"
+
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// isAccessError reports whether err came from the project access check
+func isAccessError(err error) bool {
+	return errors.Is(err, models.ErrNotFound) || errors.Is(err, services.ErrProjectForbidden) ||
+		errors.Is(err, services.ErrProjectReadOnly)
+}
+
+// accessErrorResponse answers 403 when the project is visible but the role is too low or the
+// project is read-only, 404 otherwise
+func accessErrorResponse(c *fiber.Ctx, err error, notFoundMsg string) error {
+	if errors.Is(err, services.ErrProjectForbidden) || errors.Is(err, services.ErrProjectReadOnly) {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(404).JSON(fiber.Map{"error": notFoundMsg})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"project-management/middleware"
 	"project-management/services"
 
@@ -51,9 +53,14 @@ func (h *AttachmentHandler) UploadAttachments(c *fiber.Ctx) error {
 	}
 
 	// Process upload through service
-	response, err := h.service.UploadAttachments(c.Context(), taskID, files, &userContext.UserID)
+	response, err := h.service.UploadAttachments(c.Context(), taskID, files, &userContext.UserID, userContext.Role)
 	if err != nil {
 		// Check for specific error types
+		if errors.Is(err, services.ErrProjectReadOnly) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Project is read-only: attachments cannot be changed",
+			})
+		}
 		if err.Error() == "access denied: insufficient permissions" {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error": "Access denied: insufficient permissions to upload attachments to this task",
@@ -94,7 +101,7 @@ func (h *AttachmentHandler) ListAttachments(c *fiber.Ctx) error {
 	}
 
 	// Get attachments through service
-	response, err := h.service.GetAttachmentsByTaskID(c.Context(), taskID, &userContext.UserID)
+	response, err := h.service.GetAttachmentsByTaskID(c.Context(), taskID, &userContext.UserID, userContext.Role)
 	if err != nil {
 		// Check for specific error types
 		if err.Error() == "access denied: insufficient permissions" {
@@ -136,7 +143,7 @@ func (h *AttachmentHandler) DownloadAttachment(c *fiber.Ctx) error {
 	}
 
 	// Verify access and get attachment metadata
-	attachment, err := h.service.GetAttachmentByID(c.Context(), attachmentID, &userContext.UserID)
+	attachment, err := h.service.GetAttachmentByID(c.Context(), attachmentID, &userContext.UserID, userContext.Role)
 	if err != nil {
 		// Check for specific error types
 		if err.Error() == "access denied: insufficient permissions" {
@@ -186,7 +193,7 @@ func (h *AttachmentHandler) GetThumbnail(c *fiber.Ctx) error {
 	}
 
 	// Verify access and get attachment metadata
-	attachment, err := h.service.GetAttachmentByID(c.Context(), attachmentID, &userContext.UserID)
+	attachment, err := h.service.GetAttachmentByID(c.Context(), attachmentID, &userContext.UserID, userContext.Role)
 	if err != nil {
 		// Check for specific error types
 		if err.Error() == "access denied: insufficient permissions" {
@@ -241,9 +248,14 @@ func (h *AttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
 	}
 
 	// Delete attachment through service
-	err = h.service.DeleteAttachment(c.Context(), attachmentID, &userContext.UserID)
+	err = h.service.DeleteAttachment(c.Context(), attachmentID, &userContext.UserID, userContext.Role)
 	if err != nil {
 		// Check for specific error types
+		if errors.Is(err, services.ErrProjectReadOnly) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Project is read-only: attachments cannot be changed",
+			})
+		}
 		if err.Error() == "access denied: insufficient permissions to delete attachment" {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"error": "Access denied: insufficient permissions to delete this attachment",
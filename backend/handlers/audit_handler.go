@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"project-management/middleware"
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// auditContext carries the caller and client address to audit entries recorded by services
+func auditContext(c *fiber.Ctx) context.Context {
+	req := services.AuditRequest{
+		IPAddress: c.IP(),
+		UserAgent: c.Get("User-Agent"),
+	}
+	if userCtx, err := middleware.GetUserFromContext(c); err == nil {
+		actorID := userCtx.UserID
+		req.ActorID = &actorID
+	}
+	return services.WithAuditRequest(c.Context(), req)
+}
+
+// ListAuditEntries returns the filtered audit log, paginated or as CSV with ?format=csv (admin only)
+func (h *AuditHandler) ListAuditEntries(c *fiber.Ctx) error {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "فیلتر نامعتبر است",
+				"code":    "INVALID_FILTER",
+			},
+		})
+	}
+
+	if c.Query("format") == "csv" {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="audit-log-`+time.Now().Format("20060102-150405")+`.csv"`)
+		if err := h.auditService.ExportCSV(c.Context(), filter, c.Response().BodyWriter()); err != nil {
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			c.Response().ResetBody()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "خطا در دریافت گزارش رویدادها",
+					"code":    "SERVER_ERROR",
+				},
+			})
+		}
+		return nil
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit < 1 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	entries, total, err := h.auditService.ListEntries(c.Context(), filter, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "خطا در دریافت گزارش رویدادها",
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"entries": entries,
+			"pagination": fiber.Map{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": (total + limit - 1) / limit,
+			},
+		},
+	})
+}
+
+// VerifyAuditChain recomputes the hash chain to detect tampering (admin only)
+func (h *AuditHandler) VerifyAuditChain(c *fiber.Ctx) error {
+	status, err := h.auditService.VerifyChain(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "خطا در بررسی زنجیره رویدادها",
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// parseAuditFilter reads event_type, outcome, actor_id, target_id, from and to (RFC 3339 or YYYY-MM-DD)
+func parseAuditFilter(c *fiber.Ctx) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		EventType: c.Query("event_type"),
+		Outcome:   c.Query("outcome"),
+	}
+
+	for param, dest := range map[string]**uuid.UUID{"actor_id": &filter.ActorID, "target_id": &filter.TargetID} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return filter, err
+			}
+			*dest = &id
+		}
+	}
+
+	for param, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			t, err := parseTimeParam(value, param == "to")
+			if err != nil {
+				return filter, err
+			}
+			*dest = &t
+		}
+	}
+
+	return filter, nil
+}
+
+// parseTimeParam accepts a timestamp or a date; a date used as the upper bound includes that whole day
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -1,25 +1,33 @@
 package handlers
 
 import (
+	"errors"
 	"time"
 
+	"project-management/config"
 	"project-management/middleware"
 	"project-management/models"
 	"project-management/services"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
 	authService services.AuthService
+	oidcService services.OIDCService
 }
 
-func NewAuthHandler(authService services.AuthService) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, oidcService services.OIDCService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
+		oidcService: oidcService,
 	}
 }
 
+// oidcStateCookie holds the signed state/nonce/PKCE verifier between redirect and callback
+const oidcStateCookie = "oidc_state"
+
 // Register handles user registration
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req models.CreateUserRequest
@@ -34,8 +42,17 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Register user
-	user, accessToken, refreshToken, err := h.authService.Register(c.Context(), req)
+	// Get user agent and IP address for the session
+	userAgent := c.Get("User-Agent")
+	ipAddress := c.IP()
+
+	user, accessToken, refreshToken, err := h.authService.Register(c.Context(), req, userAgent, ipAddress)
 	if err != nil {
+		var policyErr *services.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyViolation(c, policyErr)
+		}
+
 		statusCode := fiber.StatusBadRequest
 		if err == services.ErrEmailExists {
 			statusCode = fiber.StatusConflict
@@ -51,25 +68,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Set httpOnly cookies
-	c.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    accessToken,
-		HTTPOnly: true,
-		Secure:   false, // Set to true in production with HTTPS
-		SameSite: "Lax",
-		Expires:  time.Now().Add(15 * time.Minute),
-		Path:     "/",
-	})
-
-	c.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		HTTPOnly: true,
-		Secure:   false, // Set to true in production with HTTPS
-		SameSite: "Lax",
-		Expires:  time.Now().Add(7 * 24 * time.Hour),
-		Path:     "/",
-	})
+	setAuthCookies(c, accessToken, refreshToken)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
@@ -97,13 +96,40 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	ipAddress := c.IP()
 
 	// Login user
-	user, accessToken, refreshToken, err := h.authService.Login(c.Context(), req, userAgent, ipAddress)
+	user, accessToken, refreshToken, err := h.authService.Login(auditContext(c), req, userAgent, ipAddress)
 	if err != nil {
+		// Password was correct, the client must complete the second step
+		var twoFactorErr *services.TwoFactorRequiredError
+		if errors.As(err, &twoFactorErr) {
+			return c.JSON(fiber.Map{
+				"success": true,
+				"data": fiber.Map{
+					"two_factor_required": true,
+					"challenge_token":     twoFactorErr.ChallengeToken,
+				},
+			})
+		}
+
+		// Password was correct but has expired, the client must choose a new one
+		var expiredErr *services.PasswordExpiredError
+		if errors.As(err, &expiredErr) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message":         err.Error(),
+					"code":            "PASSWORD_EXPIRED",
+					"challenge_token": expiredErr.ChallengeToken,
+				},
+			})
+		}
+
 		statusCode := fiber.StatusUnauthorized
 		if err == services.ErrAccountLocked {
 			statusCode = fiber.StatusForbidden
 		} else if err == services.ErrAccountDeactivated {
 			statusCode = fiber.StatusForbidden
+		} else if err == services.ErrLDAPUnavailable {
+			statusCode = fiber.StatusServiceUnavailable
 		}
 
 		return c.Status(statusCode).JSON(fiber.Map{
@@ -116,25 +142,50 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// Set httpOnly cookies
-	c.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    accessToken,
-		HTTPOnly: true,
-		Secure:   false, // Set to true in production with HTTPS
-		SameSite: "Lax",
-		Expires:  time.Now().Add(15 * time.Minute),
-		Path:     "/",
-	})
+	setAuthCookies(c, accessToken, refreshToken)
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		HTTPOnly: true,
-		Secure:   false, // Set to true in production with HTTPS
-		SameSite: "Lax",
-		Expires:  time.Now().Add(7 * 24 * time.Hour),
-		Path:     "/",
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"user":                      user,
+			"two_factor_setup_required": h.authService.TwoFactorSetupRequired(c.Context(), user),
+		},
 	})
+}
+
+// LoginTwoFactor completes a login with the challenge token and a TOTP or recovery code
+func (h *AuthHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	var req models.TwoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	userAgent := c.Get("User-Agent")
+	ipAddress := c.IP()
+
+	user, accessToken, refreshToken, err := h.authService.VerifyTwoFactorLogin(auditContext(c), req, userAgent, ipAddress)
+	if err != nil {
+		statusCode := fiber.StatusUnauthorized
+		if err == services.ErrAccountLocked || err == services.ErrAccountDeactivated {
+			statusCode = fiber.StatusForbidden
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "TWO_FACTOR_FAILED",
+			},
+		})
+	}
+
+	setAuthCookies(c, accessToken, refreshToken)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -144,6 +195,147 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	})
 }
 
+// ChangeExpiredPassword sets a new password with the challenge token from an expired-password login
+func (h *AuthHandler) ChangeExpiredPassword(c *fiber.Ctx) error {
+	var req models.ExpiredPasswordChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	user, accessToken, refreshToken, err := h.authService.ChangeExpiredPassword(auditContext(c), req, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		var policyErr *services.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyViolation(c, policyErr)
+		}
+
+		// The new password is saved; accounts with 2FA still need the second step
+		var twoFactorErr *services.TwoFactorRequiredError
+		if errors.As(err, &twoFactorErr) {
+			return c.JSON(fiber.Map{
+				"success": true,
+				"data": fiber.Map{
+					"two_factor_required": true,
+					"challenge_token":     twoFactorErr.ChallengeToken,
+				},
+			})
+		}
+
+		statusCode := fiber.StatusUnauthorized
+		if err == services.ErrAccountLocked || err == services.ErrAccountDeactivated {
+			statusCode = fiber.StatusForbidden
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "CHANGE_PASSWORD_FAILED",
+			},
+		})
+	}
+
+	setAuthCookies(c, accessToken, refreshToken)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"user":                      user,
+			"two_factor_setup_required": h.authService.TwoFactorSetupRequired(c.Context(), user),
+		},
+	})
+}
+
+// GetPasswordPolicy returns the password rules so forms can show them up front
+func (h *AuthHandler) GetPasswordPolicy(c *fiber.Ctx) error {
+	policy := h.authService.GetPasswordPolicy()
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"min_length":        policy.MinLength,
+			"require_uppercase": policy.RequireUpper,
+			"require_lowercase": policy.RequireLower,
+			"require_digit":     policy.RequireDigit,
+			"require_symbol":    policy.RequireSymbol,
+			"max_age_days":      int(policy.MaxAge.Hours() / 24),
+			"history_size":      policy.HistorySize,
+			"breached_check":    policy.Breached != nil,
+		},
+	})
+}
+
+// passwordPolicyViolation reports every failed password rule with its code
+func passwordPolicyViolation(c *fiber.Ctx, err *services.PasswordPolicyError) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message":    err.Error(),
+			"code":       services.PasswordPolicyViolated,
+			"violations": err.Violations,
+		},
+	})
+}
+
+// GetJWKS publishes the token verification keys as a JSON Web Key Set
+func (h *AuthHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set("Cache-Control", "public, max-age=300")
+	return c.JSON(fiber.Map{
+		"keys": config.JWKS(),
+	})
+}
+
+// RefreshToken rotates the refresh_token cookie and issues a new access token
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	refreshToken := c.Cookies("refresh_token")
+	if refreshToken == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "احراز هویت نشده است",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	userAgent := c.Get("User-Agent")
+	ipAddress := c.IP()
+
+	accessToken, newRefreshToken, err := h.authService.RefreshToken(auditContext(c), refreshToken, userAgent, ipAddress)
+	if err != nil {
+		// The presented token is unusable either way, drop it from the browser
+		clearAuthCookies(c)
+
+		code := "REFRESH_FAILED"
+		if err == services.ErrTokenReused {
+			code = "TOKEN_REUSED"
+		}
+
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    code,
+			},
+		})
+	}
+
+	setAuthCookies(c, accessToken, newRefreshToken)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "توکن با موفقیت تمدید شد",
+		},
+	})
+}
+
 // GetCurrentUser returns the current authenticated user
 func (h *AuthHandler) GetCurrentUser(c *fiber.Ctx) error {
 	userContext, err := middleware.GetUserFromContext(c)
@@ -174,29 +366,11 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	// Revoke session in database if refresh token exists
 	if refreshToken != "" {
 		// Note: We don't fail logout if revocation fails (best effort)
-		_ = h.authService.RevokeSession(c.Context(), refreshToken)
+		_ = h.authService.RevokeSession(auditContext(c), refreshToken)
 	}
 
 	// Clear cookies
-	c.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    "",
-		HTTPOnly: true,
-		Secure:   false,
-		SameSite: "Lax",
-		Expires:  time.Now().Add(-1 * time.Hour),
-		Path:     "/",
-	})
-
-	c.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    "",
-		HTTPOnly: true,
-		Secure:   false,
-		SameSite: "Lax",
-		Expires:  time.Now().Add(-1 * time.Hour),
-		Path:     "/",
-	})
+	clearAuthCookies(c)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -223,7 +397,7 @@ func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
 	}
 
 	// Call service (always returns success to prevent email enumeration)
-	err := h.authService.RequestPasswordReset(c.Context(), req.Email)
+	err := h.authService.RequestPasswordReset(auditContext(c), req.Email)
 	if err != nil {
 		// Log error but don't expose to user
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -262,8 +436,13 @@ func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
 	}
 
 	// Validate token and reset password
-	err := h.authService.ResetPassword(c.Context(), req.Token, req.NewPassword)
+	err := h.authService.ResetPassword(auditContext(c), req.Token, req.NewPassword)
 	if err != nil {
+		var policyErr *services.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyViolation(c, policyErr)
+		}
+
 		statusCode := fiber.StatusBadRequest
 		if err == services.ErrInvalidToken {
 			statusCode = fiber.StatusUnauthorized
@@ -286,6 +465,89 @@ func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
 	})
 }
 
+// VerifyEmail confirms an email address with the token from the verification link
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.authService.VerifyEmail(c.Context(), req.Token); err != nil {
+		statusCode := fiber.StatusInternalServerError
+		message := "خطا در تأیید ایمیل"
+		if err == services.ErrInvalidToken {
+			statusCode = fiber.StatusBadRequest
+			message = err.Error()
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": message,
+				"code":    "EMAIL_VERIFICATION_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "ایمیل شما با موفقیت تأیید شد",
+		},
+	})
+}
+
+// ResendVerificationEmail sends a new verification link to the current user
+func (h *AuthHandler) ResendVerificationEmail(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	if err := h.authService.ResendVerificationEmail(c.Context(), userCtx.UserID); err != nil {
+		statusCode := fiber.StatusInternalServerError
+		code := "RESEND_FAILED"
+		message := "خطا در ارسال ایمیل تأیید"
+		switch err {
+		case services.ErrEmailAlreadyVerified:
+			statusCode = fiber.StatusBadRequest
+			code = "EMAIL_ALREADY_VERIFIED"
+			message = err.Error()
+		case services.ErrVerificationRateLimited:
+			statusCode = fiber.StatusTooManyRequests
+			code = "RATE_LIMITED"
+			message = err.Error()
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": message,
+				"code":    code,
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "ایمیل تأیید ارسال شد",
+		},
+	})
+}
+
 // UpdateProfile handles user profile updates
 func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 	userCtx, err := middleware.GetUserFromContext(c)
@@ -358,8 +620,13 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.authService.ChangePassword(c.Context(), userCtx.UserID, req)
+	accessToken, refreshToken, err := h.authService.ChangePassword(auditContext(c), userCtx.UserID, req, c.Get("User-Agent"), c.IP())
 	if err != nil {
+		var policyErr *services.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyViolation(c, policyErr)
+		}
+
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
@@ -369,6 +636,9 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
+	// Other sessions were revoked; keep this device signed in
+	setAuthCookies(c, accessToken, refreshToken)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data": fiber.Map{
@@ -376,3 +646,442 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// ListSessions returns the active sessions of the current user
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	sessions, err := h.authService.ListSessions(c.Context(), userCtx.UserID, c.Cookies("refresh_token"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"sessions": sessions,
+		},
+	})
+}
+
+// RevokeSession signs the current user out of one of their sessions
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه نشست نامعتبر است",
+				"code":    "INVALID_SESSION_ID",
+			},
+		})
+	}
+
+	err = h.authService.RevokeUserSession(c.Context(), userCtx.UserID, sessionID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		code := "SERVER_ERROR"
+		if err == services.ErrSessionNotFound {
+			status = fiber.StatusNotFound
+			code = "SESSION_NOT_FOUND"
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    code,
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "نشست با موفقیت خاتمه یافت",
+		},
+	})
+}
+
+// RevokeOtherSessions signs the current user out of every other device
+func (h *AuthHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(c.Context(), userCtx.UserID, c.Cookies("refresh_token"))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		code := "SERVER_ERROR"
+		if err == services.ErrSessionNotFound {
+			status = fiber.StatusBadRequest
+			code = "CURRENT_SESSION_UNKNOWN"
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    code,
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"revoked": revoked,
+		},
+	})
+}
+
+// GetOIDCStatus tells the login page whether single sign-on is available
+func (h *AuthHandler) GetOIDCStatus(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"enabled":   h.oidcService.Enabled(),
+			"login_url": "/api/auth/oidc/login",
+		},
+	})
+}
+
+// OIDCLogin redirects the browser to the identity provider
+func (h *AuthHandler) OIDCLogin(c *fiber.Ctx) error {
+	authURL, stateToken, err := h.oidcService.BeginLogin(c.Context())
+	if err != nil {
+		return redirectWithSSOError(c, "SSO_UNAVAILABLE")
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateToken,
+		HTTPOnly: true,
+		Secure:   false, // Set to true in production with HTTPS
+		SameSite: "Lax", // Must be sent on the top-level redirect back from the provider
+		Expires:  time.Now().Add(10 * time.Minute),
+		Path:     "/api/auth/oidc",
+	})
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback completes the authorization code flow and signs the user in
+func (h *AuthHandler) OIDCCallback(c *fiber.Ctx) error {
+	stateToken := c.Cookies(oidcStateCookie)
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+		Expires:  time.Now().Add(-1 * time.Hour),
+		Path:     "/api/auth/oidc",
+	})
+
+	// The provider reports denied consent and similar failures via the error parameter
+	if c.Query("error") != "" {
+		return redirectWithSSOError(c, "SSO_DENIED")
+	}
+
+	identity, err := h.oidcService.CompleteLogin(c.Context(), stateToken, c.Query("state"), c.Query("code"))
+	if err != nil {
+		return redirectWithSSOError(c, "SSO_FAILED")
+	}
+
+	_, accessToken, refreshToken, err := h.authService.LoginWithExternalIdentity(auditContext(c), *identity, c.Get("User-Agent"), c.IP())
+	if err != nil {
+		code := "SSO_FAILED"
+		switch err {
+		case services.ErrAccountDeactivated:
+			code = "ACCOUNT_DEACTIVATED"
+		case services.ErrExternalEmailConflict:
+			code = "SSO_EMAIL_CONFLICT"
+		}
+		return redirectWithSSOError(c, code)
+	}
+
+	setAuthCookies(c, accessToken, refreshToken)
+
+	return c.Redirect(config.AppURL, fiber.StatusFound)
+}
+
+// redirectWithSSOError sends the browser back to the app with an error code to display
+func redirectWithSSOError(c *fiber.Ctx, code string) error {
+	return c.Redirect(config.AppURL+"/?sso_error="+code, fiber.StatusFound)
+}
+
+// GetTwoFactorStatus returns the 2FA state of the current user
+func (h *AuthHandler) GetTwoFactorStatus(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	status, err := h.authService.GetTwoFactorStatus(c.Context(), userCtx.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// BeginTwoFactorSetup starts 2FA enrollment and returns the QR provisioning URI
+func (h *AuthHandler) BeginTwoFactorSetup(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	setup, err := h.authService.BeginTwoFactorSetup(c.Context(), userCtx.UserID)
+	if err != nil {
+		statusCode := fiber.StatusBadRequest
+		if err == services.ErrTwoFactorAlreadyEnabled {
+			statusCode = fiber.StatusConflict
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "TWO_FACTOR_SETUP_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    setup,
+	})
+}
+
+// ConfirmTwoFactorSetup enables 2FA and returns one-time recovery codes
+func (h *AuthHandler) ConfirmTwoFactorSetup(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	recoveryCodes, err := h.authService.ConfirmTwoFactorSetup(auditContext(c), userCtx.UserID, req.Code)
+	if err != nil {
+		statusCode := fiber.StatusBadRequest
+		if err == services.ErrTwoFactorAlreadyEnabled {
+			statusCode = fiber.StatusConflict
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "TWO_FACTOR_CONFIRM_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+// DisableTwoFactor turns off 2FA for the current user
+func (h *AuthHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req models.DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	if err := h.authService.DisableTwoFactor(auditContext(c), userCtx.UserID, req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "TWO_FACTOR_DISABLE_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "تأیید دو مرحله‌ای غیرفعال شد",
+		},
+	})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	recoveryCodes, err := h.authService.RegenerateRecoveryCodes(c.Context(), userCtx.UserID, req.Code)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "RECOVERY_CODES_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+// setAuthCookies sets the httpOnly access and refresh token cookies
+func setAuthCookies(c *fiber.Ctx, accessToken, refreshToken string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "access_token",
+		Value:    accessToken,
+		HTTPOnly: true,
+		Secure:   false, // Set to true in production with HTTPS
+		SameSite: "Lax",
+		Expires:  time.Now().Add(config.JWTAccessExpiry),
+		Path:     "/",
+	})
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		HTTPOnly: true,
+		Secure:   false, // Set to true in production with HTTPS
+		SameSite: "Lax",
+		Expires:  time.Now().Add(config.JWTRefreshExpiry),
+		Path:     "/",
+	})
+}
+
+// clearAuthCookies expires the access and refresh token cookies
+func clearAuthCookies(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     "access_token",
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+		Expires:  time.Now().Add(-1 * time.Hour),
+		Path:     "/",
+	})
+
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+		Expires:  time.Now().Add(-1 * time.Hour),
+		Path:     "/",
+	})
+}
@@ -29,7 +29,18 @@ func (h *CommentHandler) GetCommentsByTask(c *fiber.Ctx) error {
 		})
 	}
 
-	comments, err := h.service.GetCommentsByTaskIDWithUser(c.Context(), taskID)
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "احراز هویت نشده است",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	comments, err := h.service.GetCommentsByTaskIDWithUser(c.Context(), taskID, userContext.UserID, userContext.Role)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"success": false,
@@ -82,11 +93,13 @@ func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
 		})
 	}
 
-	comment, err := h.service.CreateComment(c.Context(), taskID, userContext.UserID, req)
+	comment, err := h.service.CreateComment(c.Context(), taskID, userContext.UserID, userContext.Role, req)
 	if err != nil {
 		statusCode := fiber.StatusInternalServerError
 		if err == services.ErrCommentNotFound {
 			statusCode = fiber.StatusNotFound
+		} else if err == services.ErrCommentForbidden || err == services.ErrCommentReadOnly {
+			statusCode = fiber.StatusForbidden
 		}
 
 		return c.Status(statusCode).JSON(fiber.Map{
@@ -140,12 +153,12 @@ func (h *CommentHandler) UpdateComment(c *fiber.Ctx) error {
 		})
 	}
 
-	comment, err := h.service.UpdateComment(c.Context(), id, userContext.UserID, req)
+	comment, err := h.service.UpdateComment(c.Context(), id, userContext.UserID, userContext.Role, req)
 	if err != nil {
 		statusCode := fiber.StatusInternalServerError
 		if err == services.ErrCommentNotFound {
 			statusCode = fiber.StatusNotFound
-		} else if err == services.ErrCommentUnauthorized {
+		} else if err == services.ErrCommentUnauthorized || err == services.ErrCommentForbidden || err == services.ErrCommentReadOnly {
 			statusCode = fiber.StatusForbidden
 		}
 
@@ -189,12 +202,12 @@ func (h *CommentHandler) DeleteComment(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.service.DeleteComment(c.Context(), id, userContext.UserID)
+	err = h.service.DeleteComment(c.Context(), id, userContext.UserID, userContext.Role)
 	if err != nil {
 		statusCode := fiber.StatusInternalServerError
 		if err == services.ErrCommentNotFound {
 			statusCode = fiber.StatusNotFound
-		} else if err == services.ErrCommentUnauthorized {
+		} else if err == services.ErrCommentUnauthorized || err == services.ErrCommentForbidden || err == services.ErrCommentReadOnly {
 			statusCode = fiber.StatusForbidden
 		}
 
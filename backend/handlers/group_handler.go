@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type GroupHandler struct {
+	groupService services.GroupService
+}
+
+func NewGroupHandler(groupService services.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+	}
+}
+
+// ListGroups returns every group (used by pickers as well as administration)
+func (h *GroupHandler) ListGroups(c *fiber.Ctx) error {
+	groups, err := h.groupService.ListGroups(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "خطا در دریافت لیست گروه‌ها",
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"groups": groups,
+		},
+	})
+}
+
+// CreateGroup defines a new group (group.manage)
+func (h *GroupHandler) CreateGroup(c *fiber.Ctx) error {
+	var req models.CreateGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	group, err := h.groupService.CreateGroup(c.Context(), req)
+	if err != nil {
+		return groupError(c, err, "CREATE_GROUP_FAILED")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"group": group,
+		},
+	})
+}
+
+// UpdateGroup renames a group or changes its description (group.manage)
+func (h *GroupHandler) UpdateGroup(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	var req models.UpdateGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	group, err := h.groupService.UpdateGroup(c.Context(), groupID, req)
+	if err != nil {
+		return groupError(c, err, "UPDATE_GROUP_FAILED")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"group": group,
+		},
+	})
+}
+
+// DeleteGroup removes a group; tasks assigned to it become unassigned (group.manage)
+func (h *GroupHandler) DeleteGroup(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	if err := h.groupService.DeleteGroup(c.Context(), groupID); err != nil {
+		return groupError(c, err, "DELETE_GROUP_FAILED")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "گروه حذف شد",
+		},
+	})
+}
+
+// ListMembers returns the users in a group (group.manage)
+func (h *GroupHandler) ListMembers(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	members, err := h.groupService.ListMembers(c.Context(), groupID)
+	if err != nil {
+		return groupError(c, err, "LIST_GROUP_MEMBERS_FAILED")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"members": members,
+		},
+	})
+}
+
+// AddMember adds a user to a group (group.manage)
+func (h *GroupHandler) AddMember(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	var req models.AddGroupMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	members, err := h.groupService.AddMember(c.Context(), groupID, req)
+	if err != nil {
+		return groupError(c, err, "ADD_GROUP_MEMBER_FAILED")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"members": members,
+		},
+	})
+}
+
+// RemoveMember removes a user from a group (group.manage)
+func (h *GroupHandler) RemoveMember(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return invalidGroupRequest(c)
+	}
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return invalidGroupRequest(c)
+	}
+
+	if err := h.groupService.RemoveMember(c.Context(), groupID, userID); err != nil {
+		return groupError(c, err, "REMOVE_GROUP_MEMBER_FAILED")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "کاربر از گروه حذف شد",
+		},
+	})
+}
+
+func invalidGroupRequest(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message": "درخواست نامعتبر است",
+			"code":    "INVALID_REQUEST",
+		},
+	})
+}
+
+// groupError maps group service errors; unexpected errors are not echoed to the client
+func groupError(c *fiber.Ctx, err error, code string) error {
+	status := fiber.StatusInternalServerError
+	message := "خطا در مدیریت گروه‌ها"
+
+	switch err {
+	case services.ErrGroupNotFound, services.ErrGroupMemberNotFound:
+		status, message = fiber.StatusNotFound, err.Error()
+	case services.ErrGroupExists, services.ErrGroupMemberExists:
+		status, message = fiber.StatusConflict, err.Error()
+	case services.ErrInvalidGroupName, services.ErrGroupMemberUser:
+		status, message = fiber.StatusBadRequest, err.Error()
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"message": message,
+			"code":    code,
+		},
+	})
+}
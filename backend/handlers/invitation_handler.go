@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+
+	"project-management/middleware"
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type InvitationHandler struct {
+	invitationService services.InvitationService
+}
+
+func NewInvitationHandler(invitationService services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+	}
+}
+
+// ListInvitations returns all invitations with their status (admin only)
+func (h *InvitationHandler) ListInvitations(c *fiber.Ctx) error {
+	invitations, err := h.invitationService.ListInvitations(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "خطا در دریافت لیست دعوت‌نامه‌ها",
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"invitations": invitations,
+		},
+	})
+}
+
+// CreateInvitation invites an email address with a preset role (admin only)
+func (h *InvitationHandler) CreateInvitation(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req models.CreateInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	invitation, err := h.invitationService.CreateInvitation(c.Context(), userCtx.UserID, req)
+	if err != nil {
+		statusCode := fiber.StatusBadRequest
+		if err == services.ErrEmailExists || err == services.ErrInvitationPending {
+			statusCode = fiber.StatusConflict
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "INVITATION_FAILED",
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"invitation": invitation,
+		},
+	})
+}
+
+// ResendInvitation emails a new link for an open invitation (admin only)
+func (h *InvitationHandler) ResendInvitation(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه دعوت‌نامه نامعتبر است",
+				"code":    "INVALID_INVITATION_ID",
+			},
+		})
+	}
+
+	invitation, err := h.invitationService.ResendInvitation(c.Context(), id)
+	if err != nil {
+		return c.Status(invitationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "RESEND_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"invitation": invitation,
+		},
+	})
+}
+
+// RevokeInvitation revokes an open invitation (admin only)
+func (h *InvitationHandler) RevokeInvitation(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه دعوت‌نامه نامعتبر است",
+				"code":    "INVALID_INVITATION_ID",
+			},
+		})
+	}
+
+	if err := h.invitationService.RevokeInvitation(c.Context(), id); err != nil {
+		return c.Status(invitationErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "REVOKE_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "دعوت‌نامه لغو شد",
+		},
+	})
+}
+
+// PreviewInvitation returns the invited email and role for the accept page
+func (h *InvitationHandler) PreviewInvitation(c *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	preview, err := h.invitationService.GetInvitationPreview(c.Context(), req.Token)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "INVALID_INVITATION",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"invitation": preview,
+		},
+	})
+}
+
+// AcceptInvitation activates the invited account with the chosen username and password
+func (h *InvitationHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req models.AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	user, err := h.invitationService.AcceptInvitation(c.Context(), req)
+	if err != nil {
+		var policyErr *services.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return passwordPolicyViolation(c, policyErr)
+		}
+
+		statusCode := fiber.StatusBadRequest
+		if err == services.ErrEmailExists || err == services.ErrUsernameExists {
+			statusCode = fiber.StatusConflict
+		}
+
+		return c.Status(statusCode).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "ACCEPT_INVITATION_FAILED",
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"user":    user,
+			"message": "حساب کاربری شما فعال شد. اکنون می‌توانید وارد شوید",
+		},
+	})
+}
+
+func invitationErrorStatus(err error) int {
+	switch err {
+	case services.ErrInvitationNotFound:
+		return fiber.StatusNotFound
+	case services.ErrInvitationClosed:
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"project-management/middleware"
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenService services.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(tokenService services.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// ListTokens returns the current user's personal access tokens
+func (h *PersonalAccessTokenHandler) ListTokens(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	tokens, err := h.tokenService.ListTokens(c.Context(), userCtx.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"tokens": tokens,
+		},
+	})
+}
+
+// CreateToken creates a personal access token; the plaintext token is only returned once
+func (h *PersonalAccessTokenHandler) CreateToken(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	var req models.CreatePersonalAccessTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	token, err := h.tokenService.CreateToken(c.Context(), userCtx.UserID, userCtx.Role, req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		code := "CREATE_TOKEN_FAILED"
+		if err == services.ErrTokenProjectForbidden {
+			status = fiber.StatusForbidden
+			code = "TOKEN_PROJECT_FORBIDDEN"
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    code,
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"token": token,
+		},
+	})
+}
+
+// RevokeToken revokes one of the current user's personal access tokens
+func (h *PersonalAccessTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	userCtx, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "دسترسی غیرمجاز",
+				"code":    "UNAUTHORIZED",
+			},
+		})
+	}
+
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه توکن نامعتبر است",
+				"code":    "INVALID_TOKEN_ID",
+			},
+		})
+	}
+
+	err = h.tokenService.RevokeToken(c.Context(), userCtx.UserID, tokenID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		code := "SERVER_ERROR"
+		if err == services.ErrTokenNotFound {
+			status = fiber.StatusNotFound
+			code = "TOKEN_NOT_FOUND"
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    code,
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "توکن با موفقیت باطل شد",
+		},
+	})
+}
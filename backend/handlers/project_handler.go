@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"project-management/middleware"
 	"project-management/models"
+	"project-management/repositories"
 	"project-management/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,6 +24,8 @@ func NewProjectHandler(service *services.ProjectService) *ProjectHandler {
 	return &ProjectHandler{service: service}
 }
 
+// GetAllProjects lists the visible projects matching the query filters. Passing limit or cursor
+// switches to cursor pagination and a {projects, next_cursor, has_more} response.
 func (h *ProjectHandler) GetAllProjects(c *fiber.Ctx) error {
 	// Get user from context (set by RequireAuth middleware)
 	userContext, err := middleware.GetUserFromContext(c)
@@ -24,12 +33,109 @@ func (h *ProjectHandler) GetAllProjects(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
 
-	// Get projects filtered by user role
-	projects, err := h.service.GetProjectsByUser(c.Context(), userContext.UserID, userContext.Role)
+	filter, err := parseProjectFilter(c)
 	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// Personal access tokens may be limited to specific projects
+	if userContext.IsTokenAuth() {
+		filter.ProjectIDs = userContext.TokenProjectIDs
+	}
+
+	// ?tree=true nests subprojects under their parents and is never paginated
+	paginated := (c.Query("limit") != "" || c.Query("cursor") != "") && !c.QueryBool("tree")
+	if paginated {
+		filter.Limit = services.ProjectPageSize(c.QueryInt("limit", 0))
+	} else {
+		filter.After = nil
+	}
+
+	page, err := h.service.ListProjects(c.Context(), userContext.UserID, userContext.Role, filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProjectSort) || errors.Is(err, services.ErrInvalidProjectCursor) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "failed to fetch projects"})
 	}
-	return c.JSON(projects)
+
+	if c.QueryBool("tree") {
+		return c.JSON(services.BuildProjectTree(page.Projects))
+	}
+	if paginated {
+		return c.JSON(page)
+	}
+	return c.JSON(page.Projects)
+}
+
+// parseProjectFilter reads the listing filters; the default order is open projects first, newest first
+func parseProjectFilter(c *fiber.Ctx) (models.ProjectFilter, error) {
+	filter := models.ProjectFilter{
+		Query:     strings.TrimSpace(c.Query("q")),
+		Sort:      c.Query("sort", "created_at"),
+		OpenFirst: c.Query("sort") == "",
+	}
+
+	switch c.Query("order") {
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	case "":
+		filter.Descending = c.Query("sort") == ""
+	default:
+		return filter, errors.New("order must be asc or desc")
+	}
+
+	// ?status=active,on-hold matches any of the listed statuses
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	for param, dest := range map[string]**bool{"closed": &filter.Closed, "is_public": &filter.IsPublic} {
+		if value := c.Query(param); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*dest = &b
+		}
+	}
+
+	if value := c.Query("owner_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errors.New("invalid owner_id")
+		}
+		filter.OwnerID = &id
+	}
+
+	for param, dest := range map[string]**time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+		"due_from":     &filter.DueFrom,
+		"due_to":       &filter.DueTo,
+	} {
+		if value := c.Query(param); value != "" {
+			// Due bounds are whole days, so only created_to is pushed to the end of the day
+			t, err := parseTimeParam(value, param == "created_to")
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*dest = &t
+		}
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := services.DecodeProjectCursor(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = cursor
+	}
+
+	return filter, nil
 }
 
 func (h *ProjectHandler) CreateProject(c *fiber.Ctx) error {
@@ -44,8 +150,14 @@ func (h *ProjectHandler) CreateProject(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
 
-	project, err := h.service.CreateProject(c.Context(), req, &userContext.UserID)
+	project, err := h.service.CreateProject(c.Context(), req, &userContext.UserID, userContext.Role)
 	if err != nil {
+		if errors.Is(err, services.ErrProjectForbidden) {
+			return c.Status(403).JSON(fiber.Map{"error": "only managers of the parent project can add subprojects"})
+		}
+		if errors.Is(err, repositories.ErrProjectIdentifierTaken) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
 		if err == models.ErrValidation {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -56,13 +168,75 @@ func (h *ProjectHandler) CreateProject(c *fiber.Ctx) error {
 	return c.Status(201).JSON(project)
 }
 
+// GetTemplates lists the project templates new projects can be copied from
+func (h *ProjectHandler) GetTemplates(c *fiber.Ctx) error {
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	var projectIDs []uuid.UUID
+	if userContext.IsTokenAuth() {
+		projectIDs = userContext.TokenProjectIDs
+	}
+
+	templates, err := h.service.GetTemplates(c.Context(), userContext.UserID, userContext.Role, projectIDs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "failed to fetch templates"})
+	}
+
+	return c.JSON(templates)
+}
+
+// CopyProject creates a new project from a project or template (project.create)
+func (h *ProjectHandler) CopyProject(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	var req models.CopyProjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	// Like creating projects, copying is out of scope for project-limited tokens
+	if len(userContext.TokenProjectIDs) > 0 {
+		return c.Status(403).JSON(fiber.Map{"error": "this token cannot create projects"})
+	}
+
+	project, err := h.service.CopyProject(c.Context(), id, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "project not found")
+		}
+		if errors.Is(err, repositories.ErrProjectIdentifierTaken) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		// Handle validation errors from service layer (identifier, status, parent, options)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(project)
+}
+
 func (h *ProjectHandler) GetProject(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
 	}
 
-	project, err := h.service.GetProjectByID(c.Context(), id)
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	project, err := h.service.GetProjectByID(c.Context(), id, userContext.UserID, userContext.Role)
 	if err != nil || project == nil {
 		return c.Status(404).JSON(fiber.Map{"error": "project not found"})
 	}
@@ -81,8 +255,23 @@ func (h *ProjectHandler) UpdateProject(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	project, err := h.service.UpdateProject(c.Context(), id, req)
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if req.ParentID != nil && !userContext.AllowsProject(*req.ParentID) {
+		return c.Status(403).JSON(fiber.Map{"error": "this token cannot access the parent project"})
+	}
+
+	project, err := h.service.UpdateProject(c.Context(), id, userContext.UserID, userContext.Role, req)
 	if err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "project not found")
+		}
+		if errors.Is(err, repositories.ErrProjectIdentifierTaken) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
 		if err == models.ErrValidation {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -99,7 +288,24 @@ func (h *ProjectHandler) DeleteProject(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
 	}
 
-	if err := h.service.DeleteProject(c.Context(), id); err != nil {
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	// ?cascade=true also deletes all subprojects, which a project-limited token may not reach
+	cascade := c.QueryBool("cascade")
+	if cascade && len(userContext.TokenProjectIDs) > 0 {
+		return c.Status(403).JSON(fiber.Map{"error": "this token cannot delete subprojects"})
+	}
+
+	if err := h.service.DeleteProject(c.Context(), id, userContext.UserID, userContext.Role, cascade); err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "project not found")
+		}
+		if errors.Is(err, services.ErrProjectHasSubprojects) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "failed to delete project"})
 	}
 
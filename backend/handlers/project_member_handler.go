@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+
+	"project-management/middleware"
+	"project-management/models"
+	"project-management/repositories"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ProjectMemberHandler struct {
+	service *services.ProjectMemberService
+}
+
+func NewProjectMemberHandler(service *services.ProjectMemberService) *ProjectMemberHandler {
+	return &ProjectMemberHandler{service: service}
+}
+
+func (h *ProjectMemberHandler) GetMembers(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	members, err := h.service.GetMembers(c.Context(), projectID, userContext.UserID, userContext.Role)
+	if err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.JSON(members)
+}
+
+func (h *ProjectMemberHandler) AddMember(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	var req models.AddProjectMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	member, err := h.service.AddMember(c.Context(), projectID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.Status(201).JSON(member)
+}
+
+func (h *ProjectMemberHandler) UpdateMember(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	memberID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid user id"})
+	}
+
+	var req models.UpdateProjectMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	member, err := h.service.UpdateMemberRole(c.Context(), projectID, memberID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.JSON(member)
+}
+
+func (h *ProjectMemberHandler) RemoveMember(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	memberID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid user id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.RemoveMember(c.Context(), projectID, memberID, userContext.UserID, userContext.Role); err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.Status(204).Send(nil)
+}
+
+func (h *ProjectMemberHandler) GetGroups(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	groups, err := h.service.GetGroups(c.Context(), projectID, userContext.UserID, userContext.Role)
+	if err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.JSON(groups)
+}
+
+func (h *ProjectMemberHandler) AddGroup(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	var req models.AddProjectGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	group, err := h.service.AddGroup(c.Context(), projectID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.Status(201).JSON(group)
+}
+
+func (h *ProjectMemberHandler) UpdateGroup(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	groupID, err := uuid.Parse(c.Params("groupId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid group id"})
+	}
+
+	var req models.UpdateProjectMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	group, err := h.service.UpdateGroupRole(c.Context(), projectID, groupID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.JSON(group)
+}
+
+func (h *ProjectMemberHandler) RemoveGroup(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	groupID, err := uuid.Parse(c.Params("groupId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid group id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.RemoveGroup(c.Context(), projectID, groupID, userContext.UserID, userContext.Role); err != nil {
+		return projectMemberError(c, err)
+	}
+
+	return c.Status(204).Send(nil)
+}
+
+// projectMemberError maps membership service errors to responses
+func projectMemberError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "project not found"})
+	case errors.Is(err, services.ErrProjectForbidden):
+		return c.Status(403).JSON(fiber.Map{"error": "only project managers can manage members"})
+	case errors.Is(err, services.ErrProjectMemberNotFound), errors.Is(err, services.ErrProjectGroupNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidProjectRole), errors.Is(err, services.ErrProjectMemberUser), errors.Is(err, services.ErrProjectGroupUnknown):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrProjectMemberExists), errors.Is(err, services.ErrProjectGroupExists), errors.Is(err, repositories.ErrLastProjectManager):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "failed to update project members"})
+	}
+}
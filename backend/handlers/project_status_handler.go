@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProjectStatusHandler struct {
+	statusService services.ProjectStatusService
+}
+
+func NewProjectStatusHandler(statusService services.ProjectStatusService) *ProjectStatusHandler {
+	return &ProjectStatusHandler{
+		statusService: statusService,
+	}
+}
+
+// ListStatuses returns the project statuses in display order
+func (h *ProjectStatusHandler) ListStatuses(c *fiber.Ctx) error {
+	statuses, err := h.statusService.ListStatuses(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "خطا در دریافت وضعیت‌های پروژه",
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"statuses": statuses,
+		},
+	})
+}
+
+// CreateStatus defines a project status (project_status.manage)
+func (h *ProjectStatusHandler) CreateStatus(c *fiber.Ctx) error {
+	var req models.CreateProjectStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	status, err := h.statusService.CreateStatus(auditContext(c), req)
+	if err != nil {
+		return c.Status(projectStatusErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "CREATE_PROJECT_STATUS_FAILED",
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"status": status,
+		},
+	})
+}
+
+// UpdateStatus replaces a status's flags and position (project_status.manage)
+func (h *ProjectStatusHandler) UpdateStatus(c *fiber.Ctx) error {
+	var req models.UpdateProjectStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	status, err := h.statusService.UpdateStatus(auditContext(c), c.Params("name"), req)
+	if err != nil {
+		return c.Status(projectStatusErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "UPDATE_PROJECT_STATUS_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"status": status,
+		},
+	})
+}
+
+// DeleteStatus removes a status no project has (project_status.manage)
+func (h *ProjectStatusHandler) DeleteStatus(c *fiber.Ctx) error {
+	if err := h.statusService.DeleteStatus(auditContext(c), c.Params("name")); err != nil {
+		return c.Status(projectStatusErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "DELETE_PROJECT_STATUS_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "وضعیت حذف شد",
+		},
+	})
+}
+
+func projectStatusErrorStatus(err error) int {
+	switch err {
+	case services.ErrProjectStatusNotFound:
+		return fiber.StatusNotFound
+	case services.ErrProjectStatusExists, services.ErrProjectStatusInUse, services.ErrLastOpenProjectStatus:
+		return fiber.StatusConflict
+	case services.ErrInvalidProjectStatusName:
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleHandler struct {
+	roleService services.RoleService
+}
+
+func NewRoleHandler(roleService services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// ListRoles returns every system role with its permissions and user count
+func (h *RoleHandler) ListRoles(c *fiber.Ctx) error {
+	roles, err := h.roleService.ListRoles(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "خطا در دریافت لیست نقش‌ها",
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"roles":       roles,
+			"permissions": models.AllPermissions,
+		},
+	})
+}
+
+// CreateRole defines a custom role (role.manage)
+func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
+	var req models.CreateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	role, err := h.roleService.CreateRole(auditContext(c), req)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "CREATE_ROLE_FAILED",
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"role": role,
+		},
+	})
+}
+
+// UpdateRole replaces a role's description and permissions (role.manage)
+func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
+	var req models.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "درخواست نامعتبر است",
+				"code":    "INVALID_REQUEST",
+			},
+		})
+	}
+
+	role, err := h.roleService.UpdateRole(auditContext(c), c.Params("name"), req)
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "UPDATE_ROLE_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"role": role,
+		},
+	})
+}
+
+// DeleteRole removes a custom role nobody holds any more (role.manage)
+func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
+	if err := h.roleService.DeleteRole(auditContext(c), c.Params("name")); err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "DELETE_ROLE_FAILED",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "نقش حذف شد",
+		},
+	})
+}
+
+func roleErrorStatus(err error) int {
+	switch err {
+	case services.ErrRoleNotFound:
+		return fiber.StatusNotFound
+	case services.ErrRoleExists, services.ErrRoleInUse:
+		return fiber.StatusConflict
+	case services.ErrInvalidRoleName, services.ErrInvalidPermission, services.ErrBuiltinRole, services.ErrAdminRoleLocked:
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"project-management/middleware"
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ShareLinkHandler struct {
+	service *services.ShareLinkService
+}
+
+func NewShareLinkHandler(service *services.ShareLinkService) *ShareLinkHandler {
+	return &ShareLinkHandler{service: service}
+}
+
+func (h *ShareLinkHandler) GetLinks(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	links, err := h.service.ListLinks(c.Context(), projectID, userContext.UserID, userContext.Role)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	return c.JSON(links)
+}
+
+func (h *ShareLinkHandler) CreateLink(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	var req models.CreateShareLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	link, err := h.service.CreateLink(c.Context(), projectID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	return c.Status(201).JSON(link)
+}
+
+func (h *ShareLinkHandler) RevokeLink(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	linkID, err := uuid.Parse(c.Params("linkId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid share link id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.RevokeLink(c.Context(), projectID, linkID, userContext.UserID, userContext.Role); err != nil {
+		return shareLinkError(c, err)
+	}
+
+	return c.SendStatus(204)
+}
+
+func (h *ShareLinkHandler) GetAccesses(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	linkID, err := uuid.Parse(c.Params("linkId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid share link id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	accesses, err := h.service.ListAccesses(c.Context(), projectID, linkID, userContext.UserID, userContext.Role)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	return c.JSON(accesses)
+}
+
+// GetSharedProject is the anonymous entry point of a share link
+func (h *ShareLinkHandler) GetSharedProject(c *fiber.Ctx) error {
+	link, err := h.open(c)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	project, err := h.service.GetProject(c.Context(), link)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	return c.JSON(project)
+}
+
+func (h *ShareLinkHandler) GetSharedComments(c *fiber.Ctx) error {
+	taskID, err := uuid.Parse(c.Params("taskId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
+	}
+
+	link, err := h.open(c)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	comments, err := h.service.GetComments(c.Context(), link, taskID)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	return c.JSON(comments)
+}
+
+func (h *ShareLinkHandler) GetSharedAttachments(c *fiber.Ctx) error {
+	taskID, err := uuid.Parse(c.Params("taskId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
+	}
+
+	link, err := h.open(c)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	attachments, err := h.service.GetAttachments(c.Context(), link, taskID)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	return c.JSON(attachments)
+}
+
+func (h *ShareLinkHandler) DownloadSharedAttachment(c *fiber.Ctx) error {
+	attachmentID, err := uuid.Parse(c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid attachment id"})
+	}
+
+	link, err := h.open(c)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	attachment, err := h.service.GetAttachment(c.Context(), link, attachmentID)
+	if err != nil {
+		return shareLinkError(c, err)
+	}
+
+	// Same security headers as the authenticated download
+	c.Set("Content-Type", "application/octet-stream")
+	c.Set("Content-Disposition", "attachment; filename=\""+attachment.OriginalFilename+"\"")
+	c.Set("X-Content-Type-Options", "nosniff")
+	c.Set("X-Frame-Options", "DENY")
+
+	return c.SendFile(attachment.FilePath)
+}
+
+// open resolves the link from the URL and logs the visit, without the token in the logged path
+func (h *ShareLinkHandler) open(c *fiber.Ctx) (*models.ShareLink, error) {
+	token := c.Params("token")
+	path := strings.TrimPrefix(c.Path(), "/api/share/"+token)
+	if path == "" {
+		path = "/"
+	}
+
+	return h.service.Open(c.Context(), token, services.ShareVisit{
+		Path:      path,
+		IPAddress: c.IP(),
+		UserAgent: c.Get("User-Agent"),
+	})
+}
+
+func shareLinkError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrShareLinkInvalid):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, models.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	case errors.Is(err, services.ErrProjectForbidden):
+		return c.Status(403).JSON(fiber.Map{"error": "only project managers can manage share links"})
+	case errors.Is(err, services.ErrShareNotIncluded):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrShareLinkNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrShareLinkLabel), errors.Is(err, services.ErrShareLinkExpiry):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "failed to process share link"})
+	}
+}
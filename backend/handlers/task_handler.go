@@ -1,6 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
 	"project-management/middleware"
 	"project-management/models"
 	"project-management/services"
@@ -38,7 +44,10 @@ func (h *TaskHandler) GetTasksByProject(c *fiber.Ctx) error {
 		limit = 10
 	}
 
-	response, err := h.service.GetTasksByUserPaginated(c.Context(), userContext.UserID, userContext.Role, projectID, page, limit)
+	// ?include_subprojects=true also lists tasks of visible subprojects; project-limited tokens stay within their projects
+	includeSubprojects := c.QueryBool("include_subprojects") && len(userContext.TokenProjectIDs) == 0
+
+	response, err := h.service.GetTasksByUserPaginated(c.Context(), userContext.UserID, userContext.Role, projectID, page, limit, includeSubprojects)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "project not found"})
 	}
@@ -46,6 +55,76 @@ func (h *TaskHandler) GetTasksByProject(c *fiber.Ctx) error {
 	return c.JSON(response)
 }
 
+// SearchTasks searches the project's task titles, descriptions and comments, best matches first.
+// ?q= takes web-search syntax ("exact phrase", or, -exclude); the start_date_* and due_date_* bounds are YYYY-MM-DD.
+func (h *TaskHandler) SearchTasks(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("projectId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	filter, err := parseTaskSearchFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 10)
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	filter.IncludeSubprojects = c.QueryBool("include_subprojects") && len(userContext.TokenProjectIDs) == 0
+
+	response, err := h.service.SearchTasks(c.Context(), userContext.UserID, userContext.Role, projectID, filter, page, limit)
+	if err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "project not found")
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "failed to search tasks"})
+	}
+
+	return c.JSON(response)
+}
+
+// parseTaskSearchFilter reads q and the inclusive start and due date bounds
+func parseTaskSearchFilter(c *fiber.Ctx) (models.TaskSearchFilter, error) {
+	filter := models.TaskSearchFilter{Query: strings.TrimSpace(c.Query("q"))}
+
+	for param, dest := range map[string]**time.Time{
+		"start_date_from": &filter.StartDateFrom,
+		"start_date_to":   &filter.StartDateTo,
+		"due_date_from":   &filter.DueDateFrom,
+		"due_date_to":     &filter.DueDateTo,
+	} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s", param)
+			}
+			*dest = &t
+		}
+	}
+
+	if filter.StartDateFrom != nil && filter.StartDateTo != nil && filter.StartDateFrom.After(*filter.StartDateTo) {
+		return filter, errors.New("start_date_from is after start_date_to")
+	}
+	if filter.DueDateFrom != nil && filter.DueDateTo != nil && filter.DueDateFrom.After(*filter.DueDateTo) {
+		return filter, errors.New("due_date_from is after due_date_to")
+	}
+
+	return filter, nil
+}
+
 func (h *TaskHandler) CreateTask(c *fiber.Ctx) error {
 	projectID, err := uuid.Parse(c.Params("projectId"))
 	if err != nil {
@@ -57,9 +136,17 @@ func (h *TaskHandler) CreateTask(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	task, err := h.service.CreateTask(c.Context(), projectID, req)
+	userContext, err := middleware.GetUserFromContext(c)
 	if err != nil {
-		if err == models.ErrValidation || err == models.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	task, err := h.service.CreateTask(c.Context(), projectID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "project not found")
+		}
+		if err == models.ErrValidation {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
 		// Handle validation errors from service layer (dates, done_ratio, etc.)
@@ -75,7 +162,12 @@ func (h *TaskHandler) GetTask(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
 	}
 
-	task, err := h.service.GetTaskByIDWithUsers(c.Context(), id)
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	task, err := h.service.GetTaskByIDWithUsers(c.Context(), id, userContext.UserID, userContext.Role)
 	if err != nil || task == nil {
 		return c.Status(404).JSON(fiber.Map{"error": "task not found"})
 	}
@@ -83,6 +175,25 @@ func (h *TaskHandler) GetTask(c *fiber.Ctx) error {
 	return c.JSON(task)
 }
 
+// GetTaskByKey looks a task up by its key, e.g. WEB-142; old keys of moved tasks redirect to the current one
+func (h *TaskHandler) GetTaskByKey(c *fiber.Ctx) error {
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	task, moved, err := h.service.GetTaskByKey(c.Context(), c.Params("key"), userContext.UserID, userContext.Role)
+	// The token middleware cannot resolve keys, so project-limited tokens are checked here
+	if err != nil || task == nil || !userContext.AllowsProject(task.ProjectID) {
+		return c.Status(404).JSON(fiber.Map{"error": "task not found"})
+	}
+
+	if moved {
+		return c.Redirect("/api/tasks/by-key/"+url.PathEscape(task.Key), fiber.StatusMovedPermanently)
+	}
+	return c.JSON(task)
+}
+
 func (h *TaskHandler) UpdateTask(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
@@ -94,8 +205,20 @@ func (h *TaskHandler) UpdateTask(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	task, err := h.service.UpdateTask(c.Context(), id, req)
+	userContext, err := middleware.GetUserFromContext(c)
 	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if req.ProjectID != nil && !userContext.AllowsProject(*req.ProjectID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "this token cannot move tasks to that project"})
+	}
+
+	task, err := h.service.UpdateTask(c.Context(), id, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "task not found")
+		}
 		if err == models.ErrValidation {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -112,8 +235,16 @@ func (h *TaskHandler) ToggleTaskCompletion(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
 	}
 
-	task, err := h.service.ToggleTaskCompletion(c.Context(), id)
+	userContext, err := middleware.GetUserFromContext(c)
 	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	task, err := h.service.ToggleTaskCompletion(c.Context(), id, userContext.UserID, userContext.Role)
+	if err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "task not found")
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "failed to update task"})
 	}
 
@@ -126,7 +257,15 @@ func (h *TaskHandler) DeleteTask(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
 	}
 
-	if err := h.service.DeleteTask(c.Context(), id); err != nil {
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.DeleteTask(c.Context(), id, userContext.UserID, userContext.Role); err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "task not found")
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "failed to delete task"})
 	}
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"project-management/middleware"
 	"project-management/models"
 	"project-management/services"
 
@@ -22,7 +23,12 @@ func (h *TimeLogHandler) GetTimeLogsByTask(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
 	}
 
-	timeLogs, err := h.service.GetTimeLogsByTaskID(c.Context(), taskID)
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	timeLogs, err := h.service.GetTimeLogsByTaskID(c.Context(), taskID, userContext.UserID, userContext.Role)
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "task not found"})
 	}
@@ -41,9 +47,17 @@ func (h *TimeLogHandler) CreateTimeLog(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	timeLog, err := h.service.CreateTimeLog(c.Context(), taskID, req)
+	userContext, err := middleware.GetUserFromContext(c)
 	if err != nil {
-		if err == models.ErrValidation || err == models.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	timeLog, err := h.service.CreateTimeLog(c.Context(), taskID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "task not found")
+		}
+		if err == models.ErrValidation {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
 		return c.Status(500).JSON(fiber.Map{"error": "failed to create time log"})
@@ -58,7 +72,12 @@ func (h *TimeLogHandler) GetTimeLog(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid time log id"})
 	}
 
-	timeLog, err := h.service.GetTimeLogByID(c.Context(), id)
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	timeLog, err := h.service.GetTimeLogByID(c.Context(), id, userContext.UserID, userContext.Role)
 	if err != nil || timeLog == nil {
 		return c.Status(404).JSON(fiber.Map{"error": "time log not found"})
 	}
@@ -72,7 +91,15 @@ func (h *TimeLogHandler) DeleteTimeLog(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid time log id"})
 	}
 
-	if err := h.service.DeleteTimeLog(c.Context(), id); err != nil {
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.DeleteTimeLog(c.Context(), id, userContext.UserID, userContext.Role); err != nil {
+		if isAccessError(err) {
+			return accessErrorResponse(c, err, "time log not found")
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "failed to delete time log"})
 	}
 
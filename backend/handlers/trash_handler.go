@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"project-management/middleware"
+	"project-management/models"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type TrashHandler struct {
+	service services.TrashService
+}
+
+func NewTrashHandler(service services.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// GetTrash lists the caller's deleted projects and tasks; ?all=true lists everyone's (trash.manage)
+func (h *TrashHandler) GetTrash(c *fiber.Ctx) error {
+	all := false
+	if value := c.Query("all"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid all"})
+		}
+		all = b
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	trash, err := h.service.GetTrash(c.Context(), userContext.UserID, userContext.Role, all)
+	if err != nil {
+		return trashError(c, err)
+	}
+
+	return c.JSON(trash)
+}
+
+func (h *TrashHandler) RestoreProject(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.RestoreProject(c.Context(), id, userContext.UserID, userContext.Role); err != nil {
+		return trashError(c, err)
+	}
+
+	return c.SendStatus(204)
+}
+
+func (h *TrashHandler) RestoreTask(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.RestoreTask(c.Context(), id, userContext.UserID, userContext.Role); err != nil {
+		return trashError(c, err)
+	}
+
+	return c.SendStatus(204)
+}
+
+func trashError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "not found in trash"})
+	case errors.Is(err, services.ErrProjectForbidden):
+		return c.Status(403).JSON(fiber.Map{"error": "viewing everyone's trash requires the trash.manage permission"})
+	case errors.Is(err, services.ErrRestoreParentDeleted):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "failed to process trash"})
+	}
+}
@@ -131,25 +131,19 @@ func (h *UserHandler) UpdateUserRole(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate role
-	if req.Role != "admin" && req.Role != "user" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"error": fiber.Map{
-				"message": "نقش نامعتبر است",
-				"code":    "INVALID_ROLE",
-			},
-		})
-	}
-
 	// Update user role
-	user, err := h.userService.UpdateUserRole(c.Context(), id, req.Role)
+	user, err := h.userService.UpdateUserRole(auditContext(c), id, req.Role)
 	if err != nil {
+		code := "UPDATE_ROLE_FAILED"
+		if err == services.ErrInvalidRole {
+			code = "INVALID_ROLE"
+		}
+
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"error": fiber.Map{
 				"message": err.Error(),
-				"code":    "UPDATE_ROLE_FAILED",
+				"code":    code,
 			},
 		})
 	}
@@ -210,7 +204,7 @@ func (h *UserHandler) UpdateUserActivation(c *fiber.Ctx) error {
 	}
 
 	// Update activation status
-	user, err := h.userService.UpdateUserActivation(c.Context(), id, req.IsActive)
+	user, err := h.userService.UpdateUserActivation(auditContext(c), id, req.IsActive)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
@@ -228,3 +222,116 @@ func (h *UserHandler) UpdateUserActivation(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// GetUserSessions lists a user's active sessions (admin only)
+func (h *UserHandler) GetUserSessions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه کاربر نامعتبر است",
+				"code":    "INVALID_USER_ID",
+			},
+		})
+	}
+
+	sessions, err := h.userService.GetUserSessions(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "USER_NOT_FOUND",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"sessions": sessions,
+		},
+	})
+}
+
+// RevokeUserSession ends one of a user's sessions (admin only)
+func (h *UserHandler) RevokeUserSession(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه کاربر نامعتبر است",
+				"code":    "INVALID_USER_ID",
+			},
+		})
+	}
+
+	sessionID, err := uuid.Parse(c.Params("sessionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه نشست نامعتبر است",
+				"code":    "INVALID_SESSION_ID",
+			},
+		})
+	}
+
+	err = h.userService.RevokeUserSession(auditContext(c), id, sessionID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		code := "SERVER_ERROR"
+		if err == services.ErrSessionNotFound {
+			status = fiber.StatusNotFound
+			code = "SESSION_NOT_FOUND"
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    code,
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"message": "نشست با موفقیت خاتمه یافت",
+		},
+	})
+}
+
+// RevokeAllUserSessions signs a user out of every device (admin only)
+func (h *UserHandler) RevokeAllUserSessions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "شناسه کاربر نامعتبر است",
+				"code":    "INVALID_USER_ID",
+			},
+		})
+	}
+
+	revoked, err := h.userService.RevokeAllUserSessions(auditContext(c), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "SERVER_ERROR",
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"revoked": revoked,
+		},
+	})
+}
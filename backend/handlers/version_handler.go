@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+
+	"project-management/middleware"
+	"project-management/models"
+	"project-management/repositories"
+	"project-management/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type VersionHandler struct {
+	service *services.VersionService
+}
+
+func NewVersionHandler(service *services.VersionService) *VersionHandler {
+	return &VersionHandler{service: service}
+}
+
+func (h *VersionHandler) GetVersions(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	versions, err := h.service.ListVersions(c.Context(), projectID, userContext.UserID, userContext.Role)
+	if err != nil {
+		return versionError(c, err)
+	}
+
+	return c.JSON(versions)
+}
+
+func (h *VersionHandler) CreateVersion(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	var req models.CreateVersionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	version, err := h.service.CreateVersion(c.Context(), projectID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		return versionError(c, err)
+	}
+
+	return c.Status(201).JSON(version)
+}
+
+func (h *VersionHandler) UpdateVersion(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	versionID, err := uuid.Parse(c.Params("versionId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid version id"})
+	}
+
+	var req models.UpdateVersionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	version, err := h.service.UpdateVersion(c.Context(), projectID, versionID, userContext.UserID, userContext.Role, req)
+	if err != nil {
+		return versionError(c, err)
+	}
+
+	return c.JSON(version)
+}
+
+func (h *VersionHandler) DeleteVersion(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	versionID, err := uuid.Parse(c.Params("versionId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid version id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	if err := h.service.DeleteVersion(c.Context(), projectID, versionID, userContext.UserID, userContext.Role); err != nil {
+		return versionError(c, err)
+	}
+
+	return c.SendStatus(204)
+}
+
+// GetRoadmap lists versions with task progress; ?include_closed=true adds closed versions
+func (h *VersionHandler) GetRoadmap(c *fiber.Ctx) error {
+	projectID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid project id"})
+	}
+
+	userContext, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+
+	roadmap, err := h.service.GetRoadmap(c.Context(), projectID, userContext.UserID, userContext.Role, c.QueryBool("include_closed"))
+	if err != nil {
+		return versionError(c, err)
+	}
+
+	return c.JSON(roadmap)
+}
+
+func versionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "project not found"})
+	case errors.Is(err, services.ErrProjectForbidden):
+		return c.Status(403).JSON(fiber.Map{"error": "only project managers can manage versions"})
+	case errors.Is(err, services.ErrVersionNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrSystemSharing):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidVersionName), errors.Is(err, services.ErrInvalidVersionStatus),
+		errors.Is(err, services.ErrInvalidSharing), errors.Is(err, services.ErrVersionMoveTarget):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrVersionHasOpenTasks), errors.Is(err, repositories.ErrVersionNameTaken):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "failed to process version"})
+	}
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
+	"strings"
+	"time"
 
 	"project-management/config"
 	"project-management/handlers"
+	"project-management/middleware"
 	"project-management/repositories"
 	"project-management/routes"
 	"project-management/services"
@@ -31,6 +35,10 @@ func main() {
 		log.Fatalf("Failed to initialize file storage: %v", err)
 	}
 
+	if err := config.InitJWTKeys(); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
 	app := fiber.New(fiber.Config{
 		AppName: "Project Management API",
 	})
@@ -55,45 +63,166 @@ func main() {
 
 	// Initialize repositories
 	projectRepo := repositories.NewProjectRepository(config.DB)
+	projectMemberRepo := repositories.NewProjectMemberRepository(config.DB)
 	taskRepo := repositories.NewTaskRepository(config.DB)
 	timeLogRepo := repositories.NewTimeLogRepository(config.DB)
 	userRepo := repositories.NewUserRepository(config.DB)
 	sessionRepo := repositories.NewSessionRepository(config.DB)
 	passwordResetRepo := repositories.NewPasswordResetRepository(config.DB)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(config.DB)
+	emailVerificationRepo := repositories.NewEmailVerificationRepository(config.DB)
+	passwordHistoryRepo := repositories.NewPasswordHistoryRepository(config.DB)
+	invitationRepo := repositories.NewInvitationRepository(config.DB)
+	auditLogRepo := repositories.NewAuditLogRepository(config.DB)
+	rateLimitRepo := repositories.NewRateLimitRepository(config.DB)
+	roleRepo := repositories.NewRoleRepository(config.DB)
+	groupRepo := repositories.NewGroupRepository(config.DB)
+	tokenRepo := repositories.NewPersonalAccessTokenRepository(config.DB)
 	commentRepo := repositories.NewCommentRepository(config.DB)
 	dashboardRepo := repositories.NewDashboardRepository(config.DB)
 	meetingRepo := repositories.NewMeetingRepository(config.DB)
 	attachmentRepo := repositories.NewAttachmentRepository(config.DB)
+	shareLinkRepo := repositories.NewShareLinkRepository(config.DB)
+	versionRepo := repositories.NewVersionRepository(config.DB)
+	projectStatusRepo := repositories.NewProjectStatusRepository(config.DB)
+	trashRepo := repositories.NewTrashRepository(config.DB)
 
 	// Initialize services
 	emailService := services.NewEmailService()
+	auditService := services.NewAuditService(auditLogRepo)
 	fileStorageService := services.NewFileStorageService()
 	fileValidationService := services.NewFileValidationService()
-	projectService := services.NewProjectService(projectRepo)
-	taskService := services.NewTaskService(taskRepo, projectRepo)
-	timeLogService := services.NewTimeLogService(timeLogRepo, taskRepo)
-	authService := services.NewAuthService(userRepo, sessionRepo, passwordResetRepo, emailService)
-	userService := services.NewUserService(userRepo)
-	commentService := services.NewCommentService(commentRepo, taskRepo)
-	dashboardService := services.NewDashboardService(dashboardRepo, meetingRepo)
+	projectAccess := services.NewProjectAccess(projectMemberRepo, roleRepo, projectStatusRepo)
+	projectService := services.NewProjectService(projectRepo, projectStatusRepo, attachmentRepo, fileStorageService, projectAccess)
+	projectMemberService := services.NewProjectMemberService(projectMemberRepo, userRepo, groupRepo, projectAccess)
+	notificationService := services.NewNotificationService(userRepo, groupRepo, projectRepo, projectAccess, emailService)
+	taskService := services.NewTaskService(taskRepo, projectRepo, userRepo, groupRepo, versionRepo, projectAccess, notificationService)
+	timeLogService := services.NewTimeLogService(timeLogRepo, projectAccess)
+	ldapAuthenticator := services.NewLDAPAuthenticator(services.LDAPConfig{
+		URL:                config.LDAPURL,
+		StartTLS:           config.LDAPStartTLS,
+		InsecureSkipVerify: config.LDAPInsecureSkipVerify,
+		BindDNTemplate:     config.LDAPBindDNTemplate,
+		BindDN:             config.LDAPBindDN,
+		BindPassword:       config.LDAPBindPassword,
+		BaseDN:             config.LDAPBaseDN,
+		UserFilter:         config.LDAPUserFilter,
+		UsernameAttribute:  config.LDAPUsernameAttribute,
+		EmailAttribute:     config.LDAPEmailAttribute,
+		GroupAttribute:     config.LDAPGroupAttribute,
+		AdminGroups:        splitList(config.LDAPAdminGroups, ";"),
+	})
+	passwordPolicy := services.PasswordPolicy{
+		MinLength:     config.PasswordMinLength,
+		RequireUpper:  config.PasswordRequireUpper,
+		RequireLower:  config.PasswordRequireLower,
+		RequireDigit:  config.PasswordRequireDigit,
+		RequireSymbol: config.PasswordRequireSymbol,
+		MaxAge:        time.Duration(config.PasswordMaxAgeDays) * 24 * time.Hour,
+		HistorySize:   config.PasswordHistorySize,
+		BcryptCost:    config.PasswordBcryptCost,
+	}
+	if config.PasswordBreachedListDir != "" {
+		passwordPolicy.Breached = services.NewBreachedPasswordDirectory(config.PasswordBreachedListDir)
+	}
+	authService := services.NewAuthService(userRepo, roleRepo, sessionRepo, passwordResetRepo, recoveryCodeRepo, emailVerificationRepo, passwordHistoryRepo, emailService, ldapAuthenticator, passwordPolicy, auditService)
+	userService := services.NewUserService(userRepo, sessionRepo, roleRepo, auditService)
+	tokenService := services.NewPersonalAccessTokenService(tokenRepo, projectAccess)
+	invitationService := services.NewInvitationService(invitationRepo, userRepo, roleRepo, projectRepo, emailService, passwordPolicy)
+	oidcService := services.NewOIDCService(services.OIDCConfig{
+		IssuerURL:     config.OIDCIssuerURL,
+		ClientID:      config.OIDCClientID,
+		ClientSecret:  config.OIDCClientSecret,
+		RedirectURL:   config.OIDCRedirectURL,
+		Scopes:        splitList(config.OIDCScopes, ","),
+		EmailClaim:    config.OIDCEmailClaim,
+		UsernameClaim: config.OIDCUsernameClaim,
+		GroupsClaim:   config.OIDCGroupsClaim,
+		AdminGroups:   splitList(config.OIDCAdminGroups, ","),
+		StateSecret:   config.JWTStateSecret,
+	})
+	roleService := services.NewRoleService(roleRepo, auditService)
+	groupService := services.NewGroupService(groupRepo, userRepo)
+	commentService := services.NewCommentService(commentRepo, taskRepo, projectAccess)
+	dashboardService := services.NewDashboardService(dashboardRepo, meetingRepo, roleRepo)
 	meetingService := services.NewMeetingService(meetingRepo, userRepo)
-	attachmentService := services.NewAttachmentService(attachmentRepo, taskRepo, projectRepo, fileStorageService, fileValidationService)
+	attachmentService := services.NewAttachmentService(attachmentRepo, taskRepo, fileStorageService, fileValidationService, projectAccess)
+	versionService := services.NewVersionService(versionRepo, projectAccess)
+	projectStatusService := services.NewProjectStatusService(projectStatusRepo, auditService)
+	trashService := services.NewTrashService(trashRepo, projectAccess, fileStorageService, config.TrashRetentionDays)
+	shareLinkService := services.NewShareLinkService(shareLinkRepo, projectRepo, taskRepo, commentRepo, attachmentRepo, projectAccess)
 
 	// Initialize handlers
 	projectHandler := handlers.NewProjectHandler(projectService)
 	taskHandler := handlers.NewTaskHandler(taskService)
 	timeLogHandler := handlers.NewTimeLogHandler(timeLogService)
-	authHandler := handlers.NewAuthHandler(authService)
+	authHandler := handlers.NewAuthHandler(authService, oidcService)
 	userHandler := handlers.NewUserHandler(userService)
 	commentHandler := handlers.NewCommentHandler(commentService)
 	dashboardHandler := handlers.NewDashboardHandler(dashboardService)
 	meetingHandler := handlers.NewMeetingHandler(meetingService)
 	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	tokenHandler := handlers.NewPersonalAccessTokenHandler(tokenService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	projectMemberHandler := handlers.NewProjectMemberHandler(projectMemberService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	groupHandler := handlers.NewGroupHandler(groupService)
+	shareLinkHandler := handlers.NewShareLinkHandler(shareLinkService)
+	versionHandler := handlers.NewVersionHandler(versionService)
+	projectStatusHandler := handlers.NewProjectStatusHandler(projectStatusService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+
+	// Enable Authorization: Bearer personal access tokens in RequireAuth
+	middleware.SetPersonalAccessTokenAuthenticator(tokenService)
+
+	// Resolve RequirePermission checks against the roles table
+	middleware.SetPermissionResolver(roleService)
+
+	// Keep rate limit counters in the database so every replica enforces the same limits
+	middleware.SetRateLimitStore(rateLimitRepo)
+	go purgeExpiredRateLimits(rateLimitRepo)
+
+	// Permanently delete trash older than the retention window, attachment files included
+	go purgeTrash(trashService)
 
-	routes.SetupRoutes(app, projectHandler, taskHandler, timeLogHandler, authHandler, userHandler, commentHandler, dashboardHandler, meetingHandler, attachmentHandler)
+	routes.SetupRoutes(app, projectHandler, taskHandler, timeLogHandler, authHandler, userHandler, commentHandler, dashboardHandler, meetingHandler, attachmentHandler, tokenHandler, invitationHandler, auditHandler, projectMemberHandler, roleHandler, groupHandler, shareLinkHandler, versionHandler, projectStatusHandler, trashHandler)
 
 	log.Println("Server starting on port 3000")
 	if err := app.Listen(":3000"); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// purgeExpiredRateLimits periodically deletes rate limit windows that no longer count
+func purgeExpiredRateLimits(repo repositories.RateLimitRepository) {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := repo.DeleteExpired(context.Background()); err != nil {
+			log.Printf("Failed to purge expired rate limits: %v", err)
+		}
+	}
+}
+
+// purgeTrash periodically purges projects and tasks that have been in the trash too long
+func purgeTrash(service services.TrashService) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := service.Purge(context.Background()); err != nil {
+			log.Printf("Failed to purge trash: %v", err)
+		}
+	}
+}
+
+// splitList parses a list-valued configuration setting
+func splitList(value, separator string) []string {
+	var items []string
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
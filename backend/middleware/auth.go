@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"project-management/config"
+	"project-management/models"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -21,10 +23,55 @@ const (
 type UserContext struct {
 	UserID uuid.UUID
 	Role   string
+
+	// Set when the request is authenticated with a personal access token
+	TokenID         *uuid.UUID
+	TokenScope      string
+	TokenProjectIDs []uuid.UUID
+}
+
+// IsTokenAuth reports whether the request was authenticated with a personal access token
+func (u *UserContext) IsTokenAuth() bool {
+	return u.TokenID != nil
 }
 
-// RequireAuth middleware validates JWT token and sets user context
+// AllowsProject reports whether the credentials used for the request may touch the project
+func (u *UserContext) AllowsProject(projectID uuid.UUID) bool {
+	if !u.IsTokenAuth() || len(u.TokenProjectIDs) == 0 {
+		return true
+	}
+	for _, id := range u.TokenProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonalAccessTokenAuthenticator validates bearer tokens (implemented by the token service)
+type PersonalAccessTokenAuthenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (*models.PersonalAccessToken, error)
+	ResolveProjectID(ctx context.Context, resource string, id uuid.UUID) (uuid.UUID, error)
+}
+
+var tokenAuthenticator PersonalAccessTokenAuthenticator
+
+// SetPersonalAccessTokenAuthenticator enables Authorization: Bearer authentication
+func SetPersonalAccessTokenAuthenticator(authenticator PersonalAccessTokenAuthenticator) {
+	tokenAuthenticator = authenticator
+}
+
+// RequireAuth middleware validates the session cookie or a personal access token and sets user context
 func RequireAuth(c *fiber.Ctx) error {
+	if token := bearerToken(c); token != "" {
+		return authenticatePersonalAccessToken(c, token)
+	}
+	return RequireSessionAuth(c)
+}
+
+// RequireSessionAuth middleware only accepts the browser session cookie.
+// Used for account management routes that personal access tokens must not reach.
+func RequireSessionAuth(c *fiber.Ctx) error {
 	// Get token from cookie
 	tokenString := c.Cookies("access_token")
 	if tokenString == "" {
@@ -38,9 +85,7 @@ func RequireAuth(c *fiber.Ctx) error {
 	}
 
 	// Parse and validate token
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.JWTSecret), nil
-	})
+	token, err := jwt.Parse(tokenString, config.JWTKeyFunc)
 
 	if err != nil || !token.Valid {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -114,10 +159,147 @@ func RequireAuth(c *fiber.Ctx) error {
 	return c.Next()
 }
 
-// RequireRole middleware checks if user has required role
-func RequireRole(allowedRoles ...string) fiber.Handler {
+// authenticatePersonalAccessToken validates a bearer token and enforces its scope and project limits
+func authenticatePersonalAccessToken(c *fiber.Ctx, tokenString string) error {
+	if tokenAuthenticator == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "توکن نامعتبر یا منقضی شده است",
+				"code":    "INVALID_TOKEN",
+			},
+		})
+	}
+
+	token, err := tokenAuthenticator.AuthenticateToken(c.Context(), tokenString)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": err.Error(),
+				"code":    "INVALID_TOKEN",
+			},
+		})
+	}
+
+	tokenID := token.ID
+	userContext := &UserContext{
+		UserID:          token.UserID,
+		Role:            token.OwnerRole,
+		TokenID:         &tokenID,
+		TokenScope:      token.Scope,
+		TokenProjectIDs: token.ProjectIDs,
+	}
+
+	// Read-only tokens may not modify anything
+	if token.Scope != models.TokenScopeWrite && !isSafeMethod(c.Method()) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "این توکن فقط دسترسی خواندنی دارد",
+				"code":    "TOKEN_READ_ONLY",
+			},
+		})
+	}
+
+	if len(userContext.TokenProjectIDs) > 0 && !tokenAllowsPath(c, userContext) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"message": "این توکن به این منبع دسترسی ندارد",
+				"code":    "TOKEN_PROJECT_FORBIDDEN",
+			},
+		})
+	}
+
+	c.Locals(string(UserContextKey), userContext)
+
+	return c.Next()
+}
+
+// tokenAllowsPath checks a project-limited token against the resource addressed by the request
+func tokenAllowsPath(c *fiber.Ctx, userContext *UserContext) bool {
+	resource, id, hasID := parseResourcePath(c.Path())
+
+	switch resource {
+	case "auth":
+		return true
+	case "projects":
+		if !hasID {
+			// Listing is filtered by the handler; creating new projects is out of scope
+			return isSafeMethod(c.Method())
+		}
+	case "tasks", "timelogs", "comments", "attachments":
+		if resource == "tasks" && isTaskKeyLookup(c) {
+			// The handler checks the project of the task the key resolves to
+			return true
+		}
+		if !hasID {
+			return false
+		}
+	default:
+		// Resources that are not tied to a single project
+		return false
+	}
+
+	projectID, err := tokenAuthenticator.ResolveProjectID(c.Context(), resource, id)
+	if err != nil {
+		return false
+	}
+	return userContext.AllowsProject(projectID)
+}
+
+// isTaskKeyLookup reports whether the request is GET /api/tasks/by-key/:key
+func isTaskKeyLookup(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodGet && strings.HasPrefix(c.Path(), "/api/tasks/by-key/")
+}
+
+// parseResourcePath extracts the resource name and id from /api/{resource}/{id}/...
+func parseResourcePath(path string) (string, uuid.UUID, bool) {
+	path = strings.TrimPrefix(path, "/api/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	resource := segments[0]
+	if len(segments) < 2 {
+		return resource, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(segments[1])
+	if err != nil {
+		return resource, uuid.Nil, false
+	}
+	return resource, id, true
+}
+
+// bearerToken returns the token from an Authorization: Bearer header, if any
+func bearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+func isSafeMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
+
+// PermissionResolver reports whether a system role grants a permission (implemented by the role service)
+type PermissionResolver interface {
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+var permissionResolver PermissionResolver
+
+// SetPermissionResolver enables RequirePermission checks against the roles table
+func SetPermissionResolver(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+// RequirePermission middleware checks the user's role grants the permission.
+// Without a resolver only the built-in admin role passes.
+func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Get user context
 		userContext, ok := c.Locals(string(UserContextKey)).(*UserContext)
 		if !ok {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -129,16 +311,22 @@ func RequireRole(allowedRoles ...string) fiber.Handler {
 			})
 		}
 
-		// Check if user has required role
-		hasRole := false
-		for _, role := range allowedRoles {
-			if strings.EqualFold(userContext.Role, role) {
-				hasRole = true
-				break
+		allowed := userContext.Role == models.SystemRoleAdmin
+		if permissionResolver != nil {
+			var err error
+			allowed, err = permissionResolver.HasPermission(c.Context(), userContext.Role, permission)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"success": false,
+					"error": fiber.Map{
+						"message": "خطا در بررسی دسترسی",
+						"code":    "SERVER_ERROR",
+					},
+				})
 			}
 		}
 
-		if !hasRole {
+		if !allowed {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error": fiber.Map{
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestParseResourcePath(t *testing.T) {
+	id := uuid.New()
+
+	cases := []struct {
+		path     string
+		resource string
+		hasID    bool
+	}{
+		{"/api/projects", "projects", false},
+		{"/api/projects/", "projects", false},
+		{"/api/projects/" + id.String(), "projects", true},
+		{"/api/projects/" + id.String() + "/tasks", "projects", true},
+		{"/api/tasks/" + id.String() + "/comments", "tasks", true},
+		{"/api/meetings/next", "meetings", false},
+		{"/api/dashboard", "dashboard", false},
+	}
+
+	for _, tc := range cases {
+		resource, gotID, hasID := parseResourcePath(tc.path)
+		if resource != tc.resource || hasID != tc.hasID {
+			t.Fatalf("parseResourcePath(%q) = (%q, %v), want (%q, %v)", tc.path, resource, hasID, tc.resource, tc.hasID)
+		}
+		if hasID && gotID != id {
+			t.Fatalf("parseResourcePath(%q) id = %s, want %s", tc.path, gotID, id)
+		}
+	}
+}
+
+func TestUserContextAllowsProject(t *testing.T) {
+	allowed := uuid.New()
+	other := uuid.New()
+	tokenID := uuid.New()
+
+	session := &UserContext{UserID: uuid.New()}
+	if !session.AllowsProject(other) {
+		t.Fatalf("session auth should not be limited to projects")
+	}
+
+	unlimited := &UserContext{UserID: uuid.New(), TokenID: &tokenID}
+	if !unlimited.AllowsProject(other) {
+		t.Fatalf("token without project list should allow all projects")
+	}
+
+	limited := &UserContext{UserID: uuid.New(), TokenID: &tokenID, TokenProjectIDs: []uuid.UUID{allowed}}
+	if !limited.AllowsProject(allowed) {
+		t.Fatalf("limited token should allow its own project")
+	}
+	if limited.AllowsProject(other) {
+		t.Fatalf("limited token should reject other projects")
+	}
+}
+
+type stubPermissionResolver map[string][]string
+
+func (s stubPermissionResolver) HasPermission(_ context.Context, role, permission string) (bool, error) {
+	for _, p := range s[role] {
+		if p == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	defer SetPermissionResolver(nil)
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Locals(string(UserContextKey), &UserContext{UserID: uuid.New(), Role: c.Get("X-Role")})
+		return c.Next()
+	}, RequirePermission("audit.view"), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	status := func(role string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Role", role)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	// Without a resolver only the built-in admin role passes
+	SetPermissionResolver(nil)
+	if got := status("admin"); got != fiber.StatusNoContent {
+		t.Fatalf("admin without resolver: status %d, want 204", got)
+	}
+	if got := status("auditor"); got != fiber.StatusForbidden {
+		t.Fatalf("custom role without resolver: status %d, want 403", got)
+	}
+
+	SetPermissionResolver(stubPermissionResolver{"auditor": {"audit.view"}})
+	if got := status("auditor"); got != fiber.StatusNoContent {
+		t.Fatalf("role with permission: status %d, want 204", got)
+	}
+	if got := status("user"); got != fiber.StatusForbidden {
+		t.Fatalf("role without permission: status %d, want 403", got)
+	}
+}
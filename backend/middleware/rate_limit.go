@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"project-management/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimitStore keeps limiter counters where every replica can see them (implemented by the rate limit repository)
+type RateLimitStore interface {
+	Hit(ctx context.Context, key string, window time.Duration) (*models.RateLimitWindow, error)
+	Undo(ctx context.Context, key string, windowStart time.Time) error
+}
+
+var rateLimitStore RateLimitStore
+
+// SetRateLimitStore shares rate limit counters across replicas; without it each process counts on its own
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimitStore = store
+}
+
+// RateLimitPolicy limits requests per key within a sliding window
+type RateLimitPolicy struct {
+	// Name separates the counters of different policies
+	Name   string
+	Max    int
+	Window time.Duration
+	// PerUser keys authenticated requests by user instead of client IP; must follow RequireAuth
+	PerUser bool
+}
+
+// RateLimit enforces the policy with a sliding window and sets RateLimit-* response headers
+func RateLimit(policy RateLimitPolicy) fiber.Handler {
+	cfg := limiter.Config{
+		Max:        policy.Max,
+		Expiration: policy.Window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			if policy.PerUser {
+				if userCtx, err := GetUserFromContext(c); err == nil {
+					return policy.Name + ":user:" + userCtx.UserID.String()
+				}
+			}
+			return policy.Name + ":ip:" + c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"message": "تعداد درخواست‌ها بیش از حد مجاز است. لطفاً کمی بعد دوباره تلاش کنید",
+					"code":    "RATE_LIMITED",
+				},
+			})
+		},
+	}
+
+	if rateLimitStore != nil {
+		cfg.LimiterMiddleware = storeSlidingWindow{store: rateLimitStore}
+	}
+	return limiter.New(cfg)
+}
+
+// storeSlidingWindow is a limiter.LimiterHandler that counts hits in a RateLimitStore
+type storeSlidingWindow struct {
+	store RateLimitStore
+}
+
+func (l storeSlidingWindow) New(cfg limiter.Config) fiber.Handler {
+	window := cfg.Expiration
+	policy := strconv.Itoa(cfg.Max) + ";w=" + strconv.Itoa(int(window.Seconds()))
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		key := cfg.KeyGenerator(c)
+		counters, err := l.store.Hit(c.Context(), key, window)
+		if err != nil {
+			// Rather serve unlimited than fail every request while the database is unreachable
+			log.Printf("Rate limit check failed for %s: %v", key, err)
+			return c.Next()
+		}
+
+		rate := slidingWindowRate(counters.Current, counters.Previous, counters.Elapsed, window)
+		reset := slidingWindowReset(counters.Elapsed, window)
+		remaining := cfg.Max - rate
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		c.Set("RateLimit-Policy", policy)
+		c.Set("RateLimit-Limit", strconv.Itoa(cfg.Max))
+		c.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(reset))
+
+		if rate > cfg.Max {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(reset))
+			return cfg.LimitReached(c)
+		}
+
+		err = c.Next()
+
+		status := c.Response().StatusCode()
+		if (cfg.SkipSuccessfulRequests && status < fiber.StatusBadRequest) ||
+			(cfg.SkipFailedRequests && status >= fiber.StatusBadRequest) {
+			if undoErr := l.store.Undo(c.Context(), key, counters.WindowStart); undoErr != nil {
+				log.Printf("Failed to undo rate limit hit for %s: %v", key, undoErr)
+			}
+		}
+
+		return err
+	}
+}
+
+// slidingWindowRate estimates the hits in the trailing window, weighting the previous fixed
+// window by how much of it the trailing window still overlaps
+func slidingWindowRate(current, previous int, elapsed, window time.Duration) int {
+	if elapsed >= window {
+		return current
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	weight := float64(window-elapsed) / float64(window)
+	return int(float64(previous)*weight) + current
+}
+
+// slidingWindowReset is the number of seconds until the current fixed window ends
+func slidingWindowReset(elapsed, window time.Duration) int {
+	remaining := window - elapsed
+	if remaining < 0 {
+		return 0
+	}
+	return int((remaining + time.Second - 1) / time.Second)
+}
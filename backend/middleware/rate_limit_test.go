@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlidingWindowRate(t *testing.T) {
+	window := time.Minute
+
+	cases := []struct {
+		current, previous int
+		elapsed           time.Duration
+		want              int
+	}{
+		{current: 1, previous: 0, elapsed: 0, want: 1},
+		// Start of a window: the previous one still counts in full
+		{current: 1, previous: 10, elapsed: 0, want: 11},
+		// Halfway: half of the previous window remains in the trailing minute
+		{current: 3, previous: 10, elapsed: 30 * time.Second, want: 8},
+		{current: 3, previous: 10, elapsed: 59 * time.Second, want: 3},
+		{current: 3, previous: 10, elapsed: window, want: 3},
+	}
+
+	for _, tc := range cases {
+		if got := slidingWindowRate(tc.current, tc.previous, tc.elapsed, window); got != tc.want {
+			t.Fatalf("slidingWindowRate(%d, %d, %v) = %d, want %d", tc.current, tc.previous, tc.elapsed, got, tc.want)
+		}
+	}
+}
+
+func TestSlidingWindowReset(t *testing.T) {
+	window := time.Minute
+
+	cases := map[time.Duration]int{
+		0:                       60,
+		1500 * time.Millisecond: 59,
+		59 * time.Second:        1,
+		window:                  0,
+		window + time.Second:    0,
+	}
+
+	for elapsed, want := range cases {
+		if got := slidingWindowReset(elapsed, window); got != want {
+			t.Fatalf("slidingWindowReset(%v) = %d, want %d", elapsed, got, want)
+		}
+	}
+}
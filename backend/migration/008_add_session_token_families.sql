@@ -0,0 +1,14 @@
+-- Migration: 008_add_session_token_families.sql
+-- Feature: Refresh token rotation with reuse detection
+-- Every rotated refresh token keeps the family_id of the session created at login,
+-- so presenting an already-rotated token can revoke the whole chain.
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS family_id UUID;
+
+-- Existing sessions start their own family
+UPDATE sessions SET family_id = id WHERE family_id IS NULL;
+
+ALTER TABLE sessions ALTER COLUMN family_id SET NOT NULL;
+
+-- Create index on family_id for family-wide revocation
+CREATE INDEX IF NOT EXISTS idx_sessions_family_id ON sessions(family_id);
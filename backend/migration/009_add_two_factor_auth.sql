@@ -0,0 +1,20 @@
+-- Migration: 009_add_two_factor_auth.sql
+-- Feature: TOTP two-factor authentication (RFC 6238)
+
+-- TOTP secret is stored base32-encoded; enrollment is pending until totp_enabled is set
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false;
+-- Last accepted time step, prevents replaying a code within its validity window
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+
+-- Create recovery codes table (one-time codes issued on 2FA enrollment)
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMP
+);
+
+-- Create index on user_id for recovery code lookups
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
@@ -0,0 +1,22 @@
+-- Migration: 010_add_personal_access_tokens.sql
+-- Feature: Personal access tokens for scripts and CI (Authorization: Bearer)
+
+-- Create personal access tokens table (token itself is only shown once; SHA-256 hash is stored)
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    -- First characters of the token, shown in listings so users can tell tokens apart
+    token_prefix VARCHAR(16) NOT NULL,
+    scope VARCHAR(10) NOT NULL DEFAULT 'read' CHECK (scope IN ('read', 'write')),
+    -- NULL means the token may access every project its owner can access
+    project_ids UUID[],
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- Create index on user_id for token listings
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
@@ -0,0 +1,13 @@
+-- Migration: 011_add_external_identities.sql
+-- Feature: Single sign-on accounts (OIDC) provisioned just-in-time
+
+-- Accounts signing in through an external provider have no local password
+ALTER TABLE users ALTER COLUMN password_hash DROP NOT NULL;
+
+-- 'local' accounts use passwords; other providers authenticate externally
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auth_provider VARCHAR(20) NOT NULL DEFAULT 'local';
+-- Stable subject identifier issued by the provider (OIDC "sub")
+ALTER TABLE users ADD COLUMN IF NOT EXISTS external_subject VARCHAR(255);
+
+-- Each external subject maps to exactly one user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_identity ON users(auth_provider, external_subject) WHERE external_subject IS NOT NULL;
@@ -0,0 +1,22 @@
+-- Migration: 012_add_email_verification.sql
+-- Feature: Email verification for new registrations and address changes
+
+-- Existing accounts are treated as verified: the column is backfilled through the
+-- temporary default, which is dropped so new accounts start unverified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE users ALTER COLUMN email_verified_at DROP DEFAULT;
+
+-- Create email verification tokens table (mirrors password_reset_tokens)
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Address being verified; the token is void once the user changes it again
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    used BOOLEAN NOT NULL DEFAULT false
+);
+
+-- Create index on user_id for resend rate limiting
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id, created_at);
@@ -0,0 +1,32 @@
+-- Migration: 013_add_user_invitations.sql
+-- Feature: Admin-managed user invitations with optional project memberships
+
+-- Create project members table (users granted access to a project besides its owner)
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, user_id)
+);
+
+-- Create index on user_id for "projects of a user" lookups
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+
+-- Create user invitations table (token itself is only emailed; SHA-256 hash is stored)
+CREATE TABLE IF NOT EXISTS user_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('admin', 'user')),
+    -- Projects the invitee becomes a member of on acceptance
+    project_ids UUID[],
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP
+);
+
+-- Create index on email for duplicate pending invitation checks
+CREATE INDEX IF NOT EXISTS idx_user_invitations_email ON user_invitations(LOWER(email));
@@ -0,0 +1,16 @@
+-- Migration: 014_add_password_history.sql
+-- Feature: Configurable password policy (maximum age and no reuse of recent passwords)
+
+-- Existing passwords count as changed when this migration first runs, so nobody is expired immediately
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+-- Create password history table (previous bcrypt hashes, pruned to the configured history size)
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on user_id for reuse checks
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created_at DESC);
@@ -0,0 +1,35 @@
+-- Migration: 015_add_audit_log.sql
+-- Feature: Append-only security audit log with a SHA-256 hash chain
+
+-- actor_id and target_id are not foreign keys so entries outlive deleted users
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    outcome VARCHAR(10) NOT NULL CHECK (outcome IN ('success', 'failure')),
+    actor_id UUID,
+    target_id UUID,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    details TEXT,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+-- Create indexes for the admin filters
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log(occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_event_type ON audit_log(event_type);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target_id ON audit_log(target_id);
+
+-- Reject updates and deletes; the hash chain catches changes made around this trigger
+CREATE OR REPLACE FUNCTION prevent_audit_log_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_modification();
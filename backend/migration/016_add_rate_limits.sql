@@ -0,0 +1,14 @@
+-- Migration: 016_add_rate_limits.sql
+-- Feature: Rate limit counters shared by every API replica (sliding window over fixed windows)
+
+-- Counters are disposable, so the table skips the write-ahead log
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limits (
+    key VARCHAR(255) NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    hits INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+-- Create index on expires_at for purging old windows
+CREATE INDEX IF NOT EXISTS idx_rate_limits_expires_at ON rate_limits(expires_at);
@@ -0,0 +1,48 @@
+-- Migration: 017_add_project_member_roles.sql
+-- Feature: Project membership with per-project roles and a single access rule
+
+-- Existing users keep access to the private projects they work in. The backfill only runs when the
+-- role column is added, since migrations are re-run and later removals must stick.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'project_members' AND column_name = 'role') THEN
+        ALTER TABLE project_members ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'developer';
+
+        -- Project owners become managers of their projects
+        INSERT INTO project_members (project_id, user_id, role)
+        SELECT p.id, owner.user_id, 'manager'
+        FROM projects p
+        CROSS JOIN LATERAL (VALUES (p.created_by), (p.user_id)) AS owner(user_id)
+        WHERE owner.user_id IS NOT NULL
+        ON CONFLICT (project_id, user_id) DO NOTHING;
+
+        -- Assignees and authors of tasks become developers of the task's project
+        INSERT INTO project_members (project_id, user_id, role)
+        SELECT DISTINCT t.project_id, worker.user_id, 'developer'
+        FROM tasks t
+        CROSS JOIN LATERAL (VALUES (t.assignee_id), (t.created_by), (t.author_id)) AS worker(user_id)
+        JOIN users u ON u.id = worker.user_id
+        ON CONFLICT (project_id, user_id) DO NOTHING;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'project_members_role_check') THEN
+        ALTER TABLE project_members ADD CONSTRAINT project_members_role_check
+            CHECK (role IN ('manager', 'developer', 'reporter', 'viewer'));
+    END IF;
+END $$;
+
+-- project_role returns the user's effective role in a project, or NULL when the project is hidden from them.
+-- Admins manage every project; anyone may view public projects.
+CREATE OR REPLACE FUNCTION project_role(p_project_id UUID, p_user_id UUID, p_system_role TEXT)
+RETURNS TEXT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT CASE
+        WHEN p_system_role = 'admin' THEN 'manager'
+        ELSE COALESCE(
+            (SELECT m.role::TEXT FROM project_members m WHERE m.project_id = p_project_id AND m.user_id = p_user_id),
+            (SELECT 'viewer' FROM projects p WHERE p.id = p_project_id AND p.is_public)
+        )
+    END
+$$;
@@ -0,0 +1,60 @@
+-- Migration: 018_add_roles_and_permissions.sql
+-- Feature: Admin-defined system roles that bundle named permissions
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(20) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    is_builtin BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The former fixed roles become built-in defaults
+INSERT INTO roles (name, description, permissions, is_builtin) VALUES
+    ('admin', 'Full access to every project and to administration',
+        ARRAY['project.create', 'project.view_all', 'project.manage_all', 'project.manage_members',
+              'task.edit', 'task.delete', 'timelog.view_all', 'attachment.delete_any',
+              'user.manage', 'role.manage', 'audit.view'], true),
+    ('user', 'Default role for new accounts', ARRAY['project.create'], true)
+ON CONFLICT (name) DO NOTHING;
+
+-- users.role and user_invitations.role reference roles instead of a fixed list
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE user_invitations DROP CONSTRAINT IF EXISTS user_invitations_role_check;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_role_fkey') THEN
+        ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'user_invitations_role_fkey') THEN
+        ALTER TABLE user_invitations ADD CONSTRAINT user_invitations_role_fkey FOREIGN KEY (role) REFERENCES roles(name);
+    END IF;
+END $$;
+
+-- role_has_permission reports whether a system role grants the permission
+CREATE OR REPLACE FUNCTION role_has_permission(p_role TEXT, p_permission TEXT)
+RETURNS BOOLEAN
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT COALESCE((SELECT p_permission = ANY(r.permissions) FROM roles r WHERE r.name = p_role), false)
+$$;
+
+-- project_role now follows permissions instead of the admin role name:
+-- project.manage_all manages every project, project.view_all views every project.
+CREATE OR REPLACE FUNCTION project_role(p_project_id UUID, p_user_id UUID, p_system_role TEXT)
+RETURNS TEXT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT CASE
+        WHEN role_has_permission(p_system_role, 'project.manage_all') THEN 'manager'
+        ELSE COALESCE(
+            (SELECT m.role::TEXT FROM project_members m WHERE m.project_id = p_project_id AND m.user_id = p_user_id),
+            (SELECT 'viewer' FROM projects p WHERE p.id = p_project_id AND p.is_public),
+            CASE WHEN role_has_permission(p_system_role, 'project.view_all') THEN 'viewer' END
+        )
+    END
+$$;
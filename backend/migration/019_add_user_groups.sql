@@ -0,0 +1,64 @@
+-- Migration: 019_add_user_groups.sql
+-- Feature: Admin-managed user groups that can join projects and be assigned tasks
+
+CREATE TABLE IF NOT EXISTS user_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_group_members (
+    group_id UUID NOT NULL REFERENCES user_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_group_members_user_id ON user_group_members(user_id);
+
+-- Groups join projects with a project role, like individual members
+CREATE TABLE IF NOT EXISTS project_group_members (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    group_id UUID NOT NULL REFERENCES user_groups(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'developer'
+        CHECK (role IN ('manager', 'developer', 'reporter', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, group_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_group_members_group_id ON project_group_members(group_id);
+
+-- A task may be assigned to a group alongside (or instead of) a single user
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee_group_id UUID REFERENCES user_groups(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee_group_id ON tasks(assignee_group_id);
+
+-- Admins manage groups
+UPDATE roles SET permissions = array_append(permissions, 'group.manage'), updated_at = NOW()
+WHERE name = 'admin' AND NOT ('group.manage' = ANY(permissions));
+
+-- project_role also considers the user's groups; the strongest membership wins
+CREATE OR REPLACE FUNCTION project_role(p_project_id UUID, p_user_id UUID, p_system_role TEXT)
+RETURNS TEXT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT CASE
+        WHEN role_has_permission(p_system_role, 'project.manage_all') THEN 'manager'
+        ELSE COALESCE(
+            (SELECT r.role FROM (
+                SELECT m.role::TEXT AS role FROM project_members m
+                WHERE m.project_id = p_project_id AND m.user_id = p_user_id
+                UNION ALL
+                SELECT g.role::TEXT FROM project_group_members g
+                JOIN user_group_members gm ON gm.group_id = g.group_id
+                WHERE g.project_id = p_project_id AND gm.user_id = p_user_id
+            ) r
+            ORDER BY CASE r.role WHEN 'manager' THEN 4 WHEN 'developer' THEN 3 WHEN 'reporter' THEN 2 ELSE 1 END DESC
+            LIMIT 1),
+            (SELECT 'viewer' FROM projects p WHERE p.id = p_project_id AND p.is_public),
+            CASE WHEN role_has_permission(p_system_role, 'project.view_all') THEN 'viewer' END
+        )
+    END
+$$;
@@ -0,0 +1,34 @@
+-- Migration: 020_add_project_share_links.sql
+-- Feature: Revocable, expiring read-only share links for people without an account
+
+-- The token itself is only shown once; its SHA-256 hash is stored
+CREATE TABLE IF NOT EXISTS project_share_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    label VARCHAR(100) NOT NULL DEFAULT '',
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    -- First characters of the token, shown in listings so managers can tell links apart
+    token_prefix VARCHAR(16) NOT NULL,
+    include_attachments BOOLEAN NOT NULL DEFAULT false,
+    include_comments BOOLEAN NOT NULL DEFAULT false,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    last_accessed_at TIMESTAMPTZ,
+    access_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_share_links_project_id ON project_share_links(project_id);
+
+-- Every anonymous request made through a share link
+CREATE TABLE IF NOT EXISTS project_share_link_accesses (
+    id BIGSERIAL PRIMARY KEY,
+    link_id UUID NOT NULL REFERENCES project_share_links(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    accessed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_share_link_accesses_link_id ON project_share_link_accesses(link_id, accessed_at DESC);
@@ -0,0 +1,21 @@
+-- Migration: 021_add_project_hierarchy.sql
+-- Feature: Subprojects with arbitrary nesting
+
+-- No ON DELETE action: a parent can only be deleted together with its whole subtree
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES projects(id);
+
+CREATE INDEX IF NOT EXISTS idx_projects_parent_id ON projects(parent_id);
+
+-- The project and all of its descendants
+CREATE OR REPLACE FUNCTION project_subtree(p_project_id UUID)
+RETURNS SETOF UUID
+LANGUAGE sql
+STABLE
+AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT p_project_id
+        UNION
+        SELECT p.id FROM projects p JOIN tree t ON p.parent_id = t.id
+    )
+    SELECT id FROM tree
+$$;
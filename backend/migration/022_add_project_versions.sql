@@ -0,0 +1,52 @@
+-- Migration: 022_add_project_versions.sql
+-- Feature: Project versions (milestones) that tasks can target, and the roadmap built from them
+
+-- open: tasks can be assigned; locked: no new tasks; closed: done
+-- sharing: which other projects may assign tasks to the version
+--   none: only its project; descendants: its subprojects; hierarchy: its ancestors and subprojects;
+--   tree: every project under the same root; system: all projects
+CREATE TABLE IF NOT EXISTS project_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    due_date DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'locked', 'closed')),
+    sharing VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (sharing IN ('none', 'descendants', 'hierarchy', 'tree', 'system')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_versions_project_id ON project_versions(project_id);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS fixed_version_id UUID REFERENCES project_versions(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_fixed_version_id ON tasks(fixed_version_id);
+
+-- The top-most ancestor of a project
+CREATE OR REPLACE FUNCTION project_root(p_project_id UUID)
+RETURNS UUID
+LANGUAGE sql
+STABLE
+AS $$
+    WITH RECURSIVE up(id, parent_id) AS (
+        SELECT id, parent_id FROM projects WHERE id = p_project_id
+        UNION
+        SELECT p.id, p.parent_id FROM projects p JOIN up ON p.id = up.parent_id
+    )
+    SELECT id FROM up WHERE parent_id IS NULL
+$$;
+
+-- Whether a version owned by p_version_project with the given sharing can be used in p_project_id
+CREATE OR REPLACE FUNCTION version_shared_with(p_sharing TEXT, p_version_project UUID, p_project_id UUID)
+RETURNS BOOLEAN
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT p_version_project = p_project_id
+        OR p_sharing = 'system'
+        OR (p_sharing IN ('descendants', 'hierarchy') AND p_project_id IN (SELECT project_subtree(p_version_project)))
+        OR (p_sharing = 'hierarchy' AND p_version_project IN (SELECT project_subtree(p_project_id)))
+        OR (p_sharing = 'tree' AND project_root(p_version_project) = project_root(p_project_id))
+$$;
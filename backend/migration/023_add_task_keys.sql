@@ -0,0 +1,46 @@
+-- Migration: 023_add_task_keys.sql
+-- Feature: Per-project task numbers and human-readable keys such as WEB-142
+
+-- Last number handed out in the project; incremented under the row lock so numbers never repeat
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS task_sequence INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS sequence_number INTEGER;
+
+-- Number existing tasks in creation order, after any numbers already given
+WITH numbered AS (
+    SELECT t.id,
+           ROW_NUMBER() OVER (PARTITION BY t.project_id ORDER BY t.created_at, t.id)
+             + COALESCE((SELECT MAX(n.sequence_number) FROM tasks n WHERE n.project_id = t.project_id), 0) AS seq
+    FROM tasks t
+    WHERE t.sequence_number IS NULL
+)
+UPDATE tasks SET sequence_number = numbered.seq
+FROM numbered
+WHERE tasks.id = numbered.id;
+
+UPDATE projects p
+SET task_sequence = GREATEST(p.task_sequence, (SELECT COALESCE(MAX(t.sequence_number), 0) FROM tasks t WHERE t.project_id = p.id));
+
+ALTER TABLE tasks ALTER COLUMN sequence_number SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_project_sequence ON tasks(project_id, sequence_number);
+
+-- Keys a task had in projects it was moved out of, so old links keep working
+CREATE TABLE IF NOT EXISTS task_key_redirects (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    sequence_number INTEGER NOT NULL,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, sequence_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_key_redirects_task_id ON task_key_redirects(task_id);
+
+-- The key of a task: the project identifier and the task's number in it
+CREATE OR REPLACE FUNCTION task_key(p_project_id UUID, p_sequence_number INTEGER)
+RETURNS TEXT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT COALESCE(identifier || '-' || p_sequence_number, '') FROM projects WHERE id = p_project_id
+$$;
@@ -0,0 +1,39 @@
+-- Migration: 024_add_project_statuses.sql
+-- Feature: Admin-configurable project statuses replacing the fixed active/completed/archived CHECK
+
+-- is_closed: the project is finished and left out of active counts and deadlines
+-- is_read_only: tasks, comments, time logs and attachments of the project can no longer change
+CREATE TABLE IF NOT EXISTS project_statuses (
+    name VARCHAR(50) PRIMARY KEY,
+    is_closed BOOLEAN NOT NULL DEFAULT false,
+    is_read_only BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO project_statuses (name, is_closed, is_read_only, position) VALUES
+    ('active', false, false, 1),
+    ('completed', true, false, 2),
+    ('archived', true, true, 3)
+ON CONFLICT (name) DO NOTHING;
+
+-- Keep statuses already stored on projects (older data used values such as 'In Progress')
+INSERT INTO project_statuses (name, is_closed, position)
+SELECT DISTINCT status, LOWER(status) IN ('completed', 'closed', 'done', 'cancelled'), 100
+FROM projects
+WHERE status IS NOT NULL
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_status_check;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'projects_status_fkey') THEN
+        ALTER TABLE projects ADD CONSTRAINT projects_status_fkey FOREIGN KEY (status) REFERENCES project_statuses(name);
+    END IF;
+END $$;
+
+-- Admins manage project statuses
+UPDATE roles SET permissions = array_append(permissions, 'project_status.manage'), updated_at = NOW()
+WHERE name = 'admin' AND NOT ('project_status.manage' = ANY(permissions));
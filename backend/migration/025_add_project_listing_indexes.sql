@@ -0,0 +1,10 @@
+-- Migration: 025_add_project_listing_indexes.sql
+-- Feature: Indexes behind the filtered, sorted and cursor-paginated project listing
+
+-- Keyset pagination orders by (sort key, id)
+CREATE INDEX IF NOT EXISTS idx_projects_created_at_id ON projects(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_projects_updated_at_id ON projects(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_projects_title_id ON projects(title, id);
+CREATE INDEX IF NOT EXISTS idx_projects_due_date_id ON projects((COALESCE(due_date, 'infinity'::date)), id);
+
+CREATE INDEX IF NOT EXISTS idx_projects_created_by ON projects(created_by);
@@ -0,0 +1,7 @@
+-- Migration: 026_add_project_templates.sql
+-- Feature: Project templates that new projects are copied from
+
+-- Templates are listed separately and left out of project listings and dashboard counts
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS is_template BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_projects_is_template ON projects(is_template) WHERE is_template;
@@ -0,0 +1,65 @@
+-- Migration: 027_add_soft_delete.sql
+-- Feature: Deleted projects and tasks go to a trash, can be restored, and are purged after a retention window
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Admins see and restore everything in the trash
+UPDATE roles SET permissions = array_append(permissions, 'trash.manage'), updated_at = NOW()
+WHERE name = 'admin' AND NOT ('trash.manage' = ANY(permissions));
+
+-- The role a user has in a project whether or not it is in the trash; restoring a project checks it
+CREATE OR REPLACE FUNCTION project_role_including_deleted(p_project_id UUID, p_user_id UUID, p_system_role TEXT)
+RETURNS TEXT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT CASE
+        WHEN role_has_permission(p_system_role, 'project.manage_all') THEN 'manager'
+        ELSE COALESCE(
+            (SELECT r.role FROM (
+                SELECT m.role::TEXT AS role FROM project_members m
+                WHERE m.project_id = p_project_id AND m.user_id = p_user_id
+                UNION ALL
+                SELECT g.role::TEXT FROM project_group_members g
+                JOIN user_group_members gm ON gm.group_id = g.group_id
+                WHERE g.project_id = p_project_id AND gm.user_id = p_user_id
+            ) r
+            ORDER BY CASE r.role WHEN 'manager' THEN 4 WHEN 'developer' THEN 3 WHEN 'reporter' THEN 2 ELSE 1 END DESC
+            LIMIT 1),
+            (SELECT 'viewer' FROM projects p WHERE p.id = p_project_id AND p.is_public),
+            CASE WHEN role_has_permission(p_system_role, 'project.view_all') THEN 'viewer' END
+        )
+    END
+$$;
+
+-- Deleted projects are hidden from everyone; every access check and listing goes through project_role
+CREATE OR REPLACE FUNCTION project_role(p_project_id UUID, p_user_id UUID, p_system_role TEXT)
+RETURNS TEXT
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT CASE
+        WHEN EXISTS (SELECT 1 FROM projects d WHERE d.id = p_project_id AND d.deleted_at IS NOT NULL) THEN NULL
+        ELSE project_role_including_deleted(p_project_id, p_user_id, p_system_role)
+    END
+$$;
+
+-- Deleted subprojects no longer belong to the subtree (progress roll-ups, subtree task lists, version sharing)
+CREATE OR REPLACE FUNCTION project_subtree(p_project_id UUID)
+RETURNS SETOF UUID
+LANGUAGE sql
+STABLE
+AS $$
+    WITH RECURSIVE tree(id) AS (
+        SELECT p_project_id
+        UNION
+        SELECT p.id FROM projects p JOIN tree t ON p.parent_id = t.id WHERE p.deleted_at IS NULL
+    )
+    SELECT id FROM tree
+$$;
@@ -0,0 +1,105 @@
+-- Migration: 028_add_task_search.sql
+-- Feature: Server-side full-text task search over titles, descriptions and comments
+
+-- Folds the ways the same Persian text gets typed into one spelling: Arabic yeh and kaf become
+-- Persian yeh and keheh, teh marbuta and heh with yeh above become heh, Persian and Arabic-Indic
+-- digits become ASCII digits, a zero-width non-joiner separates words like a space, and
+-- diacritics and tatweel are dropped. Documents and queries both go through it.
+CREATE OR REPLACE FUNCTION normalize_persian(p_text TEXT)
+RETURNS TEXT
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT lower(translate(
+        regexp_replace(COALESCE(p_text, ''), '[\u064B-\u065F\u0670\u0640]', '', 'g'),
+        'يكىةۀ٠١٢٣٤٥٦٧٨٩۰۱۲۳۴۵۶۷۸۹' || chr(8204),
+        'یکیهه01234567890123456789 '
+    ))
+$$;
+
+-- Parses a search box query like websearch_to_tsquery, after normalize_persian, but lets the last
+-- word match as a prefix so results keep up while the user is still typing it. A last word that is
+-- excluded with a minus or closes a quoted phrase stays exact.
+CREATE OR REPLACE FUNCTION task_search_query(p_query TEXT)
+RETURNS TSQUERY
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT CASE
+        WHEN p_query ~ '("|(^|\s)-\S*)\s*$' THEN q
+        ELSE regexp_replace(q::text, '''$', ''':*')::tsquery
+    END
+    FROM websearch_to_tsquery('simple', normalize_persian(p_query)) q
+$$;
+
+-- ts_headline for search results: the text is shown as it was typed, unless no word in it matches
+-- before normalize_persian (e.g. it was typed with an Arabic yeh or kaf); then its
+-- normalized spelling is shown, so the words that matched are still marked
+CREATE OR REPLACE FUNCTION task_search_headline(p_text TEXT, p_query TSQUERY, p_options TEXT)
+RETURNS TEXT
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT CASE
+        WHEN to_tsvector('simple', p_text) @@ p_query THEN ts_headline('simple', p_text, p_query, p_options)
+        ELSE ts_headline('simple', normalize_persian(p_text), p_query, p_options)
+    END
+$$;
+
+-- One search document per task, kept outside tasks so new comments do not touch tasks.updated_at
+CREATE TABLE IF NOT EXISTS task_search_documents (
+    task_id UUID PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_search_documents_document ON task_search_documents USING GIN(document);
+
+-- Title words weigh most, then the description, then the comments
+CREATE OR REPLACE FUNCTION refresh_task_search_document(p_task_id UUID)
+RETURNS VOID
+LANGUAGE sql
+AS $$
+    INSERT INTO task_search_documents (task_id, document)
+    SELECT t.id,
+           setweight(to_tsvector('simple', normalize_persian(t.title)), 'A')
+           || setweight(to_tsvector('simple', normalize_persian(t.description)), 'B')
+           || setweight(to_tsvector('simple', normalize_persian(
+                  (SELECT string_agg(c.content, ' ') FROM comments c WHERE c.task_id = t.id))), 'C')
+    FROM tasks t
+    WHERE t.id = p_task_id
+    ON CONFLICT (task_id) DO UPDATE SET document = EXCLUDED.document
+$$;
+
+CREATE OR REPLACE FUNCTION refresh_task_search_document_from_task()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_task_search_document(NEW.id);
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS tasks_search_document ON tasks;
+CREATE TRIGGER tasks_search_document AFTER INSERT OR UPDATE OF title, description ON tasks
+FOR EACH ROW EXECUTE FUNCTION refresh_task_search_document_from_task();
+
+-- A task deleted together with its comments has no row left, so its refresh inserts nothing
+CREATE OR REPLACE FUNCTION refresh_task_search_document_from_comment()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_task_search_document(OLD.task_id);
+    ELSE
+        PERFORM refresh_task_search_document(NEW.task_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS comments_search_document ON comments;
+CREATE TRIGGER comments_search_document AFTER INSERT OR UPDATE OF content OR DELETE ON comments
+FOR EACH ROW EXECUTE FUNCTION refresh_task_search_document_from_comment();
+
+-- Index the tasks that existed before this migration
+SELECT refresh_task_search_document(t.id)
+FROM tasks t
+WHERE NOT EXISTS (SELECT 1 FROM task_search_documents d WHERE d.task_id = t.id);
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit event types
+const (
+	AuditEventLogin                = "login"
+	AuditEventLogout               = "logout"
+	AuditEventAccountLocked        = "account_locked"
+	AuditEventRefreshTokenReuse    = "refresh_token_reuse"
+	AuditEventPasswordResetRequest = "password_reset_requested"
+	AuditEventPasswordReset        = "password_reset"
+	AuditEventPasswordChanged      = "password_changed"
+	AuditEventTwoFactorEnabled     = "two_factor_enabled"
+	AuditEventTwoFactorDisabled    = "two_factor_disabled"
+	AuditEventRoleChanged          = "role_changed"
+	AuditEventUserActivated        = "user_activated"
+	AuditEventUserDeactivated      = "user_deactivated"
+	AuditEventUserSessionsRevoked  = "user_sessions_revoked"
+	AuditEventRoleCreated          = "role_created"
+	AuditEventRoleUpdated          = "role_updated"
+	AuditEventRoleDeleted          = "role_deleted"
+	AuditEventProjectStatusCreated = "project_status_created"
+	AuditEventProjectStatusUpdated = "project_status_updated"
+	AuditEventProjectStatusDeleted = "project_status_deleted"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditGenesisHash is the prev_hash of the first entry in the chain
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditEntry is one append-only row of the security audit log
+type AuditEntry struct {
+	ID          int64             `json:"id"`
+	OccurredAt  time.Time         `json:"occurred_at"`
+	EventType   string            `json:"event_type"`
+	Outcome     string            `json:"outcome"`
+	ActorID     *uuid.UUID        `json:"actor_id,omitempty"`
+	TargetID    *uuid.UUID        `json:"target_id,omitempty"`
+	IPAddress   string            `json:"ip_address"`
+	UserAgent   string            `json:"user_agent"`
+	Details     map[string]string `json:"details,omitempty"`
+	PrevHash    string            `json:"prev_hash"`
+	Hash        string            `json:"hash"`
+	ActorEmail  *string           `json:"actor_email,omitempty"`  // Joined for display, not part of the hash
+	TargetEmail *string           `json:"target_email,omitempty"` // Joined for display, not part of the hash
+}
+
+// ComputeHash returns the SHA-256 over the previous hash and every stored field
+func (e *AuditEntry) ComputeHash() string {
+	// Empty details are stored as NULL and must hash the same way when read back
+	details := e.Details
+	if len(details) == 0 {
+		details = nil
+	}
+
+	payload, _ := json.Marshal(struct {
+		PrevHash   string            `json:"prev_hash"`
+		OccurredAt string            `json:"occurred_at"`
+		EventType  string            `json:"event_type"`
+		Outcome    string            `json:"outcome"`
+		ActorID    *uuid.UUID        `json:"actor_id"`
+		TargetID   *uuid.UUID        `json:"target_id"`
+		IPAddress  string            `json:"ip_address"`
+		UserAgent  string            `json:"user_agent"`
+		Details    map[string]string `json:"details"`
+	}{
+		PrevHash:   e.PrevHash,
+		OccurredAt: e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		EventType:  e.EventType,
+		Outcome:    e.Outcome,
+		ActorID:    e.ActorID,
+		TargetID:   e.TargetID,
+		IPAddress:  e.IPAddress,
+		UserAgent:  e.UserAgent,
+		Details:    details,
+	})
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter narrows the admin audit log listing
+type AuditFilter struct {
+	EventType string
+	Outcome   string
+	ActorID   *uuid.UUID
+	TargetID  *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+}
+
+// AuditChainStatus is the result of verifying the hash chain
+type AuditChainStatus struct {
+	Valid          bool   `json:"valid"`
+	EntriesChecked int64  `json:"entries_checked"`
+	BrokenAtID     *int64 `json:"broken_at_id,omitempty"`
+}
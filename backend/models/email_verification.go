@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerificationToken represents an email address verification token
+type EmailVerificationToken struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"-"` // Never send token hash to client
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Used      bool      `json:"used"`
+}
+
+// VerifyEmailRequest represents an email verification confirmation
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group is an admin-managed set of users that can join projects and be assigned tasks
+type Group struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type GroupMember struct {
+	GroupID   uuid.UUID `json:"group_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AddGroupMemberRequest identifies the user by id or by email
+type AddGroupMemberRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email,omitempty"`
+}
+
+// ProjectGroup is a group's membership in a project
+type ProjectGroup struct {
+	ProjectID   uuid.UUID `json:"project_id"`
+	GroupID     uuid.UUID `json:"group_id"`
+	Name        string    `json:"name"`
+	MemberCount int       `json:"member_count"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AddProjectGroupRequest struct {
+	GroupID uuid.UUID `json:"group_id"`
+	Role    string    `json:"role"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Personal access token scopes
+const (
+	TokenScopeRead  = "read"
+	TokenScopeWrite = "write"
+)
+
+// PersonalAccessToken represents a named API token used via Authorization: Bearer
+type PersonalAccessToken struct {
+	ID          uuid.UUID   `json:"id"`
+	UserID      uuid.UUID   `json:"user_id"`
+	Name        string      `json:"name"`
+	TokenHash   string      `json:"-"` // Never send token hash to client
+	TokenPrefix string      `json:"token_prefix"`
+	Scope       string      `json:"scope"`
+	ProjectIDs  []uuid.UUID `json:"project_ids"` // Empty means all projects of the owner
+	ExpiresAt   time.Time   `json:"expires_at"`
+	LastUsedAt  *time.Time  `json:"last_used_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+	RevokedAt   *time.Time  `json:"revoked_at,omitempty"`
+
+	// Owner state, loaded when authenticating a request
+	OwnerRole     string `json:"-"`
+	OwnerIsActive bool   `json:"-"`
+}
+
+// CreatePersonalAccessTokenRequest represents a token creation request
+type CreatePersonalAccessTokenRequest struct {
+	Name       string      `json:"name"`
+	Scope      string      `json:"scope"`
+	ProjectIDs []uuid.UUID `json:"project_ids"`
+	ExpiresAt  *time.Time  `json:"expires_at"`
+}
+
+// CreatedPersonalAccessToken is returned once on creation, with the plaintext token
+type CreatedPersonalAccessToken struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,8 @@ type Project struct {
 	Identifier  string     `json:"identifier"`
 	Homepage    *string    `json:"homepage,omitempty"`
 	IsPublic    bool       `json:"is_public"`
+	IsTemplate  bool       `json:"is_template"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
 	UserID      *uuid.UUID `json:"user_id,omitempty"`
 	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
 	StartDate   *time.Time `json:"start_date,omitempty"`
@@ -29,6 +32,8 @@ type CreateProjectRequest struct {
 	Identifier  string     `json:"identifier"`
 	Homepage    *string    `json:"homepage,omitempty"`
 	IsPublic    bool       `json:"is_public"`
+	IsTemplate  bool       `json:"is_template"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
 	StartDate   *time.Time `json:"start_date,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
 }
@@ -40,8 +45,99 @@ type UpdateProjectRequest struct {
 	Identifier  string     `json:"identifier"`
 	Homepage    *string    `json:"homepage,omitempty"`
 	IsPublic    bool       `json:"is_public"`
+	IsTemplate  *bool      `json:"is_template,omitempty"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
 	StartDate   *time.Time `json:"start_date,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
+
+	// ParentSet tells a parent_id of null, which moves the project to the top level, from a missing one
+	ParentSet bool `json:"-"`
+}
+
+// UnmarshalJSON records whether parent_id was sent, so leaving it out keeps the current parent
+func (r *UpdateProjectRequest) UnmarshalJSON(data []byte) error {
+	type request UpdateProjectRequest
+	if err := json.Unmarshal(data, (*request)(r)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	_, r.ParentSet = fields["parent_id"]
+	return nil
+}
+
+// CopyProjectRequest creates a project from an existing project or template; the flags pick what is copied
+type CopyProjectRequest struct {
+	Title      string     `json:"title"`
+	Identifier string     `json:"identifier"`
+	Status     string     `json:"status"`
+	ParentID   *uuid.UUID `json:"parent_id,omitempty"`
+	IsTemplate bool       `json:"is_template"`
+	// StartDate becomes the copy's start date; task and version dates move by the same number of days
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	Tasks       bool       `json:"tasks"`
+	Categories  bool       `json:"categories"`
+	Members     bool       `json:"members"`
+	Versions    bool       `json:"versions"`
+	Attachments bool       `json:"attachments"`
+}
+
+// CopiedFile is an attachment whose stored file was already duplicated for a project copy
+type CopiedFile struct {
+	SourceID       uuid.UUID
+	StoredFilename string
+	FilePath       string
+	ThumbnailPath  *string
+}
+
+// ProjectNode is a project with its visible subprojects, for tree listings
+type ProjectNode struct {
+	Project
+	Children []ProjectNode `json:"children"`
+}
+
+// ProjectSortFields are the fields project listings can be sorted by
+var ProjectSortFields = []string{"created_at", "updated_at", "title", "identifier", "due_date"}
+
+// ProjectFilter narrows and orders a project listing; zero values leave a criterion out
+type ProjectFilter struct {
+	Statuses    []string
+	Closed      *bool
+	OwnerID     *uuid.UUID
+	IsPublic    *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	DueFrom     *time.Time
+	DueTo       *time.Time
+	Query       string
+	Templates   bool
+	ProjectIDs  []uuid.UUID
+	Sort        string
+	Descending  bool
+	// OpenFirst lists projects in open statuses before closed ones, each group in Sort order
+	OpenFirst bool
+	After     *ProjectCursor
+	Limit     int
+}
+
+// ProjectCursor is the sort field, sort key and ID of the last project on a page. Open-first
+// listings also record whether that project is closed.
+type ProjectCursor struct {
+	Sort      string    `json:"s"`
+	Key       string    `json:"k"`
+	ID        uuid.UUID `json:"id"`
+	OpenFirst bool      `json:"o,omitempty"`
+	Closed    bool      `json:"c,omitempty"`
+}
+
+// ProjectPage is one page of a cursor-paginated project listing
+type ProjectPage struct {
+	Projects   []Project `json:"projects"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	HasMore    bool      `json:"has_more"`
 }
 
 var (
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Per-project roles, from most to least privileged
+const (
+	ProjectRoleManager   = "manager"
+	ProjectRoleDeveloper = "developer"
+	ProjectRoleReporter  = "reporter"
+	ProjectRoleViewer    = "viewer"
+)
+
+var projectRoleRank = map[string]int{
+	ProjectRoleViewer:    1,
+	ProjectRoleReporter:  2,
+	ProjectRoleDeveloper: 3,
+	ProjectRoleManager:   4,
+}
+
+// IsValidProjectRole reports whether role is one of the per-project roles
+func IsValidProjectRole(role string) bool {
+	_, ok := projectRoleRank[role]
+	return ok
+}
+
+// ProjectRoleAtLeast reports whether role grants everything min does
+func ProjectRoleAtLeast(role, min string) bool {
+	return projectRoleRank[role] >= projectRoleRank[min] && projectRoleRank[role] > 0
+}
+
+type ProjectMember struct {
+	ProjectID uuid.UUID `json:"project_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddProjectMemberRequest identifies the user by id or, for managers who cannot list users, by email
+type AddProjectMemberRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email,omitempty"`
+	Role   string    `json:"role"`
+}
+
+type UpdateProjectMemberRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ProjectStatus is an admin-defined project status; projects.status holds its name
+type ProjectStatus struct {
+	Name         string    `json:"name"`
+	IsClosed     bool      `json:"is_closed"`
+	IsReadOnly   bool      `json:"is_read_only"`
+	Position     int       `json:"position"`
+	ProjectCount int       `json:"project_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type CreateProjectStatusRequest struct {
+	Name       string `json:"name"`
+	IsClosed   bool   `json:"is_closed"`
+	IsReadOnly bool   `json:"is_read_only"`
+	Position   int    `json:"position"`
+}
+
+type UpdateProjectStatusRequest struct {
+	IsClosed   bool `json:"is_closed"`
+	IsReadOnly bool `json:"is_read_only"`
+	Position   int  `json:"position"`
+}
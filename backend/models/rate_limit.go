@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RateLimitWindow holds the counters for one limiter key after recording a hit
+type RateLimitWindow struct {
+	WindowStart time.Time
+	Current     int
+	Previous    int
+	// Time elapsed since WindowStart, measured by the database clock
+	Elapsed time.Duration
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Built-in system roles
+const (
+	SystemRoleAdmin = "admin"
+	SystemRoleUser  = "user"
+)
+
+// Permissions that system roles can bundle
+const (
+	PermissionProjectCreate        = "project.create"
+	PermissionProjectViewAll       = "project.view_all"
+	PermissionProjectManageAll     = "project.manage_all"
+	PermissionProjectManageMembers = "project.manage_members"
+	PermissionTaskEdit             = "task.edit"
+	PermissionTaskDelete           = "task.delete"
+	PermissionTimeLogViewAll       = "timelog.view_all"
+	PermissionAttachmentDeleteAny  = "attachment.delete_any"
+	PermissionUserManage           = "user.manage"
+	PermissionRoleManage           = "role.manage"
+	PermissionAuditView            = "audit.view"
+	PermissionGroupManage          = "group.manage"
+	PermissionProjectStatusManage  = "project_status.manage"
+	PermissionTrashManage          = "trash.manage"
+)
+
+// AllPermissions lists every known permission in display order
+var AllPermissions = []string{
+	PermissionProjectCreate,
+	PermissionProjectViewAll,
+	PermissionProjectManageAll,
+	PermissionProjectManageMembers,
+	PermissionTaskEdit,
+	PermissionTaskDelete,
+	PermissionTimeLogViewAll,
+	PermissionAttachmentDeleteAny,
+	PermissionUserManage,
+	PermissionRoleManage,
+	PermissionAuditView,
+	PermissionGroupManage,
+	PermissionProjectStatusManage,
+	PermissionTrashManage,
+}
+
+// IsValidPermission reports whether the permission is known
+func IsValidPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Role is a system role; users.role holds its name
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	IsBuiltin   bool      `json:"is_builtin"`
+	UserCount   int       `json:"user_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HasPermission reports whether the role grants the permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
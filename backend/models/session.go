@@ -10,10 +10,13 @@ import (
 type Session struct {
 	ID               uuid.UUID `json:"id"`
 	UserID           uuid.UUID `json:"user_id"`
+	FamilyID         uuid.UUID `json:"-"` // Shared by all sessions rotated from the same login
 	RefreshTokenHash string    `json:"-"` // Never send token hash to client
 	UserAgent        string    `json:"user_agent,omitempty"`
 	IPAddress        string    `json:"ip_address,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	ExpiresAt        time.Time `json:"expires_at"`
 	Revoked          bool      `json:"revoked"`
+	SignedInAt       time.Time `json:"signed_in_at"` // Creation of the first session in the family
+	IsCurrent        bool      `json:"is_current"`   // Set when listing, true for the requesting device
 }
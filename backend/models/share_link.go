@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLink gives anonymous read-only access to one project
+type ShareLink struct {
+	ID                 uuid.UUID  `json:"id"`
+	ProjectID          uuid.UUID  `json:"project_id"`
+	Label              string     `json:"label"`
+	TokenHash          string     `json:"-"` // Never send token hash to client
+	TokenPrefix        string     `json:"token_prefix"`
+	IncludeAttachments bool       `json:"include_attachments"`
+	IncludeComments    bool       `json:"include_comments"`
+	CreatedBy          *uuid.UUID `json:"created_by,omitempty"`
+	ExpiresAt          time.Time  `json:"expires_at"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	LastAccessedAt     *time.Time `json:"last_accessed_at,omitempty"`
+	AccessCount        int        `json:"access_count"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+type CreateShareLinkRequest struct {
+	Label              string     `json:"label"`
+	IncludeAttachments bool       `json:"include_attachments"`
+	IncludeComments    bool       `json:"include_comments"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// CreatedShareLink is returned once on creation, with the plaintext token
+type CreatedShareLink struct {
+	ShareLink
+	Token string `json:"token"`
+}
+
+// ShareLinkAccess is one logged request made through a share link
+type ShareLinkAccess struct {
+	ID         int64     `json:"id"`
+	LinkID     uuid.UUID `json:"link_id"`
+	Path       string    `json:"path"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+// SharedProject is what a share link reveals; it leaves out members and other personal data
+type SharedProject struct {
+	Title              string       `json:"title"`
+	Description        string       `json:"description"`
+	Status             string       `json:"status"`
+	StartDate          *time.Time   `json:"start_date,omitempty"`
+	DueDate            *time.Time   `json:"due_date,omitempty"`
+	TotalTasks         int          `json:"total_tasks"`
+	CompletedTasks     int          `json:"completed_tasks"`
+	Progress           int          `json:"progress"`
+	IncludeAttachments bool         `json:"include_attachments"`
+	IncludeComments    bool         `json:"include_comments"`
+	ExpiresAt          time.Time    `json:"expires_at"`
+	Tasks              []SharedTask `json:"tasks"`
+}
+
+type SharedTask struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	Completed   bool       `json:"completed"`
+	Category    *string    `json:"category,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	DoneRatio   int        `json:"done_ratio"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type SharedComment struct {
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SharedAttachment struct {
+	ID               uuid.UUID `json:"id"`
+	OriginalFilename string    `json:"original_filename"`
+	FileSize         int64     `json:"file_size"`
+	MimeType         string    `json:"mime_type"`
+	CreatedAt        time.Time `json:"created_at"`
+}
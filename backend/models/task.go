@@ -7,68 +7,84 @@ import (
 )
 
 type Task struct {
-	ID             uuid.UUID  `json:"id"`
-	ProjectID      uuid.UUID  `json:"project_id"`
-	Title          string     `json:"title"`
-	Description    string     `json:"description"`
-	Priority       string     `json:"priority"`
-	Completed      bool       `json:"completed"`
-	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty"`
-	AuthorID       *uuid.UUID `json:"author_id,omitempty"`
-	Category       *string    `json:"category,omitempty"`
-	StartDate      *time.Time `json:"start_date,omitempty"`
-	DueDate        *time.Time `json:"due_date,omitempty"`
-	EstimatedHours *float64   `json:"estimated_hours,omitempty"`
-	DoneRatio      int        `json:"done_ratio"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	ProjectID       uuid.UUID  `json:"project_id"`
+	SequenceNumber  int        `json:"sequence_number"`
+	Key             string     `json:"key"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Priority        string     `json:"priority"`
+	Completed       bool       `json:"completed"`
+	AssigneeID      *uuid.UUID `json:"assignee_id,omitempty"`
+	AssigneeGroupID *uuid.UUID `json:"assignee_group_id,omitempty"`
+	FixedVersionID  *uuid.UUID `json:"fixed_version_id,omitempty"`
+	AuthorID        *uuid.UUID `json:"author_id,omitempty"`
+	Category        *string    `json:"category,omitempty"`
+	StartDate       *time.Time `json:"start_date,omitempty"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	EstimatedHours  *float64   `json:"estimated_hours,omitempty"`
+	DoneRatio       int        `json:"done_ratio"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type CreateTaskRequest struct {
-	Title          string     `json:"title"`
-	Description    string     `json:"description"`
-	Priority       string     `json:"priority"`
-	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty"`
-	AuthorID       *uuid.UUID `json:"author_id,omitempty"`
-	Category       *string    `json:"category,omitempty"`
-	StartDate      *time.Time `json:"start_date,omitempty"`
-	DueDate        *time.Time `json:"due_date,omitempty"`
-	EstimatedHours *float64   `json:"estimated_hours,omitempty"`
-	DoneRatio      int        `json:"done_ratio"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Priority        string     `json:"priority"`
+	AssigneeID      *uuid.UUID `json:"assignee_id,omitempty"`
+	AssigneeGroupID *uuid.UUID `json:"assignee_group_id,omitempty"`
+	FixedVersionID  *uuid.UUID `json:"fixed_version_id,omitempty"`
+	AuthorID        *uuid.UUID `json:"author_id,omitempty"`
+	Category        *string    `json:"category,omitempty"`
+	StartDate       *time.Time `json:"start_date,omitempty"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	EstimatedHours  *float64   `json:"estimated_hours,omitempty"`
+	DoneRatio       int        `json:"done_ratio"`
 }
 
 type UpdateTaskRequest struct {
-	Title          string     `json:"title"`
-	Description    string     `json:"description"`
-	Priority       string     `json:"priority"`
-	Completed      bool       `json:"completed"`
-	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty"`
-	AuthorID       *uuid.UUID `json:"author_id,omitempty"`
-	Category       *string    `json:"category,omitempty"`
-	StartDate      *time.Time `json:"start_date,omitempty"`
-	DueDate        *time.Time `json:"due_date,omitempty"`
-	EstimatedHours *float64   `json:"estimated_hours,omitempty"`
-	DoneRatio      int        `json:"done_ratio"`
+	// ProjectID moves the task to another project, where it gets a new key
+	ProjectID       *uuid.UUID `json:"project_id,omitempty"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Priority        string     `json:"priority"`
+	Completed       bool       `json:"completed"`
+	AssigneeID      *uuid.UUID `json:"assignee_id,omitempty"`
+	AssigneeGroupID *uuid.UUID `json:"assignee_group_id,omitempty"`
+	FixedVersionID  *uuid.UUID `json:"fixed_version_id,omitempty"`
+	AuthorID        *uuid.UUID `json:"author_id,omitempty"`
+	Category        *string    `json:"category,omitempty"`
+	StartDate       *time.Time `json:"start_date,omitempty"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	EstimatedHours  *float64   `json:"estimated_hours,omitempty"`
+	DoneRatio       int        `json:"done_ratio"`
 }
 
 type TaskWithUsers struct {
-	ID             uuid.UUID  `json:"id"`
-	ProjectID      uuid.UUID  `json:"project_id"`
-	Title          string     `json:"title"`
-	Description    string     `json:"description"`
-	Priority       string     `json:"priority"`
-	Completed      bool       `json:"completed"`
-	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty"`
-	AssigneeName   *string    `json:"assignee_name,omitempty"`
-	AuthorID       *uuid.UUID `json:"author_id,omitempty"`
-	AuthorName     *string    `json:"author_name,omitempty"`
-	Category       *string    `json:"category,omitempty"`
-	StartDate      *time.Time `json:"start_date,omitempty"`
-	DueDate        *time.Time `json:"due_date,omitempty"`
-	EstimatedHours *float64   `json:"estimated_hours,omitempty"`
-	DoneRatio      int        `json:"done_ratio"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	ID                uuid.UUID  `json:"id"`
+	ProjectID         uuid.UUID  `json:"project_id"`
+	SequenceNumber    int        `json:"sequence_number"`
+	Key               string     `json:"key"`
+	Title             string     `json:"title"`
+	Description       string     `json:"description"`
+	Priority          string     `json:"priority"`
+	Completed         bool       `json:"completed"`
+	AssigneeID        *uuid.UUID `json:"assignee_id,omitempty"`
+	AssigneeName      *string    `json:"assignee_name,omitempty"`
+	AssigneeGroupID   *uuid.UUID `json:"assignee_group_id,omitempty"`
+	AssigneeGroupName *string    `json:"assignee_group_name,omitempty"`
+	FixedVersionID    *uuid.UUID `json:"fixed_version_id,omitempty"`
+	FixedVersionName  *string    `json:"fixed_version_name,omitempty"`
+	AuthorID          *uuid.UUID `json:"author_id,omitempty"`
+	AuthorName        *string    `json:"author_name,omitempty"`
+	Category          *string    `json:"category,omitempty"`
+	StartDate         *time.Time `json:"start_date,omitempty"`
+	DueDate           *time.Time `json:"due_date,omitempty"`
+	EstimatedHours    *float64   `json:"estimated_hours,omitempty"`
+	DoneRatio         int        `json:"done_ratio"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
 
 type PaginatedTasksResponse struct {
@@ -78,3 +94,32 @@ type PaginatedTasksResponse struct {
 	PageSize int    `json:"page_size"`
 	HasMore  bool   `json:"has_more"`
 }
+
+// TaskSearchFilter narrows a task search. Query is matched against titles, descriptions and
+// comments; the date bounds are inclusive and leave out tasks without that date.
+type TaskSearchFilter struct {
+	Query              string
+	StartDateFrom      *time.Time
+	StartDateTo        *time.Time
+	DueDateFrom        *time.Time
+	DueDateTo          *time.Time
+	IncludeSubprojects bool
+}
+
+// TaskSearchResult is a matching task with its rank. The highlights are HTML-escaped snippets with
+// <mark> around the matched words, set only for the fields that matched.
+type TaskSearchResult struct {
+	Task
+	Rank                 float32 `json:"rank"`
+	TitleHighlight       string  `json:"title_highlight,omitempty"`
+	DescriptionHighlight string  `json:"description_highlight,omitempty"`
+	CommentHighlight     string  `json:"comment_highlight,omitempty"`
+}
+
+type TaskSearchResponse struct {
+	Tasks    []TaskSearchResult `json:"tasks"`
+	Total    int                `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	HasMore  bool               `json:"has_more"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrashedProject is a deleted project waiting to be restored or purged
+type TrashedProject struct {
+	ID            uuid.UUID  `json:"id"`
+	Title         string     `json:"title"`
+	Identifier    *string    `json:"identifier,omitempty"`
+	ParentID      *uuid.UUID `json:"parent_id,omitempty"`
+	ParentDeleted bool       `json:"parent_deleted"`
+	TaskCount     int        `json:"task_count"`
+	DeletedAt     time.Time  `json:"deleted_at"`
+	DeletedBy     *uuid.UUID `json:"deleted_by,omitempty"`
+	DeletedByName *string    `json:"deleted_by_name,omitempty"`
+	PurgeAt       time.Time  `json:"purge_at"`
+}
+
+// TrashedTask is a deleted task waiting to be restored or purged
+type TrashedTask struct {
+	ID             uuid.UUID  `json:"id"`
+	ProjectID      uuid.UUID  `json:"project_id"`
+	ProjectTitle   string     `json:"project_title"`
+	Key            string     `json:"key"`
+	Title          string     `json:"title"`
+	ProjectDeleted bool       `json:"project_deleted"`
+	DeletedAt      time.Time  `json:"deleted_at"`
+	DeletedBy      *uuid.UUID `json:"deleted_by,omitempty"`
+	DeletedByName  *string    `json:"deleted_by_name,omitempty"`
+	PurgeAt        time.Time  `json:"purge_at"`
+}
+
+// Trash lists deleted projects and tasks, most recently deleted first
+type Trash struct {
+	Projects []TrashedProject `json:"projects"`
+	Tasks    []TrashedTask    `json:"tasks"`
+}
+
+// PurgedFile is the stored file of an attachment whose row was purged
+type PurgedFile struct {
+	FilePath      string
+	ThumbnailPath *string
+}
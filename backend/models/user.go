@@ -11,16 +11,50 @@ type User struct {
 	ID                  uuid.UUID  `json:"id"`
 	Username            string     `json:"username"`
 	Email               string     `json:"email"`
-	PasswordHash        string     `json:"-"` // Never send password hash to client
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	PasswordHash        string     `json:"-"` // Never send password hash to client; empty for SSO accounts
+	PasswordChangedAt   *time.Time `json:"-"` // Internal use only (password max age)
 	Role                string     `json:"role"`
+	AuthProvider        string     `json:"auth_provider"`
+	ExternalSubject     *string    `json:"-"` // Internal use only
 	IsActive            bool       `json:"is_active"`
 	FailedLoginAttempts int        `json:"-"` // Internal use only
 	LockedUntil         *time.Time `json:"-"` // Internal use only
+	TOTPSecret          *string    `json:"-"` // Never send TOTP secret to client
+	TOTPEnabled         bool       `json:"two_factor_enabled"`
+	TOTPLastStep        *int64     `json:"-"` // Internal use only
 	CreatedAt           time.Time  `json:"created_at"`
 	UpdatedAt           time.Time  `json:"updated_at"`
 	LastLoginAt         *time.Time `json:"last_login_at,omitempty"`
 }
 
+// Authentication providers
+const (
+	AuthProviderLocal = "local"
+	AuthProviderOIDC  = "oidc"
+	AuthProviderLDAP  = "ldap"
+)
+
+// IsEmailVerified reports whether the current email address has been confirmed
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasPassword reports whether the user can sign in with a local password
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// ExternalIdentity is a user identity asserted by an external provider (SSO)
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	Role          string // Empty when the provider did not send group information
+}
+
 // CreateUserRequest represents the registration request
 type CreateUserRequest struct {
 	Username             string `json:"username"`
@@ -35,6 +69,41 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// TwoFactorLoginRequest represents the second login step with a TOTP or recovery code
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// ExpiredPasswordChangeRequest replaces an expired password during login
+type ExpiredPasswordChangeRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	NewPassword    string `json:"new_password"`
+}
+
+// TwoFactorCodeRequest represents a request confirmed with a TOTP code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// DisableTwoFactorRequest represents a request to turn off 2FA
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// TwoFactorSetup is returned when enrollment starts
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorStatus describes the 2FA state of the current user
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
 // LoginResponse represents the login response with tokens
 type LoginResponse struct {
 	User         User   `json:"user"`
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation statuses, derived from the invitation timestamps
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// UserInvitation represents an admin invitation to create an account
+type UserInvitation struct {
+	ID             uuid.UUID   `json:"id"`
+	Email          string      `json:"email"`
+	Role           string      `json:"role"`
+	ProjectIDs     []uuid.UUID `json:"project_ids"`
+	TokenHash      string      `json:"-"` // Never send token hash to client
+	InvitedBy      *uuid.UUID  `json:"invited_by,omitempty"`
+	InvitedByName  *string     `json:"invited_by_name,omitempty"`
+	ExpiresAt      time.Time   `json:"expires_at"`
+	CreatedAt      time.Time   `json:"created_at"`
+	AcceptedAt     *time.Time  `json:"accepted_at,omitempty"`
+	AcceptedUserID *uuid.UUID  `json:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time  `json:"revoked_at,omitempty"`
+	Status         string      `json:"status"`
+}
+
+// CreateInvitationRequest represents an admin invitation request
+type CreateInvitationRequest struct {
+	Email      string      `json:"email"`
+	Role       string      `json:"role"`
+	ProjectIDs []uuid.UUID `json:"project_ids"`
+}
+
+// AcceptInvitationRequest represents the invitee's account details
+type AcceptInvitationRequest struct {
+	Token                string `json:"token"`
+	Username             string `json:"username"`
+	Password             string `json:"password"`
+	PasswordConfirmation string `json:"password_confirmation"`
+}
+
+// InvitationPreview is the public view of an invitation shown on the accept page
+type InvitationPreview struct {
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
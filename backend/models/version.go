@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Version statuses: tasks can only be assigned to open versions
+const (
+	VersionStatusOpen   = "open"
+	VersionStatusLocked = "locked"
+	VersionStatusClosed = "closed"
+)
+
+// Version sharing scopes, from narrowest to widest
+const (
+	VersionSharingNone        = "none"
+	VersionSharingDescendants = "descendants"
+	VersionSharingHierarchy   = "hierarchy"
+	VersionSharingTree        = "tree"
+	VersionSharingSystem      = "system"
+)
+
+// Version is a project milestone that tasks can target
+type Version struct {
+	ID           uuid.UUID  `json:"id"`
+	ProjectID    uuid.UUID  `json:"project_id"`
+	ProjectTitle string     `json:"project_title"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	Status       string     `json:"status"`
+	Sharing      string     `json:"sharing"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type CreateVersionRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Status      string     `json:"status"`
+	Sharing     string     `json:"sharing"`
+}
+
+type UpdateVersionRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Status      string     `json:"status"`
+	Sharing     string     `json:"sharing"`
+	// MoveOpenTasksTo lets a version with open tasks be closed by moving them to another open version
+	MoveOpenTasksTo *uuid.UUID `json:"move_open_tasks_to,omitempty"`
+}
+
+// VersionTaskProgress is the part of a task the roadmap needs
+type VersionTaskProgress struct {
+	VersionID      uuid.UUID
+	Completed      bool
+	DoneRatio      int
+	EstimatedHours *float64
+}
+
+// RoadmapVersion is a version with the progress of its tasks
+type RoadmapVersion struct {
+	Version
+	TotalTasks     int     `json:"total_tasks"`
+	OpenTasks      int     `json:"open_tasks"`
+	ClosedTasks    int     `json:"closed_tasks"`
+	EstimatedHours float64 `json:"estimated_hours"`
+	DoneRatio      int     `json:"done_ratio"`
+	Late           bool    `json:"late"`
+}
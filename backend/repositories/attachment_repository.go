@@ -188,4 +188,30 @@ func (r *AttachmentRepository) Update(ctx context.Context, id uuid.UUID, hasThum
 		`UPDATE task_attachments SET has_thumbnail = $1, thumbnail_path = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3`,
 		hasThumb, thumbPath, id)
 	return err
-}
\ No newline at end of file
+}
+
+// GetByProjectID retrieves the attachments of every task in a project, leaving out deleted tasks
+func (r *AttachmentRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.TaskAttachment, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT ta.id, ta.task_id, ta.original_filename, ta.stored_filename, ta.file_path, ta.file_size, ta.mime_type,
+		        ta.uploaded_by, ta.has_thumbnail, ta.thumbnail_path, ta.created_at, ta.updated_at
+		 FROM task_attachments ta
+		 JOIN tasks t ON t.id = ta.task_id
+		 WHERE t.project_id = $1 AND t.deleted_at IS NULL
+		 ORDER BY ta.created_at`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.TaskAttachment
+	for rows.Next() {
+		var attachment models.TaskAttachment
+		if err := rows.Scan(&attachment.ID, &attachment.TaskID, &attachment.OriginalFilename, &attachment.StoredFilename, &attachment.FilePath, &attachment.FileSize, &attachment.MimeType, &attachment.UploadedBy, &attachment.HasThumbnail, &attachment.ThumbnailPath, &attachment.CreatedAt, &attachment.UpdatedAt); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
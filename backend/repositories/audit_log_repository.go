@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"project-management/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// auditLogLockKey serializes appends so every entry links to the one before it
+const auditLogLockKey = 7_211_002
+
+type AuditLogRepository interface {
+	Append(ctx context.Context, entry *models.AuditEntry) error
+	List(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]models.AuditEntry, int, error)
+	ListChain(ctx context.Context, afterID int64, limit int) ([]models.AuditEntry, error)
+}
+
+type auditLogRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditLogRepository(db *pgxpool.Pool) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Append links the entry to the latest one, seals it with its hash and inserts it
+func (r *auditLogRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", auditLogLockKey); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, "SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1").Scan(&entry.PrevHash)
+	if err == pgx.ErrNoRows {
+		entry.PrevHash = models.AuditGenesisHash
+	} else if err != nil {
+		return err
+	}
+	entry.Hash = entry.ComputeHash()
+
+	var details *string
+	if len(entry.Details) > 0 {
+		encoded, err := json.Marshal(entry.Details)
+		if err != nil {
+			return err
+		}
+		value := string(encoded)
+		details = &value
+	}
+
+	query := `
+INSERT INTO audit_log (occurred_at, event_type, outcome, actor_id, target_id, ip_address, user_agent, details, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id
+`
+	err = tx.QueryRow(ctx, query,
+		entry.OccurredAt, entry.EventType, entry.Outcome, entry.ActorID, entry.TargetID,
+		entry.IPAddress, entry.UserAgent, details, entry.PrevHash, entry.Hash,
+	).Scan(&entry.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// List returns matching entries newest first, with the emails of the users involved
+func (r *auditLogRepository) List(ctx context.Context, filter models.AuditFilter, limit, offset int) ([]models.AuditEntry, int, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 1
+
+	if filter.EventType != "" {
+		where += fmt.Sprintf(" AND a.event_type = $%d", argCount)
+		args = append(args, filter.EventType)
+		argCount++
+	}
+
+	if filter.Outcome != "" {
+		where += fmt.Sprintf(" AND a.outcome = $%d", argCount)
+		args = append(args, filter.Outcome)
+		argCount++
+	}
+
+	if filter.ActorID != nil {
+		where += fmt.Sprintf(" AND a.actor_id = $%d", argCount)
+		args = append(args, *filter.ActorID)
+		argCount++
+	}
+
+	if filter.TargetID != nil {
+		where += fmt.Sprintf(" AND a.target_id = $%d", argCount)
+		args = append(args, *filter.TargetID)
+		argCount++
+	}
+
+	if filter.From != nil {
+		where += fmt.Sprintf(" AND a.occurred_at >= $%d", argCount)
+		args = append(args, filter.From.UTC())
+		argCount++
+	}
+
+	if filter.To != nil {
+		where += fmt.Sprintf(" AND a.occurred_at < $%d", argCount)
+		args = append(args, filter.To.UTC())
+		argCount++
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM audit_log a"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+SELECT a.id, a.occurred_at, a.event_type, a.outcome, a.actor_id, a.target_id, a.ip_address, a.user_agent,
+       a.details, a.prev_hash, a.hash, actor.email, target.email
+FROM audit_log a
+LEFT JOIN users actor ON actor.id = a.actor_id
+LEFT JOIN users target ON target.id = a.target_id` + where
+
+	// A negative limit returns every match (CSV export)
+	if limit >= 0 {
+		query += fmt.Sprintf(" ORDER BY a.id DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+		args = append(args, limit, offset)
+	} else {
+		query += " ORDER BY a.id DESC"
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows, true)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, total, rows.Err()
+}
+
+// ListChain returns entries in chain order starting after the given id
+func (r *auditLogRepository) ListChain(ctx context.Context, afterID int64, limit int) ([]models.AuditEntry, error) {
+	query := `
+SELECT id, occurred_at, event_type, outcome, actor_id, target_id, ip_address, user_agent, details, prev_hash, hash
+FROM audit_log
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func scanAuditEntry(rows pgx.Rows, withEmails bool) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var ipAddress, userAgent, details *string
+
+	dest := []interface{}{
+		&entry.ID, &entry.OccurredAt, &entry.EventType, &entry.Outcome, &entry.ActorID, &entry.TargetID,
+		&ipAddress, &userAgent, &details, &entry.PrevHash, &entry.Hash,
+	}
+	if withEmails {
+		dest = append(dest, &entry.ActorEmail, &entry.TargetEmail)
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if ipAddress != nil {
+		entry.IPAddress = *ipAddress
+	}
+	if userAgent != nil {
+		entry.UserAgent = *userAgent
+	}
+	if details != nil {
+		if err := json.Unmarshal([]byte(*details), &entry.Details); err != nil {
+			return nil, err
+		}
+	}
+
+	return &entry, nil
+}
@@ -17,15 +17,16 @@ func NewDashboardRepository(db *pgxpool.Pool) *DashboardRepository {
 	return &DashboardRepository{db: db}
 }
 
-func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUID, userRole string) (models.DashboardStatistics, error) {
+// GetStatistics counts the dashboard figures; includeTeam adds the active user counts
+func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUID, userRole string, includeTeam bool) (models.DashboardStatistics, error) {
 	var stats models.DashboardStatistics
 
 	// 1. Active Projects
 	// Current
 	err := r.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM projects 
-		WHERE status IN ('active', 'In Progress', 'On Track')
-		AND (user_id = $1 OR created_by = $1 OR is_public = true OR $2 = 'admin')
+		WHERE status IN (SELECT name FROM project_statuses WHERE NOT is_closed) AND NOT is_template
+		AND project_role(id, $1, $2) IS NOT NULL
 	`, userID, userRole).Scan(&stats.ActiveProjects.Current)
 	if err != nil {
 		return stats, err
@@ -34,8 +35,8 @@ func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUI
 	// Previous (7 days ago)
 	err = r.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM projects 
-		WHERE status IN ('active', 'In Progress', 'On Track')
-		AND (user_id = $1 OR created_by = $1 OR is_public = true OR $2 = 'admin')
+		WHERE status IN (SELECT name FROM project_statuses WHERE NOT is_closed) AND NOT is_template
+		AND project_role(id, $1, $2) IS NOT NULL
 		AND (updated_at <= NOW() - INTERVAL '7 days' OR created_at <= NOW() - INTERVAL '7 days')
 	`, userID, userRole).Scan(&stats.ActiveProjects.Previous)
 	if err != nil {
@@ -47,8 +48,9 @@ func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUI
 	// Current
 	err = r.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM tasks 
-		WHERE completed = false
-		AND (assignee_id = $1 OR created_by = $1 OR $2 = 'admin')
+		WHERE completed = false AND deleted_at IS NULL
+		AND (assignee_id = $1 OR assignee_group_id IN (SELECT group_id FROM user_group_members WHERE user_id = $1) OR created_by = $1 OR role_has_permission($2, 'project.view_all'))
+		AND project_role(project_id, $1, $2) IS NOT NULL
 	`, userID, userRole).Scan(&stats.PendingTasks.Current)
 	if err != nil {
 		return stats, err
@@ -57,8 +59,9 @@ func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUI
 	// Previous (7 days ago)
 	err = r.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM tasks 
-		WHERE completed = false
-		AND (assignee_id = $1 OR created_by = $1 OR $2 = 'admin')
+		WHERE completed = false AND deleted_at IS NULL
+		AND (assignee_id = $1 OR assignee_group_id IN (SELECT group_id FROM user_group_members WHERE user_id = $1) OR created_by = $1 OR role_has_permission($2, 'project.view_all'))
+		AND project_role(project_id, $1, $2) IS NOT NULL
 		AND (updated_at <= NOW() - INTERVAL '7 days' OR created_at <= NOW() - INTERVAL '7 days')
 	`, userID, userRole).Scan(&stats.PendingTasks.Previous)
 	if err != nil {
@@ -66,8 +69,8 @@ func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUI
 	}
 	stats.PendingTasks.Change = stats.PendingTasks.Current - stats.PendingTasks.Previous
 
-	// 3. Team Members (Admin only)
-	if userRole == "admin" {
+	// 3. Team Members (user managers only)
+	if includeTeam {
 		err = r.db.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE is_active = true`).Scan(&stats.TeamMembers.Current)
 		if err != nil {
 			return stats, err
@@ -83,12 +86,13 @@ func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUI
 	err = r.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM (
 			SELECT id FROM tasks 
-			WHERE completed = false AND due_date BETWEEN CURRENT_DATE AND CURRENT_DATE + INTERVAL '7 days'
-			AND (assignee_id = $1 OR created_by = $1 OR $2 = 'admin')
+			WHERE completed = false AND deleted_at IS NULL AND due_date BETWEEN CURRENT_DATE AND CURRENT_DATE + INTERVAL '7 days'
+			AND (assignee_id = $1 OR assignee_group_id IN (SELECT group_id FROM user_group_members WHERE user_id = $1) OR created_by = $1 OR role_has_permission($2, 'project.view_all'))
+			AND project_role(project_id, $1, $2) IS NOT NULL
 			UNION ALL
 			SELECT id FROM projects 
-			WHERE status != 'Completed' AND due_date BETWEEN CURRENT_DATE AND CURRENT_DATE + INTERVAL '7 days'
-			AND (user_id = $1 OR created_by = $1 OR is_public = true OR $2 = 'admin')
+			WHERE status IN (SELECT name FROM project_statuses WHERE NOT is_closed) AND NOT is_template AND due_date BETWEEN CURRENT_DATE AND CURRENT_DATE + INTERVAL '7 days'
+			AND project_role(id, $1, $2) IS NOT NULL
 		) AS deadlines
 	`, userID, userRole).Scan(&stats.UpcomingDeadlines.Current)
 	if err != nil {
@@ -98,12 +102,13 @@ func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUI
 	err = r.db.QueryRow(ctx, `
 		SELECT COUNT(*) FROM (
 			SELECT id FROM tasks 
-			WHERE completed = false AND due_date BETWEEN CURRENT_DATE - INTERVAL '7 days' AND CURRENT_DATE
-			AND (assignee_id = $1 OR created_by = $1 OR $2 = 'admin')
+			WHERE completed = false AND deleted_at IS NULL AND due_date BETWEEN CURRENT_DATE - INTERVAL '7 days' AND CURRENT_DATE
+			AND (assignee_id = $1 OR assignee_group_id IN (SELECT group_id FROM user_group_members WHERE user_id = $1) OR created_by = $1 OR role_has_permission($2, 'project.view_all'))
+			AND project_role(project_id, $1, $2) IS NOT NULL
 			UNION ALL
 			SELECT id FROM projects 
-			WHERE status != 'Completed' AND due_date BETWEEN CURRENT_DATE - INTERVAL '7 days' AND CURRENT_DATE
-			AND (user_id = $1 OR created_by = $1 OR is_public = true OR $2 = 'admin')
+			WHERE status IN (SELECT name FROM project_statuses WHERE NOT is_closed) AND NOT is_template AND due_date BETWEEN CURRENT_DATE - INTERVAL '7 days' AND CURRENT_DATE
+			AND project_role(id, $1, $2) IS NOT NULL
 		) AS deadlines
 	`, userID, userRole).Scan(&stats.UpcomingDeadlines.Previous)
 	if err != nil {
@@ -115,17 +120,23 @@ func (r *DashboardRepository) GetStatistics(ctx context.Context, userID uuid.UUI
 }
 
 func (r *DashboardRepository) GetRecentProjects(ctx context.Context, userID uuid.UUID, userRole string, limit int) ([]models.ProjectCard, error) {
+	// Progress rolls up the tasks of all subprojects
 	rows, err := r.db.Query(ctx, `
 		SELECT 
 			p.id, p.title, p.status, p.updated_at,
 			COALESCE(p.due_date, (p.created_at + INTERVAL '30 days')::date) as due_date,
-			COUNT(t.id) as total_tasks,
-			COUNT(CASE WHEN t.completed = true THEN 1 END) as completed_tasks
+			t.total_tasks,
+			t.completed_tasks
 		FROM projects p
-		LEFT JOIN tasks t ON t.project_id = p.id
-		WHERE (p.user_id = $1 OR p.created_by = $1 OR p.is_public = true OR $2 = 'admin')
-		AND p.status IN ('active', 'Planning', 'In Progress', 'On Track', 'Review')
-		GROUP BY p.id, p.title, p.status, p.updated_at, p.due_date, p.created_at
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) as total_tasks,
+				COUNT(CASE WHEN completed = true THEN 1 END) as completed_tasks
+			FROM tasks
+			WHERE project_id IN (SELECT project_subtree(p.id)) AND deleted_at IS NULL
+		) t
+		WHERE project_role(p.id, $1, $2) IS NOT NULL
+		AND p.status IN (SELECT name FROM project_statuses WHERE NOT is_closed) AND NOT p.is_template
 		ORDER BY p.updated_at DESC
 		LIMIT $3
 	`, userID, userRole, limit)
@@ -169,21 +180,19 @@ func (r *DashboardRepository) getProjectTeamMembers(ctx context.Context, project
 	// Total count
 	var total int
 	err := r.db.QueryRow(ctx, `
-		SELECT COUNT(DISTINCT u.id)
-		FROM users u
-		JOIN tasks t ON t.assignee_id = u.id
-		WHERE t.project_id = $1
+		SELECT COUNT(*) FROM project_members WHERE project_id = $1
 	`, projectID).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Top 3
+	// Top 3, managers first
 	rows, err := r.db.Query(ctx, `
-		SELECT DISTINCT u.id, u.username, u.email, u.role, u.is_active, u.created_at, u.updated_at
+		SELECT u.id, u.username, u.email, u.role, u.is_active, u.created_at, u.updated_at
 		FROM users u
-		JOIN tasks t ON t.assignee_id = u.id
-		WHERE t.project_id = $1
+		JOIN project_members m ON m.user_id = u.id
+		WHERE m.project_id = $1
+		ORDER BY (m.role = 'manager') DESC, m.created_at
 		LIMIT 3
 	`, projectID)
 	if err != nil {
@@ -203,13 +212,15 @@ func (r *DashboardRepository) getProjectTeamMembers(ctx context.Context, project
 	return members, total, nil
 }
 
-func (r *DashboardRepository) GetUserTasks(ctx context.Context, userID uuid.UUID, limit int) ([]models.TaskSummary, error) {
+func (r *DashboardRepository) GetUserTasks(ctx context.Context, userID uuid.UUID, userRole string, limit int) ([]models.TaskSummary, error) {
 	rows, err := r.db.Query(ctx, `
 		SELECT t.id, t.title, p.title as project_name, t.project_id, t.priority, COALESCE(t.due_date, (t.created_at + INTERVAL '7 days')::date), t.completed
 		FROM tasks t
 		JOIN projects p ON t.project_id = p.id
-		WHERE (t.assignee_id = $1 OR t.created_by = $1)
-		AND t.completed = false
+		WHERE (t.assignee_id = $1 OR t.created_by = $1
+			OR t.assignee_group_id IN (SELECT group_id FROM user_group_members WHERE user_id = $1))
+		AND project_role(t.project_id, $1, $2) IS NOT NULL
+		AND t.completed = false AND t.deleted_at IS NULL
 		ORDER BY 
 			CASE 
 				WHEN t.priority = 'Critical' THEN 4
@@ -219,8 +230,8 @@ func (r *DashboardRepository) GetUserTasks(ctx context.Context, userID uuid.UUID
 				ELSE 0
 			END DESC,
 			t.due_date ASC NULLS LAST
-		LIMIT $2
-	`, userID, limit)
+		LIMIT $3
+	`, userID, userRole, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"project-management/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, token *models.EmailVerificationToken) error
+	GetByToken(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error)
+	MarkAsUsed(ctx context.Context, tokenHash string) error
+	InvalidateForUser(ctx context.Context, userID uuid.UUID) error
+	CountCreatedSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error)
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+type emailVerificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailVerificationRepository(db *pgxpool.Pool) EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+func (r *emailVerificationRepository) Create(ctx context.Context, token *models.EmailVerificationToken) error {
+	query := "INSERT INTO email_verification_tokens (id, user_id, email, token_hash, created_at, expires_at, used) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at"
+	token.ID = uuid.New()
+	token.CreatedAt = time.Now()
+	return r.db.QueryRow(ctx, query, token.ID, token.UserID, token.Email, token.TokenHash, token.CreatedAt, token.ExpiresAt, token.Used).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *emailVerificationRepository) GetByToken(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	query := "SELECT id, user_id, email, token_hash, created_at, expires_at, used FROM email_verification_tokens WHERE token_hash = $1"
+	token := &models.EmailVerificationToken{}
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.Email, &token.TokenHash, &token.CreatedAt, &token.ExpiresAt, &token.Used)
+	return token, err
+}
+
+func (r *emailVerificationRepository) MarkAsUsed(ctx context.Context, tokenHash string) error {
+	query := "UPDATE email_verification_tokens SET used = true WHERE token_hash = $1"
+	_, err := r.db.Exec(ctx, query, tokenHash)
+	return err
+}
+
+// InvalidateForUser voids all outstanding tokens, e.g. when a new one is sent
+func (r *emailVerificationRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID) error {
+	query := "UPDATE email_verification_tokens SET used = true WHERE user_id = $1 AND used = false"
+	_, err := r.db.Exec(ctx, query, userID)
+	return err
+}
+
+// CountCreatedSince counts tokens issued to the user since the given time (used for resend limits)
+func (r *emailVerificationRepository) CountCreatedSince(ctx context.Context, userID uuid.UUID, since time.Time) (int, error) {
+	query := "SELECT COUNT(*) FROM email_verification_tokens WHERE user_id = $1 AND created_at >= $2"
+
+	var count int
+	err := r.db.QueryRow(ctx, query, userID, since).Scan(&count)
+	return count, err
+}
+
+func (r *emailVerificationRepository) DeleteExpired(ctx context.Context) (int, error) {
+	query := "DELETE FROM email_verification_tokens WHERE expires_at < $1"
+	result, err := r.db.Exec(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrGroupNameTaken is returned when another group already uses the name
+var ErrGroupNameTaken = errors.New("group name is taken")
+
+type GroupRepository interface {
+	List(ctx context.Context) ([]models.Group, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error)
+	Create(ctx context.Context, group *models.Group) error
+	Update(ctx context.Context, id uuid.UUID, name, description string) (*models.Group, error)
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
+	ListMembers(ctx context.Context, groupID uuid.UUID) ([]models.GroupMember, error)
+	AddMember(ctx context.Context, groupID, userID uuid.UUID) (bool, error)
+	RemoveMember(ctx context.Context, groupID, userID uuid.UUID) (bool, error)
+	GetNotifiableMembers(ctx context.Context, groupID uuid.UUID) ([]models.User, error)
+}
+
+type groupRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewGroupRepository(db *pgxpool.Pool) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+const groupSelectColumns = `g.id, g.name, g.description,
+	(SELECT COUNT(*) FROM user_group_members gm WHERE gm.group_id = g.id), g.created_at, g.updated_at`
+
+func scanGroup(row pgx.Row) (*models.Group, error) {
+	var group models.Group
+	err := row.Scan(&group.ID, &group.Name, &group.Description, &group.MemberCount, &group.CreatedAt, &group.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (r *groupRepository) List(ctx context.Context) ([]models.Group, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+groupSelectColumns+" FROM user_groups g ORDER BY g.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, *group)
+	}
+	return groups, rows.Err()
+}
+
+func (r *groupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	return scanGroup(r.db.QueryRow(ctx, "SELECT "+groupSelectColumns+" FROM user_groups g WHERE g.id = $1", id))
+}
+
+func (r *groupRepository) Create(ctx context.Context, group *models.Group) error {
+	err := r.db.QueryRow(ctx,
+		"INSERT INTO user_groups (name, description) VALUES ($1, $2) RETURNING id, created_at, updated_at",
+		group.Name, group.Description).Scan(&group.ID, &group.CreatedAt, &group.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrGroupNameTaken
+	}
+	return err
+}
+
+// Update renames a group and replaces its description; nil means it does not exist
+func (r *groupRepository) Update(ctx context.Context, id uuid.UUID, name, description string) (*models.Group, error) {
+	result, err := r.db.Exec(ctx,
+		"UPDATE user_groups SET name = $2, description = $3, updated_at = NOW() WHERE id = $1",
+		id, name, description)
+	if isUniqueViolation(err) {
+		return nil, ErrGroupNameTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, nil
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes a group; its project memberships go with it and its tasks become unassigned
+func (r *groupRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM user_groups WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *groupRepository) ListMembers(ctx context.Context, groupID uuid.UUID) ([]models.GroupMember, error) {
+	rows, err := r.db.Query(ctx, `
+SELECT gm.group_id, gm.user_id, u.username, u.email, gm.created_at
+FROM user_group_members gm
+JOIN users u ON u.id = gm.user_id
+WHERE gm.group_id = $1
+ORDER BY u.username`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.GroupMember{}
+	for rows.Next() {
+		var m models.GroupMember
+		if err := rows.Scan(&m.GroupID, &m.UserID, &m.Username, &m.Email, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddMember reports false when the user already belongs to the group
+func (r *groupRepository) AddMember(ctx context.Context, groupID, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		"INSERT INTO user_group_members (group_id, user_id) VALUES ($1, $2) ON CONFLICT (group_id, user_id) DO NOTHING",
+		groupID, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *groupRepository) RemoveMember(ctx context.Context, groupID, userID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM user_group_members WHERE group_id = $1 AND user_id = $2", groupID, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// GetNotifiableMembers returns the group's active members with a verified email address and their system role
+func (r *groupRepository) GetNotifiableMembers(ctx context.Context, groupID uuid.UUID) ([]models.User, error) {
+	rows, err := r.db.Query(ctx, `
+SELECT u.id, u.username, u.email, u.role
+FROM user_group_members gm
+JOIN users u ON u.id = gm.user_id
+WHERE gm.group_id = $1 AND u.is_active AND u.email_verified_at IS NOT NULL`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.Role); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
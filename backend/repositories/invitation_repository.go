@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *models.UserInvitation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.UserInvitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.UserInvitation, error)
+	List(ctx context.Context) ([]models.UserInvitation, error)
+	HasPending(ctx context.Context, email string) (bool, error)
+	Reissue(ctx context.Context, id uuid.UUID, tokenHash string, expiresAt time.Time) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID) (bool, error)
+	Accept(ctx context.Context, invitationID uuid.UUID, user *models.User) (bool, error)
+}
+
+type invitationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewInvitationRepository(db *pgxpool.Pool) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+const invitationSelectColumns = `i.id, i.email, i.role, i.project_ids, i.token_hash, i.invited_by, u.username,
+       i.expires_at, i.created_at, i.accepted_at, i.accepted_user_id, i.revoked_at`
+
+func scanInvitation(row pgx.Row) (*models.UserInvitation, error) {
+	invitation := &models.UserInvitation{}
+	err := row.Scan(
+		&invitation.ID, &invitation.Email, &invitation.Role, &invitation.ProjectIDs, &invitation.TokenHash,
+		&invitation.InvitedBy, &invitation.InvitedByName,
+		&invitation.ExpiresAt, &invitation.CreatedAt, &invitation.AcceptedAt, &invitation.AcceptedUserID, &invitation.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *models.UserInvitation) error {
+	query := `
+INSERT INTO user_invitations (id, email, role, project_ids, token_hash, invited_by, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at
+`
+
+	invitation.ID = uuid.New()
+	invitation.CreatedAt = time.Now()
+
+	var projectIDs []uuid.UUID
+	if len(invitation.ProjectIDs) > 0 {
+		projectIDs = invitation.ProjectIDs
+	}
+
+	return r.db.QueryRow(ctx, query,
+		invitation.ID, invitation.Email, invitation.Role, projectIDs, invitation.TokenHash,
+		invitation.InvitedBy, invitation.ExpiresAt, invitation.CreatedAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+}
+
+func (r *invitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.UserInvitation, error) {
+	query := "SELECT " + invitationSelectColumns + " FROM user_invitations i LEFT JOIN users u ON u.id = i.invited_by WHERE i.id = $1"
+	return scanInvitation(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.UserInvitation, error) {
+	query := "SELECT " + invitationSelectColumns + " FROM user_invitations i LEFT JOIN users u ON u.id = i.invited_by WHERE i.token_hash = $1"
+	return scanInvitation(r.db.QueryRow(ctx, query, tokenHash))
+}
+
+// List returns all invitations, newest first
+func (r *invitationRepository) List(ctx context.Context) ([]models.UserInvitation, error) {
+	query := "SELECT " + invitationSelectColumns + " FROM user_invitations i LEFT JOIN users u ON u.id = i.invited_by ORDER BY i.created_at DESC"
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []models.UserInvitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// HasPending reports whether the email has an unexpired invitation that is neither accepted nor revoked
+func (r *invitationRepository) HasPending(ctx context.Context, email string) (bool, error) {
+	query := `
+SELECT EXISTS (
+    SELECT 1 FROM user_invitations
+    WHERE LOWER(email) = LOWER($1) AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+)
+`
+	var exists bool
+	err := r.db.QueryRow(ctx, query, email, time.Now()).Scan(&exists)
+	return exists, err
+}
+
+// Reissue replaces the token of an open invitation, invalidating the previously emailed link
+func (r *invitationRepository) Reissue(ctx context.Context, id uuid.UUID, tokenHash string, expiresAt time.Time) (bool, error) {
+	query := "UPDATE user_invitations SET token_hash = $1, expires_at = $2 WHERE id = $3 AND accepted_at IS NULL AND revoked_at IS NULL"
+	result, err := r.db.Exec(ctx, query, tokenHash, expiresAt, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Revoke revokes an open invitation. Returns false if it was already accepted or revoked.
+func (r *invitationRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := "UPDATE user_invitations SET revoked_at = $1 WHERE id = $2 AND accepted_at IS NULL AND revoked_at IS NULL"
+	result, err := r.db.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Accept atomically consumes the invitation, creates the user and adds the project memberships.
+// Returns false if the invitation is no longer open (accepted, revoked or expired in the meantime).
+func (r *invitationRepository) Accept(ctx context.Context, invitationID uuid.UUID, user *models.User) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	user.ID = uuid.New()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	if user.AuthProvider == "" {
+		user.AuthProvider = models.AuthProviderLocal
+	}
+
+	_, err = tx.Exec(ctx,
+		"INSERT INTO users (id, username, email, email_verified_at, password_hash, role, auth_provider, is_active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+		user.ID, user.Username, user.Email, user.EmailVerifiedAt, user.PasswordHash, user.Role, user.AuthProvider, user.IsActive, user.CreatedAt, user.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(ctx, `
+UPDATE user_invitations SET accepted_at = $1, accepted_user_id = $2
+WHERE id = $3 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $1
+`, now, user.ID, invitationID)
+	if err != nil {
+		return false, err
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	// Projects deleted since the invitation was sent are skipped
+	_, err = tx.Exec(ctx, `
+INSERT INTO project_members (project_id, user_id, created_at)
+SELECT p.id, $1, $2
+FROM user_invitations i
+JOIN projects p ON p.id = ANY(i.project_ids)
+WHERE i.id = $3
+ON CONFLICT DO NOTHING
+`, user.ID, now, invitationID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PasswordHistoryRepository interface {
+	ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error)
+	Record(ctx context.Context, userID uuid.UUID, passwordHash string, keep int) error
+}
+
+type passwordHistoryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordHistoryRepository(db *pgxpool.Pool) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+// ListRecent returns the user's most recent previous password hashes, newest first
+func (r *passwordHistoryRepository) ListRecent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error) {
+	query := "SELECT password_hash FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2"
+
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+// Record stores a replaced password hash and keeps only the newest entries
+func (r *passwordHistoryRepository) Record(ctx context.Context, userID uuid.UUID, passwordHash string, keep int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		"INSERT INTO password_history (id, user_id, password_hash, created_at) VALUES ($1, $2, $3, $4)",
+		uuid.New(), userID, passwordHash, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+DELETE FROM password_history
+WHERE user_id = $1 AND id NOT IN (
+    SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2
+)
+`, userID, keep)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
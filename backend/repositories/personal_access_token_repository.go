@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, tokenID uuid.UUID) (bool, error)
+	TouchLastUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error
+	GetResourceProjectID(ctx context.Context, resource string, id uuid.UUID) (uuid.UUID, error)
+}
+
+type personalAccessTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPersonalAccessTokenRepository(db *pgxpool.Pool) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db: db}
+}
+
+// lastUsedResolution limits last_used_at writes to one per token per minute
+const lastUsedResolution = time.Minute
+
+// resourceProjectQueries maps API resource names to a query resolving their owning project
+var resourceProjectQueries = map[string]string{
+	"projects":    "SELECT id FROM projects WHERE id = $1",
+	"tasks":       "SELECT project_id FROM tasks WHERE id = $1",
+	"timelogs":    "SELECT t.project_id FROM time_logs tl JOIN tasks t ON t.id = tl.task_id WHERE tl.id = $1",
+	"comments":    "SELECT t.project_id FROM comments c JOIN tasks t ON t.id = c.task_id WHERE c.id = $1",
+	"attachments": "SELECT t.project_id FROM task_attachments a JOIN tasks t ON t.id = a.task_id WHERE a.id = $1",
+}
+
+// ErrUnknownResource is returned when a resource has no owning project
+var ErrUnknownResource = errors.New("unknown resource")
+
+func (r *personalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	query := `
+INSERT INTO personal_access_tokens (id, user_id, name, token_hash, token_prefix, scope, project_ids, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at
+`
+
+	token.ID = uuid.New()
+	token.CreatedAt = time.Now()
+
+	var projectIDs []uuid.UUID
+	if len(token.ProjectIDs) > 0 {
+		projectIDs = token.ProjectIDs
+	}
+
+	return r.db.QueryRow(ctx, query,
+		token.ID, token.UserID, token.Name, token.TokenHash, token.TokenPrefix,
+		token.Scope, projectIDs, token.ExpiresAt, token.CreatedAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetByTokenHash loads a token together with the state of its owner
+func (r *personalAccessTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	query := `
+SELECT t.id, t.user_id, t.name, t.token_hash, t.token_prefix, t.scope, t.project_ids,
+       t.expires_at, t.last_used_at, t.created_at, t.revoked_at, u.role, u.is_active
+FROM personal_access_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.token_hash = $1
+`
+
+	token := &models.PersonalAccessToken{}
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenHash, &token.TokenPrefix, &token.Scope, &token.ProjectIDs,
+		&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt, &token.RevokedAt, &token.OwnerRole, &token.OwnerIsActive,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// ListByUser returns the user's tokens that have not been revoked, newest first
+func (r *personalAccessTokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	query := `
+SELECT id, user_id, name, token_prefix, scope, project_ids, expires_at, last_used_at, created_at
+FROM personal_access_tokens
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC
+`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.PersonalAccessToken{}
+	for rows.Next() {
+		var token models.PersonalAccessToken
+		if err := rows.Scan(
+			&token.ID, &token.UserID, &token.Name, &token.TokenPrefix, &token.Scope, &token.ProjectIDs,
+			&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Revoke revokes one of the user's tokens. Returns false if no such active token exists.
+func (r *personalAccessTokenRepository) Revoke(ctx context.Context, userID, tokenID uuid.UUID) (bool, error) {
+	query := "UPDATE personal_access_tokens SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL"
+	result, err := r.db.Exec(ctx, query, time.Now(), tokenID, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// TouchLastUsed records token usage, skipping the write if it was recorded recently
+func (r *personalAccessTokenRepository) TouchLastUsed(ctx context.Context, tokenID uuid.UUID, usedAt time.Time) error {
+	query := `
+UPDATE personal_access_tokens SET last_used_at = $1
+WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+`
+	_, err := r.db.Exec(ctx, query, usedAt, tokenID, usedAt.Add(-lastUsedResolution))
+	return err
+}
+
+// GetResourceProjectID resolves the project owning a resource addressed as /api/{resource}/{id}
+func (r *personalAccessTokenRepository) GetResourceProjectID(ctx context.Context, resource string, id uuid.UUID) (uuid.UUID, error) {
+	query, ok := resourceProjectQueries[resource]
+	if !ok {
+		return uuid.Nil, ErrUnknownResource
+	}
+
+	var projectID uuid.UUID
+	err := r.db.QueryRow(ctx, query, id).Scan(&projectID)
+	return projectID, err
+}
@@ -0,0 +1,250 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrLastProjectManager is returned when a change would leave a project without a manager
+var ErrLastProjectManager = errors.New("project must keep at least one manager")
+
+type ProjectMemberRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProjectMemberRepository(db *pgxpool.Pool) *ProjectMemberRepository {
+	return &ProjectMemberRepository{db: db}
+}
+
+// GetProjectRole returns the user's effective role in the project ("" when it is hidden from them)
+// and whether the project exists. Every project-scoped access check goes through project_role().
+func (r *ProjectMemberRepository) GetProjectRole(ctx context.Context, projectID, userID uuid.UUID, systemRole string) (string, bool, error) {
+	var role *string
+	err := r.db.QueryRow(ctx, "SELECT project_role(id, $2, $3) FROM projects WHERE id = $1", projectID, userID, systemRole).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if role == nil {
+		return "", true, nil
+	}
+	return *role, true, nil
+}
+
+// GetDeletedProjectRole is GetProjectRole that also sees projects in the trash
+func (r *ProjectMemberRepository) GetDeletedProjectRole(ctx context.Context, projectID, userID uuid.UUID, systemRole string) (string, bool, error) {
+	var role *string
+	err := r.db.QueryRow(ctx, "SELECT project_role_including_deleted(id, $2, $3) FROM projects WHERE id = $1", projectID, userID, systemRole).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if role == nil {
+		return "", true, nil
+	}
+	return *role, true, nil
+}
+
+// GetTaskProjectRole is GetProjectRole for the project a task belongs to
+func (r *ProjectMemberRepository) GetTaskProjectRole(ctx context.Context, taskID, userID uuid.UUID, systemRole string) (string, bool, error) {
+	var role *string
+	err := r.db.QueryRow(ctx, "SELECT project_role(project_id, $2, $3) FROM tasks WHERE id = $1 AND deleted_at IS NULL", taskID, userID, systemRole).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if role == nil {
+		return "", true, nil
+	}
+	return *role, true, nil
+}
+
+func (r *ProjectMemberRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.ProjectMember, error) {
+	query := `
+SELECT m.project_id, m.user_id, u.username, u.email, m.role, m.created_at
+FROM project_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.project_id = $1
+ORDER BY CASE m.role WHEN 'manager' THEN 1 WHEN 'developer' THEN 2 WHEN 'reporter' THEN 3 ELSE 4 END, u.username
+`
+	rows, err := r.db.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.ProjectMember{}
+	for rows.Next() {
+		var m models.ProjectMember
+		if err := rows.Scan(&m.ProjectID, &m.UserID, &m.Username, &m.Email, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *ProjectMemberRepository) Get(ctx context.Context, projectID, userID uuid.UUID) (*models.ProjectMember, error) {
+	query := `
+SELECT m.project_id, m.user_id, u.username, u.email, m.role, m.created_at
+FROM project_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.project_id = $1 AND m.user_id = $2
+`
+	var m models.ProjectMember
+	err := r.db.QueryRow(ctx, query, projectID, userID).Scan(&m.ProjectID, &m.UserID, &m.Username, &m.Email, &m.Role, &m.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Add inserts a membership and reports false when the user is already a member
+func (r *ProjectMemberRepository) Add(ctx context.Context, projectID, userID uuid.UUID, role string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		"INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (project_id, user_id) DO NOTHING",
+		projectID, userID, role)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// UpdateRole changes a member's role, refusing to demote the last manager
+func (r *ProjectMemberRepository) UpdateRole(ctx context.Context, projectID, userID uuid.UUID, role string) (bool, error) {
+	return r.changeMembership(ctx, projectID, userID, role != models.ProjectRoleManager,
+		"UPDATE project_members SET role = $3 WHERE project_id = $1 AND user_id = $2", role)
+}
+
+// Remove deletes a membership, refusing to remove the last manager
+func (r *ProjectMemberRepository) Remove(ctx context.Context, projectID, userID uuid.UUID) (bool, error) {
+	return r.changeMembership(ctx, projectID, userID, true,
+		"DELETE FROM project_members WHERE project_id = $1 AND user_id = $2")
+}
+
+// changeMembership runs the statement while holding the project row, so concurrent changes
+// cannot both remove the remaining managers
+func (r *ProjectMemberRepository) changeMembership(ctx context.Context, projectID, userID uuid.UUID, dropsManager bool, statement string, args ...interface{}) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT id FROM projects WHERE id = $1 FOR UPDATE", projectID); err != nil {
+		return false, err
+	}
+
+	if dropsManager {
+		var isManager bool
+		var otherManagers int
+		err := tx.QueryRow(ctx, `
+SELECT COALESCE(bool_or(user_id = $2), false), COUNT(*) FILTER (WHERE user_id <> $2)
+FROM project_members
+WHERE project_id = $1 AND role = 'manager'
+`, projectID, userID).Scan(&isManager, &otherManagers)
+		if err != nil {
+			return false, err
+		}
+		if isManager && otherManagers == 0 {
+			return false, ErrLastProjectManager
+		}
+	}
+
+	result, err := tx.Exec(ctx, statement, append([]interface{}{projectID, userID}, args...)...)
+	if err != nil {
+		return false, err
+	}
+	if result.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+const projectGroupQuery = `
+SELECT pg.project_id, pg.group_id, g.name,
+       (SELECT COUNT(*) FROM user_group_members gm WHERE gm.group_id = g.id), pg.role, pg.created_at
+FROM project_group_members pg
+JOIN user_groups g ON g.id = pg.group_id
+`
+
+// GetGroupsByProjectID lists the groups that are members of the project
+func (r *ProjectMemberRepository) GetGroupsByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.ProjectGroup, error) {
+	rows, err := r.db.Query(ctx, projectGroupQuery+"WHERE pg.project_id = $1 ORDER BY g.name", projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.ProjectGroup{}
+	for rows.Next() {
+		var g models.ProjectGroup
+		if err := rows.Scan(&g.ProjectID, &g.GroupID, &g.Name, &g.MemberCount, &g.Role, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+func (r *ProjectMemberRepository) GetGroup(ctx context.Context, projectID, groupID uuid.UUID) (*models.ProjectGroup, error) {
+	var g models.ProjectGroup
+	err := r.db.QueryRow(ctx, projectGroupQuery+"WHERE pg.project_id = $1 AND pg.group_id = $2", projectID, groupID).
+		Scan(&g.ProjectID, &g.GroupID, &g.Name, &g.MemberCount, &g.Role, &g.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// AddGroup gives every member of the group a role in the project; false means it already has one
+func (r *ProjectMemberRepository) AddGroup(ctx context.Context, projectID, groupID uuid.UUID, role string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		"INSERT INTO project_group_members (project_id, group_id, role) VALUES ($1, $2, $3) ON CONFLICT (project_id, group_id) DO NOTHING",
+		projectID, groupID, role)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *ProjectMemberRepository) UpdateGroupRole(ctx context.Context, projectID, groupID uuid.UUID, role string) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		"UPDATE project_group_members SET role = $3 WHERE project_id = $1 AND group_id = $2",
+		projectID, groupID, role)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *ProjectMemberRepository) RemoveGroup(ctx context.Context, projectID, groupID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM project_group_members WHERE project_id = $1 AND group_id = $2", projectID, groupID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
@@ -2,13 +2,20 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"project-management/models"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrProjectIdentifierTaken is returned when another project already uses the identifier
+var ErrProjectIdentifierTaken = errors.New("identifier is already in use")
+
 type ProjectRepository struct {
 	db *pgxpool.Pool
 }
@@ -18,7 +25,7 @@ func NewProjectRepository(db *pgxpool.Pool) *ProjectRepository {
 }
 
 func (r *ProjectRepository) GetAll(ctx context.Context) ([]models.Project, error) {
-	rows, err := r.db.Query(ctx, "SELECT id, title, description, status, identifier, homepage, is_public, user_id, created_by, created_at, updated_at FROM projects ORDER BY created_at DESC")
+	rows, err := r.db.Query(ctx, "SELECT id, title, description, status, identifier, homepage, is_public, is_template, parent_id, user_id, created_by, created_at, updated_at FROM projects WHERE deleted_at IS NULL ORDER BY created_at DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +34,7 @@ func (r *ProjectRepository) GetAll(ctx context.Context) ([]models.Project, error
 	var projects []models.Project
 	for rows.Next() {
 		var p models.Project
-		if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.UserID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.IsTemplate, &p.ParentID, &p.UserID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
 			return nil, err
 		}
 		projects = append(projects, p)
@@ -38,8 +45,8 @@ func (r *ProjectRepository) GetAll(ctx context.Context) ([]models.Project, error
 
 func (r *ProjectRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
 	var p models.Project
-	err := r.db.QueryRow(ctx, "SELECT id, title, description, status, identifier, homepage, is_public, user_id, created_by, created_at, updated_at FROM projects WHERE id = $1", id).
-		Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.UserID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
+	err := r.db.QueryRow(ctx, "SELECT id, title, description, status, identifier, homepage, is_public, is_template, parent_id, user_id, created_by, created_at, updated_at FROM projects WHERE id = $1 AND deleted_at IS NULL", id).
+		Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.IsTemplate, &p.ParentID, &p.UserID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -55,11 +62,22 @@ func (r *ProjectRepository) Create(ctx context.Context, req models.CreateProject
 	id := uuid.New()
 	var p models.Project
 
-	err := r.db.QueryRow(ctx,
-		"INSERT INTO projects (id, title, description, status, identifier, homepage, is_public, created_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, title, description, status, identifier, homepage, is_public, user_id, created_by, created_at, updated_at",
-		id, req.Title, req.Description, req.Status, req.Identifier, req.Homepage, req.IsPublic, createdBy).
-		Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.UserID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
+	// The creator becomes the project's first manager
+	err := r.db.QueryRow(ctx, `
+WITH p AS (
+    INSERT INTO projects (id, title, description, status, identifier, homepage, is_public, is_template, parent_id, created_by) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    RETURNING id, title, description, status, identifier, homepage, is_public, is_template, parent_id, user_id, created_by, created_at, updated_at
+), m AS (
+    INSERT INTO project_members (project_id, user_id, role)
+    SELECT id, created_by, 'manager' FROM p WHERE created_by IS NOT NULL
+)
+SELECT * FROM p`,
+		id, req.Title, req.Description, req.Status, req.Identifier, req.Homepage, req.IsPublic, req.IsTemplate, req.ParentID, createdBy).
+		Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.IsTemplate, &p.ParentID, &p.UserID, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt)
 
+	if isUniqueViolation(err) {
+		return nil, ErrProjectIdentifierTaken
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -71,10 +89,13 @@ func (r *ProjectRepository) Update(ctx context.Context, id uuid.UUID, req models
 	var p models.Project
 
 	err := r.db.QueryRow(ctx,
-		"UPDATE projects SET title = $1, description = $2, status = $3, identifier = $4, homepage = $5, is_public = $6 WHERE id = $7 RETURNING id, title, description, status, identifier, homepage, is_public, created_at, updated_at",
-		req.Title, req.Description, req.Status, req.Identifier, req.Homepage, req.IsPublic, id).
-		Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.CreatedAt, &p.UpdatedAt)
+		"UPDATE projects SET title = $1, description = $2, status = $3, identifier = $4, homepage = $5, is_public = $6, is_template = $7, parent_id = $8 WHERE id = $9 AND deleted_at IS NULL RETURNING id, title, description, status, identifier, homepage, is_public, is_template, parent_id, created_at, updated_at",
+		req.Title, req.Description, req.Status, req.Identifier, req.Homepage, req.IsPublic, req.IsTemplate != nil && *req.IsTemplate, req.ParentID, id).
+		Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.IsTemplate, &p.ParentID, &p.CreatedAt, &p.UpdatedAt)
 
+	if isUniqueViolation(err) {
+		return nil, ErrProjectIdentifierTaken
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +103,303 @@ func (r *ProjectRepository) Update(ctx context.Context, id uuid.UUID, req models
 	return &p, nil
 }
 
-func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.Exec(ctx, "DELETE FROM projects WHERE id = $1", id)
+// Delete moves the project to the trash; its tasks stay with it
+func (r *ProjectRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "UPDATE projects SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL", id, deletedBy)
+	return err
+}
+
+// DeleteTree moves the project and all of its subprojects to the trash. They share one deleted_at,
+// which is how restoring the project brings the subprojects back with it.
+func (r *ProjectRepository) DeleteTree(ctx context.Context, id, deletedBy uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "UPDATE projects SET deleted_at = NOW(), deleted_by = $2 WHERE id IN (SELECT project_subtree($1)) AND deleted_at IS NULL", id, deletedBy)
 	return err
 }
+
+// GetDescendantIDs returns the IDs of all subprojects at any depth, excluding the project itself
+func (r *ProjectRepository) GetDescendantIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, "SELECT s FROM project_subtree($1) s WHERE s <> $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var descendantID uuid.UUID
+		if err := rows.Scan(&descendantID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, descendantID)
+	}
+
+	return ids, rows.Err()
+}
+
+// projectSortKeys maps sortable fields to their SQL expression and the type their text form casts back to
+var projectSortKeys = map[string]struct{ expr, cast string }{
+	"created_at": {"p.created_at", "timestamptz"},
+	"updated_at": {"p.updated_at", "timestamptz"},
+	"title":      {"p.title", "text"},
+	"identifier": {"COALESCE(p.identifier, '')", "text"},
+	// Projects without a due date sort after every dated one
+	"due_date": {"COALESCE(p.due_date, 'infinity'::date)", "date"},
+}
+
+// projectOpenFirstOrder lists projects in open statuses before closed ones
+const projectOpenFirstOrder = "COALESCE((SELECT s.is_closed FROM project_statuses s WHERE s.name = p.status), false)"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// List returns the projects the user can see that match the filter. Pages continue after filter.After
+// by keyset on (sort key, id), led by the closed flag for open-first listings; the returned cursor
+// is set when more projects follow.
+func (r *ProjectRepository) List(ctx context.Context, userID uuid.UUID, role string, filter models.ProjectFilter) ([]models.Project, *models.ProjectCursor, error) {
+	sortKey, ok := projectSortKeys[filter.Sort]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown project sort field %q", filter.Sort)
+	}
+
+	where := " WHERE project_role(p.id, $1, $2) IS NOT NULL AND p.is_template = $3"
+	args := []interface{}{userID, role, filter.Templates}
+	argCount := 4
+
+	if len(filter.ProjectIDs) > 0 {
+		where += fmt.Sprintf(" AND p.id = ANY($%d)", argCount)
+		args = append(args, filter.ProjectIDs)
+		argCount++
+	}
+
+	if len(filter.Statuses) > 0 {
+		where += fmt.Sprintf(" AND p.status = ANY($%d)", argCount)
+		args = append(args, filter.Statuses)
+		argCount++
+	}
+
+	if filter.Closed != nil {
+		where += fmt.Sprintf(" AND "+projectOpenFirstOrder+" = $%d", argCount)
+		args = append(args, *filter.Closed)
+		argCount++
+	}
+
+	if filter.OwnerID != nil {
+		where += fmt.Sprintf(" AND p.created_by = $%d", argCount)
+		args = append(args, *filter.OwnerID)
+		argCount++
+	}
+
+	if filter.IsPublic != nil {
+		where += fmt.Sprintf(" AND p.is_public = $%d", argCount)
+		args = append(args, *filter.IsPublic)
+		argCount++
+	}
+
+	if filter.CreatedFrom != nil {
+		where += fmt.Sprintf(" AND p.created_at >= $%d", argCount)
+		args = append(args, filter.CreatedFrom.UTC())
+		argCount++
+	}
+
+	if filter.CreatedTo != nil {
+		where += fmt.Sprintf(" AND p.created_at < $%d", argCount)
+		args = append(args, filter.CreatedTo.UTC())
+		argCount++
+	}
+
+	// Due dates are plain dates; both bounds are inclusive
+	if filter.DueFrom != nil {
+		where += fmt.Sprintf(" AND p.due_date >= $%d::date", argCount)
+		args = append(args, filter.DueFrom.Format("2006-01-02"))
+		argCount++
+	}
+
+	if filter.DueTo != nil {
+		where += fmt.Sprintf(" AND p.due_date <= $%d::date", argCount)
+		args = append(args, filter.DueTo.Format("2006-01-02"))
+		argCount++
+	}
+
+	if filter.Query != "" {
+		where += fmt.Sprintf(" AND (p.title ILIKE $%d OR p.identifier ILIKE $%d OR p.description ILIKE $%d)", argCount, argCount, argCount)
+		args = append(args, "%"+likeEscaper.Replace(filter.Query)+"%")
+		argCount++
+	}
+
+	direction, after := "ASC", ">"
+	if filter.Descending {
+		direction, after = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		keyset := fmt.Sprintf("(%s, p.id) %s ($%d::%s, $%d)", sortKey.expr, after, argCount, sortKey.cast, argCount+1)
+		args = append(args, filter.After.Key, filter.After.ID)
+		argCount += 2
+		// Open-first pages go on within the cursor's group, then on to the closed projects
+		if filter.OpenFirst {
+			keyset = fmt.Sprintf("(%s > $%d OR (%s = $%d AND %s))", projectOpenFirstOrder, argCount, projectOpenFirstOrder, argCount, keyset)
+			args = append(args, filter.After.Closed)
+			argCount++
+		}
+		where += " AND " + keyset
+	}
+
+	order := fmt.Sprintf("%s %s, p.id %s", sortKey.expr, direction, direction)
+	if filter.OpenFirst {
+		order = projectOpenFirstOrder + ", " + order
+	}
+
+	query := `
+SELECT p.id, p.title, p.description, p.status, p.identifier, p.homepage, p.is_public, p.is_template, p.parent_id, p.user_id, p.created_by,
+       p.start_date, p.due_date, p.created_at, p.updated_at, (` + sortKey.expr + `)::text, ` + projectOpenFirstOrder + `
+FROM projects p` + where + " ORDER BY " + order
+
+	// One extra row tells whether another page follows
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit+1)
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	keys := []string{}
+	closed := []bool{}
+	for rows.Next() {
+		var p models.Project
+		var key string
+		var isClosed bool
+		if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.IsTemplate, &p.ParentID, &p.UserID, &p.CreatedBy,
+			&p.StartDate, &p.DueDate, &p.CreatedAt, &p.UpdatedAt, &key, &isClosed); err != nil {
+			return nil, nil, err
+		}
+		projects = append(projects, p)
+		keys = append(keys, key)
+		closed = append(closed, isClosed)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if filter.Limit > 0 && len(projects) > filter.Limit {
+		last := filter.Limit - 1
+		cursor := &models.ProjectCursor{Sort: filter.Sort, Key: keys[last], ID: projects[last].ID}
+		if filter.OpenFirst {
+			cursor.OpenFirst, cursor.Closed = true, closed[last]
+		}
+		return projects[:filter.Limit], cursor, nil
+	}
+	return projects, nil, nil
+}
+
+// GetCopyReferenceDate returns the date a copy's date shift is measured from: the project's start
+// date, or else the earliest task date. Nil means the project has no dates.
+func (r *ProjectRepository) GetCopyReferenceDate(ctx context.Context, id uuid.UUID) (*time.Time, error) {
+	var reference *time.Time
+	err := r.db.QueryRow(ctx, `
+SELECT COALESCE(p.start_date, (SELECT MIN(LEAST(t.start_date, t.due_date)) FROM tasks t WHERE t.project_id = p.id AND t.deleted_at IS NULL))
+FROM projects p WHERE p.id = $1`, id).Scan(&reference)
+	if err == pgx.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	return reference, err
+}
+
+// Copy creates a project from the source in one transaction. Tasks keep their numbers, dates move by
+// dayShift days, and files lists the attachments whose stored files were already duplicated.
+func (r *ProjectRepository) Copy(ctx context.Context, sourceID uuid.UUID, req models.CopyProjectRequest, createdBy uuid.UUID, dayShift int, files []models.CopiedFile) (*models.Project, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var p models.Project
+	err = tx.QueryRow(ctx, `
+INSERT INTO projects (id, title, description, status, identifier, homepage, is_public, is_template, parent_id, created_by, start_date, due_date, task_sequence)
+SELECT $1, $2, s.description, $3, $4, s.homepage, s.is_public, $5, $6, $7,
+       COALESCE($8::date, s.start_date), s.due_date + $9::int, CASE WHEN $10 THEN s.task_sequence ELSE 0 END
+FROM projects s WHERE s.id = $11
+RETURNING id, title, description, status, identifier, homepage, is_public, is_template, parent_id, user_id, created_by, start_date, due_date, created_at, updated_at`,
+		uuid.New(), req.Title, req.Status, req.Identifier, req.IsTemplate, req.ParentID, createdBy,
+		req.StartDate, dayShift, req.Tasks, sourceID).
+		Scan(&p.ID, &p.Title, &p.Description, &p.Status, &p.Identifier, &p.Homepage, &p.IsPublic, &p.IsTemplate, &p.ParentID, &p.UserID, &p.CreatedBy, &p.StartDate, &p.DueDate, &p.CreatedAt, &p.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrProjectIdentifierTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The creator becomes the copy's first manager
+	if _, err := tx.Exec(ctx, "INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, 'manager')", p.ID, createdBy); err != nil {
+		return nil, err
+	}
+
+	if req.Members {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO project_members (project_id, user_id, role)
+SELECT $1, user_id, role FROM project_members WHERE project_id = $2
+ON CONFLICT (project_id, user_id) DO NOTHING`, p.ID, sourceID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `
+INSERT INTO project_group_members (project_id, group_id, role)
+SELECT $1, group_id, role FROM project_group_members WHERE project_id = $2`, p.ID, sourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Versions {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO project_versions (project_id, name, description, due_date, status, sharing)
+SELECT $1, name, description, due_date + $3::int, status, sharing FROM project_versions WHERE project_id = $2`,
+			p.ID, sourceID, dayShift); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Tasks {
+		// Copies start over as open work. Assignees only follow the members, and a task keeps its
+		// version only when that version was copied; copied versions keep their names.
+		if _, err := tx.Exec(ctx, `
+INSERT INTO tasks (id, project_id, sequence_number, title, description, priority, assignee_id, assignee_group_id,
+                   fixed_version_id, author_id, created_by, category, start_date, due_date, estimated_hours, done_ratio)
+SELECT gen_random_uuid(), $1, t.sequence_number, t.title, t.description, t.priority,
+       CASE WHEN $3 THEN t.assignee_id END, CASE WHEN $3 THEN t.assignee_group_id END,
+       (SELECT nv.id FROM project_versions ov
+        JOIN project_versions nv ON nv.project_id = $1 AND nv.name = ov.name
+        WHERE ov.id = t.fixed_version_id AND ov.project_id = $2),
+       t.author_id, $4, CASE WHEN $5 THEN t.category END,
+       t.start_date + $6::int, t.due_date + $6::int, t.estimated_hours, 0
+FROM tasks t WHERE t.project_id = $2 AND t.deleted_at IS NULL`,
+			p.ID, sourceID, req.Members, createdBy, req.Categories, dayShift); err != nil {
+			return nil, err
+		}
+
+		// Copied tasks are found again by their number
+		for _, file := range files {
+			if _, err := tx.Exec(ctx, `
+INSERT INTO task_attachments (task_id, original_filename, stored_filename, file_path, file_size, mime_type, uploaded_by, has_thumbnail, thumbnail_path)
+SELECT nt.id, a.original_filename, $3, $4, a.file_size, a.mime_type, a.uploaded_by, $5::text IS NOT NULL, $5
+FROM task_attachments a
+JOIN tasks ot ON ot.id = a.task_id AND ot.project_id = $2
+JOIN tasks nt ON nt.project_id = $1 AND nt.sequence_number = ot.sequence_number
+WHERE a.id = $6`,
+				p.ID, sourceID, file.StoredFilename, file.FilePath, file.ThumbnailPath, file.SourceID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
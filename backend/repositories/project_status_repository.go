@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrProjectStatusInUse is returned when deleting a status projects still have
+var ErrProjectStatusInUse = errors.New("project status is still in use")
+
+type ProjectStatusRepository interface {
+	List(ctx context.Context) ([]models.ProjectStatus, error)
+	GetByName(ctx context.Context, name string) (*models.ProjectStatus, error)
+	GetDefault(ctx context.Context) (*models.ProjectStatus, error)
+	CountOpen(ctx context.Context) (int, error)
+	Create(ctx context.Context, status *models.ProjectStatus) (bool, error)
+	Update(ctx context.Context, name string, req models.UpdateProjectStatusRequest) (*models.ProjectStatus, error)
+	Delete(ctx context.Context, name string) (bool, error)
+	IsProjectReadOnly(ctx context.Context, projectID uuid.UUID) (bool, error)
+	IsTaskProjectReadOnly(ctx context.Context, taskID uuid.UUID) (bool, error)
+}
+
+type projectStatusRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewProjectStatusRepository(db *pgxpool.Pool) ProjectStatusRepository {
+	return &projectStatusRepository{db: db}
+}
+
+const projectStatusSelectColumns = `s.name, s.is_closed, s.is_read_only, s.position,
+	(SELECT COUNT(*) FROM projects p WHERE p.status = s.name), s.created_at, s.updated_at`
+
+func scanProjectStatus(row pgx.Row) (*models.ProjectStatus, error) {
+	var s models.ProjectStatus
+	err := row.Scan(&s.Name, &s.IsClosed, &s.IsReadOnly, &s.Position, &s.ProjectCount, &s.CreatedAt, &s.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List returns statuses in display order
+func (r *projectStatusRepository) List(ctx context.Context) ([]models.ProjectStatus, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+projectStatusSelectColumns+" FROM project_statuses s ORDER BY s.position, s.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := []models.ProjectStatus{}
+	for rows.Next() {
+		status, err := scanProjectStatus(rows)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, rows.Err()
+}
+
+func (r *projectStatusRepository) GetByName(ctx context.Context, name string) (*models.ProjectStatus, error) {
+	return scanProjectStatus(r.db.QueryRow(ctx, "SELECT "+projectStatusSelectColumns+" FROM project_statuses s WHERE s.name = $1", name))
+}
+
+// GetDefault returns the first open status in display order, given to projects created without one
+func (r *projectStatusRepository) GetDefault(ctx context.Context) (*models.ProjectStatus, error) {
+	return scanProjectStatus(r.db.QueryRow(ctx,
+		"SELECT "+projectStatusSelectColumns+" FROM project_statuses s WHERE NOT s.is_closed ORDER BY s.position, s.name LIMIT 1"))
+}
+
+func (r *projectStatusRepository) CountOpen(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM project_statuses WHERE NOT is_closed").Scan(&count)
+	return count, err
+}
+
+// Create inserts a status; false means the name is taken
+func (r *projectStatusRepository) Create(ctx context.Context, status *models.ProjectStatus) (bool, error) {
+	err := r.db.QueryRow(ctx, `
+INSERT INTO project_statuses (name, is_closed, is_read_only, position) VALUES ($1, $2, $3, $4)
+ON CONFLICT (name) DO NOTHING
+RETURNING created_at, updated_at`,
+		status.Name, status.IsClosed, status.IsReadOnly, status.Position).Scan(&status.CreatedAt, &status.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Update replaces the flags and position; nil means the status does not exist
+func (r *projectStatusRepository) Update(ctx context.Context, name string, req models.UpdateProjectStatusRequest) (*models.ProjectStatus, error) {
+	tag, err := r.db.Exec(ctx,
+		"UPDATE project_statuses SET is_closed = $2, is_read_only = $3, position = $4, updated_at = NOW() WHERE name = $1",
+		name, req.IsClosed, req.IsReadOnly, req.Position)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+	return r.GetByName(ctx, name)
+}
+
+// Delete removes a status no project has
+func (r *projectStatusRepository) Delete(ctx context.Context, name string) (bool, error) {
+	tag, err := r.db.Exec(ctx, "DELETE FROM project_statuses WHERE name = $1", name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return false, ErrProjectStatusInUse
+		}
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// IsProjectReadOnly reports whether the project's status freezes its content
+func (r *projectStatusRepository) IsProjectReadOnly(ctx context.Context, projectID uuid.UUID) (bool, error) {
+	var readOnly bool
+	err := r.db.QueryRow(ctx, `
+SELECT COALESCE(s.is_read_only, false)
+FROM projects p LEFT JOIN project_statuses s ON s.name = p.status
+WHERE p.id = $1`, projectID).Scan(&readOnly)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	return readOnly, err
+}
+
+// IsTaskProjectReadOnly is IsProjectReadOnly for the project a task belongs to
+func (r *projectStatusRepository) IsTaskProjectReadOnly(ctx context.Context, taskID uuid.UUID) (bool, error) {
+	var readOnly bool
+	err := r.db.QueryRow(ctx, `
+SELECT COALESCE(s.is_read_only, false)
+FROM tasks t JOIN projects p ON p.id = t.project_id LEFT JOIN project_statuses s ON s.name = p.status
+WHERE t.id = $1`, taskID).Scan(&readOnly)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	return readOnly, err
+}
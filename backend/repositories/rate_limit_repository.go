@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"project-management/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RateLimitRepository interface {
+	Hit(ctx context.Context, key string, window time.Duration) (*models.RateLimitWindow, error)
+	Undo(ctx context.Context, key string, windowStart time.Time) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type rateLimitRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRateLimitRepository(db *pgxpool.Pool) RateLimitRepository {
+	return &rateLimitRepository{db: db}
+}
+
+// Hit counts a request in the current window and returns it with the previous window's count.
+// Windows are aligned on the database clock so every replica agrees on them.
+func (r *rateLimitRepository) Hit(ctx context.Context, key string, window time.Duration) (*models.RateLimitWindow, error) {
+	query := `
+WITH clock AS (
+    SELECT EXTRACT(EPOCH FROM NOW())::float8 AS now_epoch,
+           to_timestamp(floor(EXTRACT(EPOCH FROM NOW())::float8 / $2::float8) * $2::float8) AS window_start
+), hit AS (
+    INSERT INTO rate_limits (key, window_start, hits, expires_at)
+    SELECT $1, window_start, 1, window_start + make_interval(secs => $2::float8 * 2) FROM clock
+    ON CONFLICT (key, window_start) DO UPDATE SET hits = rate_limits.hits + 1
+    RETURNING window_start, hits
+)
+SELECT hit.window_start, hit.hits,
+       COALESCE((SELECT p.hits FROM rate_limits p
+                 WHERE p.key = $1 AND p.window_start = hit.window_start - make_interval(secs => $2::float8)), 0),
+       clock.now_epoch - EXTRACT(EPOCH FROM hit.window_start)::float8
+FROM hit, clock
+`
+	var result models.RateLimitWindow
+	var elapsed float64
+	err := r.db.QueryRow(ctx, query, key, window.Seconds()).Scan(&result.WindowStart, &result.Current, &result.Previous, &elapsed)
+	if err != nil {
+		return nil, err
+	}
+	result.Elapsed = time.Duration(elapsed * float64(time.Second))
+
+	return &result, nil
+}
+
+// Undo takes back a hit for requests the limiter is configured not to count
+func (r *rateLimitRepository) Undo(ctx context.Context, key string, windowStart time.Time) error {
+	query := `UPDATE rate_limits SET hits = hits - 1 WHERE key = $1 AND window_start = $2 AND hits > 0`
+	_, err := r.db.Exec(ctx, query, key, windowStart)
+	return err
+}
+
+// DeleteExpired removes windows that can no longer affect a limit
+func (r *rateLimitRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM rate_limits WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
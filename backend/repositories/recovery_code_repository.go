@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RecoveryCodeRepository interface {
+	ReplaceForUser(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	Use(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountUnused(ctx context.Context, userID uuid.UUID) (int, error)
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type recoveryCodeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRecoveryCodeRepository(db *pgxpool.Pool) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+// ReplaceForUser deletes existing recovery codes and stores the new set atomically
+func (r *recoveryCodeRepository) ReplaceForUser(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM user_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(ctx,
+			"INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1, $2, $3, $4)",
+			uuid.New(), userID, hash, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Use marks an unused recovery code as used. Returns false if no such code exists.
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := "UPDATE user_recovery_codes SET used_at = $1 WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL"
+	result, err := r.db.Exec(ctx, query, time.Now(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *recoveryCodeRepository) CountUnused(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := "SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL"
+
+	var count int
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *recoveryCodeRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM user_recovery_codes WHERE user_id = $1", userID)
+	return err
+}
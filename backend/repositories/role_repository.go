@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-management/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrRoleInUse is returned when deleting a role still assigned to users or invitations
+var ErrRoleInUse = errors.New("role is still assigned")
+
+type RoleRepository interface {
+	List(ctx context.Context) ([]models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	Create(ctx context.Context, role *models.Role) (bool, error)
+	Update(ctx context.Context, name, description string, permissions []string) (*models.Role, error)
+	Delete(ctx context.Context, name string) (bool, error)
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+type roleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRoleRepository(db *pgxpool.Pool) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+const roleSelectColumns = `r.name, r.description, r.permissions, r.is_builtin,
+	(SELECT COUNT(*) FROM users u WHERE u.role = r.name), r.created_at, r.updated_at`
+
+func scanRole(row pgx.Row) (*models.Role, error) {
+	var role models.Role
+	err := row.Scan(&role.Name, &role.Description, &role.Permissions, &role.IsBuiltin, &role.UserCount, &role.CreatedAt, &role.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// List returns built-in roles first, then custom roles by name
+func (r *roleRepository) List(ctx context.Context) ([]models.Role, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+roleSelectColumns+" FROM roles r ORDER BY r.is_builtin DESC, r.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []models.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	return scanRole(r.db.QueryRow(ctx, "SELECT "+roleSelectColumns+" FROM roles r WHERE r.name = $1", name))
+}
+
+// Create inserts a custom role; false means the name is taken
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) (bool, error) {
+	err := r.db.QueryRow(ctx, `
+INSERT INTO roles (name, description, permissions) VALUES ($1, $2, $3)
+ON CONFLICT (name) DO NOTHING
+RETURNING created_at, updated_at`,
+		role.Name, role.Description, role.Permissions).Scan(&role.CreatedAt, &role.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Update replaces the description and permissions; nil means the role does not exist
+func (r *roleRepository) Update(ctx context.Context, name, description string, permissions []string) (*models.Role, error) {
+	tag, err := r.db.Exec(ctx,
+		"UPDATE roles SET description = $2, permissions = $3, updated_at = NOW() WHERE name = $1",
+		name, description, permissions)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+	return r.GetByName(ctx, name)
+}
+
+// Delete removes a custom role; built-in roles are never deleted
+func (r *roleRepository) Delete(ctx context.Context, name string) (bool, error) {
+	tag, err := r.db.Exec(ctx, "DELETE FROM roles WHERE name = $1 AND NOT is_builtin", name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return false, ErrRoleInUse
+		}
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *roleRepository) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	var allowed bool
+	err := r.db.QueryRow(ctx, "SELECT role_has_permission($1, $2)", role, permission).Scan(&allowed)
+	return allowed, err
+}
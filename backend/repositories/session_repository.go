@@ -14,6 +14,11 @@ type SessionRepository interface {
 	Create(ctx context.Context, session *models.Session) error
 	GetByRefreshToken(ctx context.Context, tokenHash string) (*models.Session, error)
 	Revoke(ctx context.Context, tokenHash string) error
+	RevokeIfActive(ctx context.Context, tokenHash string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	RevokeByID(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID, exceptFamilyID uuid.UUID) (int, error)
 	DeleteExpired(ctx context.Context) (int, error)
 }
 
@@ -27,17 +32,23 @@ func NewSessionRepository(db *pgxpool.Pool) SessionRepository {
 
 func (r *sessionRepository) Create(ctx context.Context, session *models.Session) error {
 	query := `
-INSERT INTO sessions (id, user_id, refresh_token_hash, user_agent, ip_address, created_at, expires_at, revoked)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO sessions (id, user_id, family_id, refresh_token_hash, user_agent, ip_address, created_at, expires_at, revoked)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING id, created_at
 `
 
 	session.ID = uuid.New()
 	session.CreatedAt = time.Now()
 
+	// A session without a family starts a new one
+	if session.FamilyID == uuid.Nil {
+		session.FamilyID = session.ID
+	}
+
 	return r.db.QueryRow(ctx, query,
 		session.ID,
 		session.UserID,
+		session.FamilyID,
 		session.RefreshTokenHash,
 		session.UserAgent,
 		session.IPAddress,
@@ -49,7 +60,7 @@ RETURNING id, created_at
 
 func (r *sessionRepository) GetByRefreshToken(ctx context.Context, tokenHash string) (*models.Session, error) {
 	query := `
-SELECT id, user_id, refresh_token_hash, user_agent, ip_address, created_at, expires_at, revoked
+SELECT id, user_id, family_id, refresh_token_hash, user_agent, ip_address, created_at, expires_at, revoked
 FROM sessions
 WHERE refresh_token_hash = $1
 `
@@ -58,6 +69,7 @@ WHERE refresh_token_hash = $1
 	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
 		&session.ID,
 		&session.UserID,
+		&session.FamilyID,
 		&session.RefreshTokenHash,
 		&session.UserAgent,
 		&session.IPAddress,
@@ -83,10 +95,117 @@ WHERE refresh_token_hash = $1
 	return err
 }
 
+// RevokeIfActive revokes a session only if it is still active.
+// Returns false when the session was already revoked, which lets callers
+// detect concurrent or repeated use of the same refresh token.
+func (r *sessionRepository) RevokeIfActive(ctx context.Context, tokenHash string) (bool, error) {
+	query := `
+UPDATE sessions
+SET revoked = true
+WHERE refresh_token_hash = $1 AND revoked = false
+`
+
+	result, err := r.db.Exec(ctx, query, tokenHash)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// RevokeFamily revokes every session rotated from the same login
+func (r *sessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `
+UPDATE sessions
+SET revoked = true
+WHERE family_id = $1 AND revoked = false
+`
+
+	_, err := r.db.Exec(ctx, query, familyID)
+	return err
+}
+
+// DeleteExpired removes sessions past their expiry. Revoked sessions are kept
+// until they expire so that reuse of a rotated refresh token is still detected.
+// ListActiveByUser returns the user's non-revoked, unexpired sessions (one per device/login)
+func (r *sessionRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	query := `
+SELECT s.id, s.user_id, s.family_id, s.refresh_token_hash, s.user_agent, s.ip_address, s.created_at, s.expires_at, s.revoked,
+       (SELECT MIN(f.created_at) FROM sessions f WHERE f.family_id = s.family_id) AS signed_in_at
+FROM sessions s
+WHERE s.user_id = $1 AND s.revoked = false AND s.expires_at > $2
+ORDER BY s.created_at DESC
+`
+
+	rows, err := r.db.Query(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.FamilyID,
+			&session.RefreshTokenHash,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.ExpiresAt,
+			&session.Revoked,
+			&session.SignedInAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeByID revokes the session's whole token family, scoped to the owning user.
+// Returns false if the session doesn't exist, belongs to someone else or is already revoked.
+func (r *sessionRepository) RevokeByID(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	query := `
+UPDATE sessions
+SET revoked = true
+WHERE user_id = $1 AND revoked = false
+AND family_id = (SELECT family_id FROM sessions WHERE id = $2 AND user_id = $1)
+`
+
+	result, err := r.db.Exec(ctx, query, userID, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RevokeAllByUser revokes every active session of a user except the given family.
+// Pass uuid.Nil to revoke all of them.
+func (r *sessionRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID, exceptFamilyID uuid.UUID) (int, error) {
+	query := `
+UPDATE sessions
+SET revoked = true
+WHERE user_id = $1 AND revoked = false AND family_id <> $2
+`
+
+	result, err := r.db.Exec(ctx, query, userID, exceptFamilyID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
 func (r *sessionRepository) DeleteExpired(ctx context.Context) (int, error) {
 	query := `
 	DELETE FROM sessions
-	WHERE expires_at < $1 OR revoked = true
+	WHERE expires_at < $1
 	`
 
 	result, err := r.db.Exec(ctx, query, time.Now())
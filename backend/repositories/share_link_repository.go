@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ShareLinkRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewShareLinkRepository(db *pgxpool.Pool) *ShareLinkRepository {
+	return &ShareLinkRepository{db: db}
+}
+
+const shareLinkColumns = `id, project_id, label, token_hash, token_prefix, include_attachments, include_comments,
+	created_by, expires_at, revoked_at, last_accessed_at, access_count, created_at`
+
+func scanShareLink(row pgx.Row) (*models.ShareLink, error) {
+	var l models.ShareLink
+	err := row.Scan(&l.ID, &l.ProjectID, &l.Label, &l.TokenHash, &l.TokenPrefix, &l.IncludeAttachments, &l.IncludeComments,
+		&l.CreatedBy, &l.ExpiresAt, &l.RevokedAt, &l.LastAccessedAt, &l.AccessCount, &l.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *ShareLinkRepository) Create(ctx context.Context, link *models.ShareLink) error {
+	return r.db.QueryRow(ctx, `
+INSERT INTO project_share_links (project_id, label, token_hash, token_prefix, include_attachments, include_comments, created_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at`,
+		link.ProjectID, link.Label, link.TokenHash, link.TokenPrefix, link.IncludeAttachments, link.IncludeComments, link.CreatedBy, link.ExpiresAt).
+		Scan(&link.ID, &link.CreatedAt)
+}
+
+// ListByProject returns the project's links, newest first, including revoked and expired ones
+func (r *ShareLinkRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]models.ShareLink, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+shareLinkColumns+" FROM project_share_links WHERE project_id = $1 ORDER BY created_at DESC", projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.ShareLink{}
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+	return links, rows.Err()
+}
+
+func (r *ShareLinkRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.ShareLink, error) {
+	return scanShareLink(r.db.QueryRow(ctx, "SELECT "+shareLinkColumns+" FROM project_share_links WHERE token_hash = $1", tokenHash))
+}
+
+func (r *ShareLinkRepository) GetByID(ctx context.Context, projectID, linkID uuid.UUID) (*models.ShareLink, error) {
+	return scanShareLink(r.db.QueryRow(ctx, "SELECT "+shareLinkColumns+" FROM project_share_links WHERE id = $1 AND project_id = $2", linkID, projectID))
+}
+
+// Revoke marks an active link revoked; false means no such active link in the project
+func (r *ShareLinkRepository) Revoke(ctx context.Context, projectID, linkID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx,
+		"UPDATE project_share_links SET revoked_at = NOW() WHERE id = $1 AND project_id = $2 AND revoked_at IS NULL",
+		linkID, projectID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// RecordAccess logs a request made through the link and bumps its counters
+func (r *ShareLinkRepository) RecordAccess(ctx context.Context, linkID uuid.UUID, path, ipAddress, userAgent string) error {
+	_, err := r.db.Exec(ctx, `
+WITH access AS (
+    INSERT INTO project_share_link_accesses (link_id, path, ip_address, user_agent)
+    VALUES ($1, $2, $3, $4)
+)
+UPDATE project_share_links SET last_accessed_at = NOW(), access_count = access_count + 1 WHERE id = $1`,
+		linkID, path, ipAddress, userAgent)
+	return err
+}
+
+// ListAccesses returns the most recent requests made through the link
+func (r *ShareLinkRepository) ListAccesses(ctx context.Context, linkID uuid.UUID, limit int) ([]models.ShareLinkAccess, error) {
+	rows, err := r.db.Query(ctx, `
+SELECT id, link_id, path, COALESCE(ip_address, ''), COALESCE(user_agent, ''), accessed_at
+FROM project_share_link_accesses
+WHERE link_id = $1
+ORDER BY accessed_at DESC
+LIMIT $2`, linkID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accesses := []models.ShareLinkAccess{}
+	for rows.Next() {
+		var a models.ShareLinkAccess
+		if err := rows.Scan(&a.ID, &a.LinkID, &a.Path, &a.IPAddress, &a.UserAgent, &a.AccessedAt); err != nil {
+			return nil, err
+		}
+		accesses = append(accesses, a)
+	}
+	return accesses, rows.Err()
+}
@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"fmt"
 	"project-management/models"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -19,7 +21,7 @@ func NewTaskRepository(db *pgxpool.Pool) *TaskRepository {
 
 func (r *TaskRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.Task, error) {
 	rows, err := r.db.Query(ctx,
-		"SELECT id, project_id, title, description, priority, completed, assignee_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at FROM tasks WHERE project_id = $1 ORDER BY created_at DESC",
+		"SELECT id, project_id, sequence_number, task_key(project_id, sequence_number), title, description, priority, completed, assignee_id, assignee_group_id, fixed_version_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at FROM tasks WHERE project_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC",
 		projectID)
 	if err != nil {
 		return nil, err
@@ -29,7 +31,7 @@ func (r *TaskRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID
 	var tasks []models.Task
 	for rows.Next() {
 		var t models.Task
-		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt); err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, t)
@@ -40,7 +42,7 @@ func (r *TaskRepository) GetByProjectID(ctx context.Context, projectID uuid.UUID
 
 func (r *TaskRepository) GetByProjectIDPaginated(ctx context.Context, projectID uuid.UUID, limit int, offset int) ([]models.Task, error) {
 	rows, err := r.db.Query(ctx,
-		"SELECT id, project_id, title, description, priority, completed, assignee_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at FROM tasks WHERE project_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3",
+		"SELECT id, project_id, sequence_number, task_key(project_id, sequence_number), title, description, priority, completed, assignee_id, assignee_group_id, fixed_version_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at FROM tasks WHERE project_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $2 OFFSET $3",
 		projectID, limit, offset)
 	if err != nil {
 		return nil, err
@@ -50,7 +52,7 @@ func (r *TaskRepository) GetByProjectIDPaginated(ctx context.Context, projectID
 	var tasks []models.Task
 	for rows.Next() {
 		var t models.Task
-		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt); err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, t)
@@ -61,18 +63,150 @@ func (r *TaskRepository) GetByProjectIDPaginated(ctx context.Context, projectID
 
 func (r *TaskRepository) GetTotalTasksByProject(ctx context.Context, projectID uuid.UUID) (int, error) {
 	var total int
-	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM tasks WHERE project_id = $1", projectID).Scan(&total)
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM tasks WHERE project_id = $1 AND deleted_at IS NULL", projectID).Scan(&total)
 	if err != nil {
 		return 0, err
 	}
 	return total, nil
 }
 
+// liveTaskFilter leaves out tasks in the trash and the tasks of projects in the trash
+const liveTaskFilter = "deleted_at IS NULL AND project_id NOT IN (SELECT id FROM projects WHERE deleted_at IS NOT NULL)"
+
+// subtreeTasksFilter limits tasks to the project and the subprojects the user can see, leaving out deleted tasks
+const subtreeTasksFilter = "deleted_at IS NULL AND project_id IN (SELECT s FROM project_subtree($1) s WHERE s = $1 OR project_role(s, $2, $3) IS NOT NULL)"
+
+// GetBySubtreePaginated returns tasks of the project and its visible subprojects, newest first
+func (r *TaskRepository) GetBySubtreePaginated(ctx context.Context, projectID, userID uuid.UUID, role string, limit int, offset int) ([]models.Task, error) {
+	rows, err := r.db.Query(ctx,
+		"SELECT id, project_id, sequence_number, task_key(project_id, sequence_number), title, description, priority, completed, assignee_id, assignee_group_id, fixed_version_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at FROM tasks WHERE "+subtreeTasksFilter+" ORDER BY created_at DESC LIMIT $4 OFFSET $5",
+		projectID, userID, role, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var t models.Task
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, nil
+}
+
+func (r *TaskRepository) GetTotalTasksBySubtree(ctx context.Context, projectID, userID uuid.UUID, role string) (int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM tasks WHERE "+subtreeTasksFilter, projectID, userID, role).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Search marks matched words with these bytes, which typed task text does not contain; the service
+// escapes the snippets and turns the markers into <mark> tags
+const (
+	TaskSearchHighlightStart = "\x02"
+	TaskSearchHighlightStop  = "\x03"
+)
+
+const (
+	taskTitleHeadline   = "StartSel=" + TaskSearchHighlightStart + ", StopSel=" + TaskSearchHighlightStop + ", HighlightAll=true"
+	taskSnippetHeadline = "StartSel=" + TaskSearchHighlightStart + ", StopSel=" + TaskSearchHighlightStop + ", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=…"
+)
+
+// Search finds the project's tasks matching the filter, or with filter.IncludeSubprojects those of the
+// project and the subprojects the user can see. Matches are ranked by ts_rank, so title matches come
+// first; without a query the newest tasks come first. total counts every match.
+func (r *TaskRepository) Search(ctx context.Context, projectID, userID uuid.UUID, role string, filter models.TaskSearchFilter, limit int, offset int) ([]models.TaskSearchResult, int, error) {
+	where := " WHERE t.project_id = $1 AND t.deleted_at IS NULL"
+	args := []interface{}{projectID, filter.Query, taskTitleHeadline, taskSnippetHeadline}
+	argCount := 5
+
+	if filter.IncludeSubprojects {
+		where = " WHERE t.deleted_at IS NULL AND t.project_id IN (SELECT s FROM project_subtree($1) s WHERE s = $1 OR project_role(s, $5, $6) IS NOT NULL)"
+		args = append(args, userID, role)
+		argCount += 2
+	}
+
+	if filter.Query != "" {
+		where += " AND d.document @@ q.query"
+	}
+
+	// Task dates are plain dates; both bounds are inclusive
+	for _, bound := range []struct {
+		condition string
+		date      *time.Time
+	}{
+		{"t.start_date >= $%d::date", filter.StartDateFrom},
+		{"t.start_date <= $%d::date", filter.StartDateTo},
+		{"t.due_date >= $%d::date", filter.DueDateFrom},
+		{"t.due_date <= $%d::date", filter.DueDateTo},
+	} {
+		if bound.date != nil {
+			where += " AND " + fmt.Sprintf(bound.condition, argCount)
+			args = append(args, bound.date.Format("2006-01-02"))
+			argCount++
+		}
+	}
+
+	// Documents and the query go through the same normalize_persian for matching; the highlights are
+	// cut from the text as it was typed where that text matches, see task_search_headline
+	query := `
+WITH q AS (SELECT task_search_query($2) AS query)
+SELECT t.id, t.project_id, t.sequence_number, task_key(t.project_id, t.sequence_number), t.title, t.description, t.priority, t.completed,
+       t.assignee_id, t.assignee_group_id, t.fixed_version_id, t.author_id, t.category, t.start_date, t.due_date, t.estimated_hours,
+       t.done_ratio, t.created_at, t.updated_at,
+       CASE WHEN $2 = '' THEN 0 ELSE ts_rank(d.document, q.query) END AS rank,
+       CASE WHEN $2 = '' THEN '' ELSE task_search_headline(t.title, q.query, $3) END,
+       CASE WHEN $2 = '' THEN '' ELSE task_search_headline(t.description, q.query, $4) END,
+       COALESCE(task_search_headline(c.content, q.query, $4), ''),
+       COUNT(*) OVER ()
+FROM tasks t
+JOIN task_search_documents d ON d.task_id = t.id
+CROSS JOIN q
+LEFT JOIN LATERAL (
+    SELECT m.content FROM comments m
+    WHERE $2 <> '' AND m.task_id = t.id AND to_tsvector('simple', normalize_persian(m.content)) @@ q.query
+    ORDER BY m.created_at DESC
+    LIMIT 1
+) c ON true` + where + fmt.Sprintf(" ORDER BY rank DESC, t.created_at DESC LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []models.TaskSearchResult{}
+	total := 0
+	for rows.Next() {
+		var t models.TaskSearchResult
+		if err := rows.Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed,
+			&t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours,
+			&t.DoneRatio, &t.CreatedAt, &t.UpdatedAt,
+			&t.Rank, &t.TitleHighlight, &t.DescriptionHighlight, &t.CommentHighlight, &total); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
 func (r *TaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
 	var t models.Task
 	err := r.db.QueryRow(ctx,
-		"SELECT id, project_id, title, description, priority, completed, assignee_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at FROM tasks WHERE id = $1", id).
-		Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt)
+		"SELECT id, project_id, sequence_number, task_key(project_id, sequence_number), title, description, priority, completed, assignee_id, assignee_group_id, fixed_version_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at FROM tasks WHERE id = $1 AND deleted_at IS NULL", id).
+		Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -87,19 +221,23 @@ func (r *TaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tas
 func (r *TaskRepository) GetByIDWithUsers(ctx context.Context, id uuid.UUID) (*models.TaskWithUsers, error) {
 	var t models.TaskWithUsers
 	err := r.db.QueryRow(ctx,
-		`SELECT t.id, t.project_id, t.title, t.description, t.priority, t.completed,
-		        t.assignee_id, t.author_id, t.category, t.start_date, t.due_date,
+		`SELECT t.id, t.project_id, t.sequence_number, task_key(t.project_id, t.sequence_number), t.title, t.description, t.priority, t.completed,
+		        t.assignee_id, t.assignee_group_id, t.fixed_version_id, t.author_id, t.category, t.start_date, t.due_date,
 		        t.estimated_hours, t.done_ratio, t.created_at, t.updated_at,
 		        assignee.username as assignee_name,
+		        assignee_group.name as assignee_group_name,
+		        fixed_version.name as fixed_version_name,
 		        author.username as author_name
 		 FROM tasks t
 		 LEFT JOIN users assignee ON t.assignee_id = assignee.id
+		 LEFT JOIN user_groups assignee_group ON t.assignee_group_id = assignee_group.id
+		 LEFT JOIN project_versions fixed_version ON t.fixed_version_id = fixed_version.id
 		 LEFT JOIN users author ON t.author_id = author.id
-		 WHERE t.id = $1`, id).
-		Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Priority, &t.Completed,
-			&t.AssigneeID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate,
+		 WHERE t.id = $1 AND t.deleted_at IS NULL`, id).
+		Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed,
+			&t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate,
 			&t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt,
-			&t.AssigneeName, &t.AuthorName)
+			&t.AssigneeName, &t.AssigneeGroupName, &t.FixedVersionName, &t.AuthorName)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -116,9 +254,13 @@ func (r *TaskRepository) Create(ctx context.Context, projectID uuid.UUID, req mo
 	var t models.Task
 
 	err := r.db.QueryRow(ctx,
-		"INSERT INTO tasks (id, project_id, title, description, priority, assignee_id, author_id, category, start_date, due_date, estimated_hours, done_ratio) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, project_id, title, description, priority, completed, assignee_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at",
-		id, projectID, req.Title, req.Description, req.Priority, req.AssigneeID, req.AuthorID, req.Category, req.StartDate, req.DueDate, req.EstimatedHours, req.DoneRatio).
-		Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt)
+		// The counter row stays locked until the insert commits, so concurrent creates get consecutive numbers
+		`WITH seq AS (UPDATE projects SET task_sequence = task_sequence + 1 WHERE id = $2 RETURNING task_sequence)
+		 INSERT INTO tasks (id, project_id, sequence_number, title, description, priority, assignee_id, assignee_group_id, fixed_version_id, author_id, category, start_date, due_date, estimated_hours, done_ratio)
+		 VALUES ($1, $2, (SELECT task_sequence FROM seq), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		 RETURNING id, project_id, sequence_number, task_key(project_id, sequence_number), title, description, priority, completed, assignee_id, assignee_group_id, fixed_version_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at`,
+		id, projectID, req.Title, req.Description, req.Priority, req.AssigneeID, req.AssigneeGroupID, req.FixedVersionID, req.AuthorID, req.Category, req.StartDate, req.DueDate, req.EstimatedHours, req.DoneRatio).
+		Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt)
 
 	if err != nil {
 		return nil, err
@@ -127,13 +269,20 @@ func (r *TaskRepository) Create(ctx context.Context, projectID uuid.UUID, req mo
 	return &t, nil
 }
 
-func (r *TaskRepository) Update(ctx context.Context, id uuid.UUID, req models.UpdateTaskRequest) (*models.Task, error) {
+const updateTaskQuery = "UPDATE tasks SET title = $1, description = $2, priority = $3, completed = $4, assignee_id = $5, assignee_group_id = $6, fixed_version_id = $7, author_id = $8, category = $9, start_date = $10, due_date = $11, estimated_hours = $12, done_ratio = $13 WHERE id = $14 AND deleted_at IS NULL RETURNING id, project_id, sequence_number, task_key(project_id, sequence_number), title, description, priority, completed, assignee_id, assignee_group_id, fixed_version_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at"
+
+// rowQuerier is a pool or a transaction
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// updateTask runs updateTaskQuery on the pool or inside a transaction
+func updateTask(ctx context.Context, db rowQuerier, id uuid.UUID, req models.UpdateTaskRequest) (*models.Task, error) {
 	var t models.Task
 
-	err := r.db.QueryRow(ctx,
-		"UPDATE tasks SET title = $1, description = $2, priority = $3, completed = $4, assignee_id = $5, author_id = $6, category = $7, start_date = $8, due_date = $9, estimated_hours = $10, done_ratio = $11 WHERE id = $12 RETURNING id, project_id, title, description, priority, completed, assignee_id, author_id, category, start_date, due_date, estimated_hours, done_ratio, created_at, updated_at",
-		req.Title, req.Description, req.Priority, req.Completed, req.AssigneeID, req.AuthorID, req.Category, req.StartDate, req.DueDate, req.EstimatedHours, req.DoneRatio, id).
-		Scan(&t.ID, &t.ProjectID, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt)
+	err := db.QueryRow(ctx, updateTaskQuery,
+		req.Title, req.Description, req.Priority, req.Completed, req.AssigneeID, req.AssigneeGroupID, req.FixedVersionID, req.AuthorID, req.Category, req.StartDate, req.DueDate, req.EstimatedHours, req.DoneRatio, id).
+		Scan(&t.ID, &t.ProjectID, &t.SequenceNumber, &t.Key, &t.Title, &t.Description, &t.Priority, &t.Completed, &t.AssigneeID, &t.AssigneeGroupID, &t.FixedVersionID, &t.AuthorID, &t.Category, &t.StartDate, &t.DueDate, &t.EstimatedHours, &t.DoneRatio, &t.CreatedAt, &t.UpdatedAt)
 
 	if err != nil {
 		return nil, err
@@ -142,7 +291,79 @@ func (r *TaskRepository) Update(ctx context.Context, id uuid.UUID, req models.Up
 	return &t, nil
 }
 
-func (r *TaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	_, err := r.db.Exec(ctx, "DELETE FROM tasks WHERE id = $1", id)
+func (r *TaskRepository) Update(ctx context.Context, id uuid.UUID, req models.UpdateTaskRequest) (*models.Task, error) {
+	return updateTask(ctx, r.db, id, req)
+}
+
+// Move puts the task in another project under the project's next number, keeps the old key as a
+// redirect and applies the update, all in one transaction so a failed update leaves the task in place
+func (r *TaskRepository) Move(ctx context.Context, id, projectID uuid.UUID, req models.UpdateTaskRequest) (*models.Task, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var oldProjectID uuid.UUID
+	var oldSequence int
+	err = tx.QueryRow(ctx, "SELECT project_id, sequence_number FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id).Scan(&oldProjectID, &oldSequence)
+	if err == pgx.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if oldProjectID != projectID {
+		if _, err := tx.Exec(ctx,
+			"INSERT INTO task_key_redirects (project_id, sequence_number, task_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+			oldProjectID, oldSequence, id); err != nil {
+			return nil, err
+		}
+
+		var sequence int
+		if err := tx.QueryRow(ctx, "UPDATE projects SET task_sequence = task_sequence + 1 WHERE id = $1 RETURNING task_sequence", projectID).Scan(&sequence); err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(ctx, "UPDATE tasks SET project_id = $2, sequence_number = $3 WHERE id = $1", id, projectID, sequence); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, "UPDATE time_logs SET project_id = $2 WHERE task_id = $1", id, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := updateTask(ctx, tx, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// FindByKey resolves a project identifier and task number to a task id.
+// moved is true when the key belonged to the task before it left that project.
+func (r *TaskRepository) FindByKey(ctx context.Context, identifier string, sequence int) (id uuid.UUID, moved bool, err error) {
+	err = r.db.QueryRow(ctx, `
+SELECT t.id, false FROM tasks t JOIN projects p ON p.id = t.project_id
+WHERE p.identifier = $1 AND t.sequence_number = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+UNION ALL
+SELECT k.task_id, true FROM task_key_redirects k JOIN projects p ON p.id = k.project_id
+JOIN tasks t ON t.id = k.task_id
+WHERE p.identifier = $1 AND k.sequence_number = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+LIMIT 1`, identifier, sequence).Scan(&id, &moved)
+	if err == pgx.ErrNoRows {
+		return uuid.Nil, false, models.ErrNotFound
+	}
+	return id, moved, err
+}
+
+// Delete moves the task to the trash; comments, time logs and attachments stay with it
+func (r *TaskRepository) Delete(ctx context.Context, id, deletedBy uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "UPDATE tasks SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL", id, deletedBy)
 	return err
 }
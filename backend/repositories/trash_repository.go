@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TrashRepository interface {
+	ListProjects(ctx context.Context, deletedBy *uuid.UUID) ([]models.TrashedProject, error)
+	ListTasks(ctx context.Context, deletedBy *uuid.UUID) ([]models.TrashedTask, error)
+	GetProject(ctx context.Context, id uuid.UUID) (*models.TrashedProject, error)
+	GetTask(ctx context.Context, id uuid.UUID) (*models.TrashedTask, error)
+	RestoreProject(ctx context.Context, id uuid.UUID) (bool, error)
+	RestoreTask(ctx context.Context, id uuid.UUID) (bool, error)
+	Purge(ctx context.Context, before time.Time) (projects int64, tasks int64, files []models.PurgedFile, err error)
+}
+
+type trashRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTrashRepository(db *pgxpool.Pool) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+const trashedProjectQuery = `
+SELECT p.id, p.title, p.identifier, p.parent_id, parent.deleted_at IS NOT NULL,
+       (SELECT COUNT(*) FROM tasks t WHERE t.project_id = p.id AND t.deleted_at IS NULL),
+       p.deleted_at, p.deleted_by, u.username
+FROM projects p
+LEFT JOIN projects parent ON parent.id = p.parent_id
+LEFT JOIN users u ON u.id = p.deleted_by
+WHERE p.deleted_at IS NOT NULL`
+
+const trashedTaskQuery = `
+SELECT t.id, t.project_id, p.title, task_key(t.project_id, t.sequence_number), t.title, p.deleted_at IS NOT NULL,
+       t.deleted_at, t.deleted_by, u.username
+FROM tasks t
+JOIN projects p ON p.id = t.project_id
+LEFT JOIN users u ON u.id = t.deleted_by
+WHERE t.deleted_at IS NOT NULL`
+
+func scanTrashedProject(row pgx.Row) (*models.TrashedProject, error) {
+	var p models.TrashedProject
+	err := row.Scan(&p.ID, &p.Title, &p.Identifier, &p.ParentID, &p.ParentDeleted, &p.TaskCount, &p.DeletedAt, &p.DeletedBy, &p.DeletedByName)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func scanTrashedTask(row pgx.Row) (*models.TrashedTask, error) {
+	var t models.TrashedTask
+	err := row.Scan(&t.ID, &t.ProjectID, &t.ProjectTitle, &t.Key, &t.Title, &t.ProjectDeleted, &t.DeletedAt, &t.DeletedBy, &t.DeletedByName)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListProjects returns deleted projects, only those deleted by deletedBy when it is set
+func (r *trashRepository) ListProjects(ctx context.Context, deletedBy *uuid.UUID) ([]models.TrashedProject, error) {
+	rows, err := r.db.Query(ctx, trashedProjectQuery+" AND ($1::uuid IS NULL OR p.deleted_by = $1) ORDER BY p.deleted_at DESC, p.title", deletedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.TrashedProject{}
+	for rows.Next() {
+		p, err := scanTrashedProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *p)
+	}
+	return projects, rows.Err()
+}
+
+// ListTasks returns deleted tasks, only those deleted by deletedBy when it is set. A task deleted
+// before its project is listed with ProjectDeleted set; the tasks a project had when it was deleted
+// are not in the trash and come back with the project.
+func (r *trashRepository) ListTasks(ctx context.Context, deletedBy *uuid.UUID) ([]models.TrashedTask, error) {
+	rows, err := r.db.Query(ctx, trashedTaskQuery+" AND ($1::uuid IS NULL OR t.deleted_by = $1) ORDER BY t.deleted_at DESC, t.title", deletedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.TrashedTask{}
+	for rows.Next() {
+		t, err := scanTrashedTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+func (r *trashRepository) GetProject(ctx context.Context, id uuid.UUID) (*models.TrashedProject, error) {
+	return scanTrashedProject(r.db.QueryRow(ctx, trashedProjectQuery+" AND p.id = $1", id))
+}
+
+func (r *trashRepository) GetTask(ctx context.Context, id uuid.UUID) (*models.TrashedTask, error) {
+	return scanTrashedTask(r.db.QueryRow(ctx, trashedTaskQuery+" AND t.id = $1", id))
+}
+
+// RestoreProject takes the project out of the trash together with the subprojects deleted along with it
+func (r *trashRepository) RestoreProject(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, `
+WITH RECURSIVE root AS (
+    SELECT id, deleted_at FROM projects WHERE id = $1 AND deleted_at IS NOT NULL
+), tree(id) AS (
+    SELECT id FROM root
+    UNION
+    SELECT p.id FROM projects p JOIN tree t ON p.parent_id = t.id JOIN root ON p.deleted_at = root.deleted_at
+)
+UPDATE projects SET deleted_at = NULL, deleted_by = NULL WHERE id IN (SELECT id FROM tree)`, id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *trashRepository) RestoreTask(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "UPDATE tasks SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// purgedProjects selects projects deleted before $1 and the deleted subprojects below them
+const purgedProjects = `
+WITH RECURSIVE purged(id) AS (
+    SELECT id FROM projects WHERE deleted_at < $1
+    UNION
+    SELECT p.id FROM projects p JOIN purged ON p.parent_id = purged.id AND p.deleted_at IS NOT NULL
+)`
+
+// Purge permanently deletes projects and tasks deleted before the cutoff. Comments, time logs and
+// attachment rows go with them; the stored files of those attachments are returned for removal.
+func (r *trashRepository) Purge(ctx context.Context, before time.Time) (int64, int64, []models.PurgedFile, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, purgedProjects+`
+SELECT a.file_path, a.thumbnail_path
+FROM task_attachments a
+JOIN tasks t ON t.id = a.task_id
+WHERE t.deleted_at < $1 OR t.project_id IN (SELECT id FROM purged)`, before)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	files := []models.PurgedFile{}
+	for rows.Next() {
+		var f models.PurgedFile
+		if err := rows.Scan(&f.FilePath, &f.ThumbnailPath); err != nil {
+			rows.Close()
+			return 0, 0, nil, err
+		}
+		files = append(files, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, nil, err
+	}
+
+	tasks, err := tx.Exec(ctx, "DELETE FROM tasks WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	// Subprojects still in use lose their purged parent and become top-level projects
+	if _, err := tx.Exec(ctx, purgedProjects+" UPDATE projects SET parent_id = NULL WHERE parent_id IN (SELECT id FROM purged) AND id NOT IN (SELECT id FROM purged)", before); err != nil {
+		return 0, 0, nil, err
+	}
+	// One statement, so subprojects and their parents are removed together
+	projects, err := tx.Exec(ctx, purgedProjects+" DELETE FROM projects WHERE id IN (SELECT id FROM purged)", before)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, nil, err
+	}
+	return projects.RowsAffected(), tasks.RowsAffected(), files, nil
+}
@@ -8,6 +8,7 @@ import (
 	"project-management/models"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,7 +21,29 @@ type UserRepository interface {
 	ListPaginated(ctx context.Context, limit, offset int, role string, isActive *bool) ([]models.User, int, error)
 	UpdateFailedAttempts(ctx context.Context, userID uuid.UUID, attempts int) error
 	LockAccount(ctx context.Context, userID uuid.UUID, lockUntil time.Time) error
-	CountActiveAdmins(ctx context.Context) (int, error)
+	CountActiveWithPermission(ctx context.Context, permission string) (int, error)
+	UpdateTOTP(ctx context.Context, userID uuid.UUID, secret *string, enabled bool) error
+	UpdateTOTPLastStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	GetByExternalSubject(ctx context.Context, provider, subject string) (*models.User, error)
+	LinkExternalIdentity(ctx context.Context, userID uuid.UUID, provider, subject string) error
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (bool, error)
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+}
+
+const userSelectColumns = "id, username, email, email_verified_at, COALESCE(password_hash, ''), password_changed_at, role, auth_provider, external_subject, is_active, failed_login_attempts, locked_until, totp_secret, totp_enabled, totp_last_step, created_at, updated_at, last_login_at"
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email, &user.EmailVerifiedAt, &user.PasswordHash, &user.PasswordChangedAt, &user.Role, &user.AuthProvider, &user.ExternalSubject, &user.IsActive,
+		&user.FailedLoginAttempts, &user.LockedUntil, &user.TOTPSecret, &user.TOTPEnabled, &user.TOTPLastStep,
+		&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 type userRepository struct {
@@ -32,55 +55,40 @@ func NewUserRepository(db *pgxpool.Pool) UserRepository {
 }
 
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	query := "INSERT INTO users (id, username, email, password_hash, role, is_active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, updated_at"
+	query := "INSERT INTO users (id, username, email, email_verified_at, password_hash, role, auth_provider, external_subject, is_active, created_at, updated_at) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $10, $11) RETURNING id, created_at, updated_at"
 
 	user.ID = uuid.New()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
+	if user.AuthProvider == "" {
+		user.AuthProvider = models.AuthProviderLocal
+	}
 
 	return r.db.QueryRow(ctx, query,
-		user.ID, user.Username, user.Email, user.PasswordHash, user.Role, user.IsActive, user.CreatedAt, user.UpdatedAt,
+		user.ID, user.Username, user.Email, user.EmailVerifiedAt, user.PasswordHash, user.Role, user.AuthProvider, user.ExternalSubject, user.IsActive, user.CreatedAt, user.UpdatedAt,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	query := "SELECT id, username, email, password_hash, role, is_active, failed_login_attempts, locked_until, created_at, updated_at, last_login_at FROM users WHERE id = $1"
-
-	user := &models.User{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive,
-		&user.FailedLoginAttempts, &user.LockedUntil, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	query := "SELECT " + userSelectColumns + " FROM users WHERE id = $1"
+	return scanUser(r.db.QueryRow(ctx, query, id))
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := "SELECT id, username, email, password_hash, role, is_active, failed_login_attempts, locked_until, created_at, updated_at, last_login_at FROM users WHERE email = $1"
-
-	user := &models.User{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive,
-		&user.FailedLoginAttempts, &user.LockedUntil, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
+	query := "SELECT " + userSelectColumns + " FROM users WHERE email = $1"
+	return scanUser(r.db.QueryRow(ctx, query, email))
 }
 
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
-	query := "UPDATE users SET username = $1, email = $2, role = $3, is_active = $4, password_hash = $5, last_login_at = $6, updated_at = $7 WHERE id = $8"
+	query := "UPDATE users SET username = $1, email = $2, email_verified_at = $3, role = $4, is_active = $5, password_hash = NULLIF($6, ''), last_login_at = $7, updated_at = $8 WHERE id = $9"
 
 	user.UpdatedAt = time.Now()
-	_, err := r.db.Exec(ctx, query, user.Username, user.Email, user.Role, user.IsActive, user.PasswordHash, user.LastLoginAt, user.UpdatedAt, user.ID)
+	_, err := r.db.Exec(ctx, query, user.Username, user.Email, user.EmailVerifiedAt, user.Role, user.IsActive, user.PasswordHash, user.LastLoginAt, user.UpdatedAt, user.ID)
 	return err
 }
 
 func (r *userRepository) List(ctx context.Context, limit, offset int, role string, isActive *bool) ([]*models.User, int, error) {
-	query := "SELECT id, username, email, role, is_active, created_at, updated_at, last_login_at FROM users WHERE 1=1"
+	query := "SELECT id, username, email, email_verified_at, role, auth_provider, is_active, created_at, updated_at, last_login_at FROM users WHERE 1=1"
 	countQuery := "SELECT COUNT(*) FROM users WHERE 1=1"
 	args := []interface{}{}
 	argCount := 1
@@ -116,7 +124,7 @@ func (r *userRepository) List(ctx context.Context, limit, offset int, role strin
 	users := []*models.User{}
 	for rows.Next() {
 		user := &models.User{}
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.IsActive, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerifiedAt, &user.Role, &user.AuthProvider, &user.IsActive, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -140,7 +148,7 @@ func (r *userRepository) LockAccount(ctx context.Context, userID uuid.UUID, lock
 
 // ListPaginated returns users with pagination (returns User values, not pointers)
 func (r *userRepository) ListPaginated(ctx context.Context, limit, offset int, role string, isActive *bool) ([]models.User, int, error) {
-	query := "SELECT id, username, email, role, is_active, created_at, updated_at, last_login_at FROM users WHERE 1=1"
+	query := "SELECT id, username, email, email_verified_at, role, auth_provider, is_active, created_at, updated_at, last_login_at FROM users WHERE 1=1"
 	countQuery := "SELECT COUNT(*) FROM users WHERE 1=1"
 	args := []interface{}{}
 	argCount := 1
@@ -176,7 +184,7 @@ func (r *userRepository) ListPaginated(ctx context.Context, limit, offset int, r
 	users := []models.User{}
 	for rows.Next() {
 		user := models.User{}
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.IsActive, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.EmailVerifiedAt, &user.Role, &user.AuthProvider, &user.IsActive, &user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -186,11 +194,67 @@ func (r *userRepository) ListPaginated(ctx context.Context, limit, offset int, r
 	return users, total, nil
 }
 
-// CountActiveAdmins returns the count of active admin users
-func (r *userRepository) CountActiveAdmins(ctx context.Context) (int, error) {
-	query := "SELECT COUNT(*) FROM users WHERE role = 'admin' AND is_active = true"
+// CountActiveWithPermission returns the count of active users whose role grants the permission
+func (r *userRepository) CountActiveWithPermission(ctx context.Context, permission string) (int, error) {
+	query := "SELECT COUNT(*) FROM users WHERE is_active = true AND role_has_permission(role, $1)"
 
 	var count int
-	err := r.db.QueryRow(ctx, query).Scan(&count)
+	err := r.db.QueryRow(ctx, query, permission).Scan(&count)
 	return count, err
 }
+
+// UpdateTOTP stores the TOTP secret and enabled flag; a nil secret clears 2FA
+func (r *userRepository) UpdateTOTP(ctx context.Context, userID uuid.UUID, secret *string, enabled bool) error {
+	query := "UPDATE users SET totp_secret = $1, totp_enabled = $2, totp_last_step = NULL, updated_at = $3 WHERE id = $4"
+	_, err := r.db.Exec(ctx, query, secret, enabled, time.Now(), userID)
+	return err
+}
+
+// UpdateTOTPLastStep records the last accepted TOTP step.
+// Returns false if the step (or a later one) was already used.
+func (r *userRepository) UpdateTOTPLastStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := "UPDATE users SET totp_last_step = $1 WHERE id = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)"
+	result, err := r.db.Exec(ctx, query, step, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// GetByExternalSubject finds the user linked to an external provider identity
+func (r *userRepository) GetByExternalSubject(ctx context.Context, provider, subject string) (*models.User, error) {
+	query := "SELECT " + userSelectColumns + " FROM users WHERE auth_provider = $1 AND external_subject = $2"
+	return scanUser(r.db.QueryRow(ctx, query, provider, subject))
+}
+
+// LinkExternalIdentity switches an account to an external provider
+func (r *userRepository) LinkExternalIdentity(ctx context.Context, userID uuid.UUID, provider, subject string) error {
+	query := "UPDATE users SET auth_provider = $1, external_subject = $2, updated_at = $3 WHERE id = $4"
+	_, err := r.db.Exec(ctx, query, provider, subject, time.Now(), userID)
+	return err
+}
+
+func (r *userRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)"
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, username).Scan(&exists)
+	return exists, err
+}
+
+// MarkEmailVerified confirms the address, provided it is still the user's current email
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (bool, error) {
+	query := "UPDATE users SET email_verified_at = $1, updated_at = $1 WHERE id = $2 AND email = $3"
+	result, err := r.db.Exec(ctx, query, time.Now(), userID, email)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// UpdatePassword stores a new password hash and restarts the password age
+func (r *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := "UPDATE users SET password_hash = $1, password_changed_at = $2, updated_at = $2 WHERE id = $3"
+	_, err := r.db.Exec(ctx, query, passwordHash, time.Now(), userID)
+	return err
+}
@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrVersionNameTaken is returned when the project already has a version with the name
+var ErrVersionNameTaken = errors.New("a version with this name already exists in the project")
+
+type VersionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVersionRepository(db *pgxpool.Pool) *VersionRepository {
+	return &VersionRepository{db: db}
+}
+
+const versionQuery = `
+SELECT v.id, v.project_id, p.title, v.name, v.description, v.due_date, v.status, v.sharing, v.created_at, v.updated_at
+FROM project_versions v
+JOIN projects p ON p.id = v.project_id
+`
+
+func scanVersion(row pgx.Row) (*models.Version, error) {
+	var v models.Version
+	err := row.Scan(&v.ID, &v.ProjectID, &v.ProjectTitle, &v.Name, &v.Description, &v.DueDate, &v.Status, &v.Sharing, &v.CreatedAt, &v.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListSharedWith returns the versions usable in the project: its own and those shared with it
+func (r *VersionRepository) ListSharedWith(ctx context.Context, projectID uuid.UUID) ([]models.Version, error) {
+	rows, err := r.db.Query(ctx, versionQuery+`
+WHERE version_shared_with(v.sharing, v.project_id, $1) AND p.deleted_at IS NULL
+ORDER BY v.due_date NULLS LAST, v.name`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.Version{}
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *v)
+	}
+	return versions, rows.Err()
+}
+
+// GetByProject returns the version only when the project owns it
+func (r *VersionRepository) GetByProject(ctx context.Context, projectID, versionID uuid.UUID) (*models.Version, error) {
+	return scanVersion(r.db.QueryRow(ctx, versionQuery+"WHERE v.id = $1 AND v.project_id = $2", versionID, projectID))
+}
+
+// GetSharedWith returns the version only when it can be used in the project
+func (r *VersionRepository) GetSharedWith(ctx context.Context, versionID, projectID uuid.UUID) (*models.Version, error) {
+	return scanVersion(r.db.QueryRow(ctx, versionQuery+"WHERE v.id = $1 AND version_shared_with(v.sharing, v.project_id, $2) AND p.deleted_at IS NULL", versionID, projectID))
+}
+
+func (r *VersionRepository) Create(ctx context.Context, projectID uuid.UUID, req models.CreateVersionRequest) (*models.Version, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `
+INSERT INTO project_versions (project_id, name, description, due_date, status, sharing)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id`,
+		projectID, req.Name, req.Description, req.DueDate, req.Status, req.Sharing).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrVersionNameTaken
+		}
+		return nil, err
+	}
+	return r.GetByProject(ctx, projectID, id)
+}
+
+// Update saves the version and, when moveOpenTasksTo is set, first moves its open tasks there;
+// tasks in the trash keep the version, as CountOpenTasksNotSharedWith did not check them.
+// Tasks of projects the narrowed sharing no longer covers lose the version.
+func (r *VersionRepository) Update(ctx context.Context, projectID, versionID uuid.UUID, req models.UpdateVersionRequest, moveOpenTasksTo *uuid.UUID) (*models.Version, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if moveOpenTasksTo != nil {
+		if _, err := tx.Exec(ctx,
+			"UPDATE tasks SET fixed_version_id = $2 WHERE fixed_version_id = $1 AND completed = false AND "+liveTaskFilter,
+			versionID, *moveOpenTasksTo); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := tx.Exec(ctx, `
+UPDATE project_versions
+SET name = $3, description = $4, due_date = $5, status = $6, sharing = $7, updated_at = NOW()
+WHERE id = $1 AND project_id = $2`,
+		versionID, projectID, req.Name, req.Description, req.DueDate, req.Status, req.Sharing)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrVersionNameTaken
+		}
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE tasks SET fixed_version_id = NULL
+WHERE fixed_version_id = $1 AND NOT version_shared_with($2, $3, project_id)`,
+		versionID, req.Sharing, projectID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByProject(ctx, projectID, versionID)
+}
+
+// Delete removes the version; its tasks keep existing without a version
+func (r *VersionRepository) Delete(ctx context.Context, projectID, versionID uuid.UUID) (bool, error) {
+	result, err := r.db.Exec(ctx, "DELETE FROM project_versions WHERE id = $1 AND project_id = $2", versionID, projectID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *VersionRepository) CountOpenTasks(ctx context.Context, versionID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM tasks WHERE fixed_version_id = $1 AND completed = false AND "+liveTaskFilter, versionID).Scan(&count)
+	return count, err
+}
+
+// CountOpenTasksNotSharedWith counts open tasks of the version whose project cannot use the target version
+func (r *VersionRepository) CountOpenTasksNotSharedWith(ctx context.Context, versionID, targetID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+SELECT COUNT(*)
+FROM tasks t
+JOIN project_versions target ON target.id = $2
+WHERE t.fixed_version_id = $1 AND t.completed = false
+AND t.deleted_at IS NULL AND t.project_id NOT IN (SELECT id FROM projects WHERE deleted_at IS NOT NULL)
+AND NOT version_shared_with(target.sharing, target.project_id, t.project_id)`,
+		versionID, targetID).Scan(&count)
+	return count, err
+}
+
+// GetTaskProgress returns the progress fields of every task in the given versions
+func (r *VersionRepository) GetTaskProgress(ctx context.Context, versionIDs []uuid.UUID) ([]models.VersionTaskProgress, error) {
+	rows, err := r.db.Query(ctx,
+		"SELECT fixed_version_id, completed, done_ratio, estimated_hours FROM tasks WHERE fixed_version_id = ANY($1) AND "+liveTaskFilter,
+		versionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []models.VersionTaskProgress{}
+	for rows.Next() {
+		var p models.VersionTaskProgress
+		if err := rows.Scan(&p.VersionID, &p.Completed, &p.DoneRatio, &p.EstimatedHours); err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+	return progress, rows.Err()
+}
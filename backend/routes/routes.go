@@ -1,11 +1,14 @@
 package routes
 
 import (
+	"time"
+
+	"project-management/config"
 	"project-management/handlers"
 	"project-management/middleware"
+	"project-management/models"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
 func SetupRoutes(
@@ -19,45 +22,138 @@ func SetupRoutes(
 	dashboardHandler *handlers.DashboardHandler,
 	meetingHandler *handlers.MeetingHandler,
 	attachmentHandler *handlers.AttachmentHandler,
+	tokenHandler *handlers.PersonalAccessTokenHandler,
+	invitationHandler *handlers.InvitationHandler,
+	auditHandler *handlers.AuditHandler,
+	projectMemberHandler *handlers.ProjectMemberHandler,
+	roleHandler *handlers.RoleHandler,
+	groupHandler *handlers.GroupHandler,
+	shareLinkHandler *handlers.ShareLinkHandler,
+	versionHandler *handlers.VersionHandler,
+	projectStatusHandler *handlers.ProjectStatusHandler,
+	trashHandler *handlers.TrashHandler,
 ) {
 	app.Use(func(c *fiber.Ctx) error {
 		c.Set("Content-Type", "application/json")
 		return c.Next()
 	})
 
+	// Public keys for services that verify our access tokens
+	app.Get("/.well-known/jwks.json", authHandler.GetJWKS)
+
 	api := app.Group("/api")
 
+	// Rate limit policies (counters are shared across replicas when a store is configured)
+	authLimiter := middleware.RateLimit(middleware.RateLimitPolicy{
+		Name:   "auth",
+		Max:    config.RateLimitAuthMax,
+		Window: time.Duration(config.RateLimitAuthWindow) * time.Second,
+	})
+	loginLimiter := middleware.RateLimit(middleware.RateLimitPolicy{
+		Name:   "login",
+		Max:    config.RateLimitLoginMax,
+		Window: time.Duration(config.RateLimitLoginWindow) * time.Second,
+	})
+	// Placed after RequireAuth so authenticated requests are counted per user
+	apiLimiter := middleware.RateLimit(middleware.RateLimitPolicy{
+		Name:    "api",
+		Max:     config.RateLimitAPIMax,
+		Window:  time.Duration(config.RateLimitAPIWindow) * time.Second,
+		PerUser: true,
+	})
+	// Anonymous share link visitors are counted per IP
+	shareLimiter := middleware.RateLimit(middleware.RateLimitPolicy{
+		Name:   "share",
+		Max:    config.RateLimitAPIMax,
+		Window: time.Duration(config.RateLimitAPIWindow) * time.Second,
+	})
+
 	// Public auth routes (no authentication required)
-	auth := api.Group("/auth", limiter.New(limiter.Config{
-		Max: 10, // 10 requests per minute per IP
-	}))
+	auth := api.Group("/auth", authLimiter)
 	auth.Post("/register", authHandler.Register)
-	auth.Post("/login", limiter.New(limiter.Config{
-		Max:        5,      // 5 attempts per 5 minutes per IP for login
-		Expiration: 5 * 60, // 5 minutes
-	}), authHandler.Login)
+	auth.Post("/login", loginLimiter, authHandler.Login)
+	auth.Post("/login/2fa", loginLimiter, authHandler.LoginTwoFactor)
+	auth.Post("/login/password", loginLimiter, authHandler.ChangeExpiredPassword)
+	auth.Get("/password-policy", authHandler.GetPasswordPolicy)
 	auth.Post("/forgot-password", authHandler.ForgotPassword)
 	auth.Post("/reset-password", authHandler.ResetPassword)
+	auth.Post("/verify-email", authHandler.VerifyEmail)
+	auth.Post("/invitations/preview", invitationHandler.PreviewInvitation)
+	auth.Post("/invitations/accept", invitationHandler.AcceptInvitation)
+	auth.Post("/refresh", authHandler.RefreshToken)
 
-	// Protected auth routes (require authentication)
+	// OpenID Connect single sign-on (browser redirects)
+	auth.Get("/oidc", authHandler.GetOIDCStatus)
+	auth.Get("/oidc/login", authHandler.OIDCLogin)
+	auth.Get("/oidc/callback", authHandler.OIDCCallback)
+
+	// Protected auth routes (require authentication).
+	// Account management is limited to browser sessions; personal access tokens may only call GET /me.
 	auth.Get("/me", middleware.RequireAuth, authHandler.GetCurrentUser)
-	auth.Post("/logout", middleware.RequireAuth, authHandler.Logout)
-	auth.Put("/me", middleware.RequireAuth, authHandler.UpdateProfile)
-	auth.Put("/me/password", middleware.RequireAuth, authHandler.ChangePassword)
+	auth.Post("/logout", middleware.RequireSessionAuth, authHandler.Logout)
+	auth.Put("/me", middleware.RequireSessionAuth, authHandler.UpdateProfile)
+	auth.Put("/me/password", middleware.RequireSessionAuth, authHandler.ChangePassword)
+	auth.Post("/verify-email/resend", middleware.RequireSessionAuth, authHandler.ResendVerificationEmail)
+
+	// Two-factor authentication enrollment
+	auth.Get("/me/2fa", middleware.RequireSessionAuth, authHandler.GetTwoFactorStatus)
+	auth.Post("/me/2fa", middleware.RequireSessionAuth, authHandler.BeginTwoFactorSetup)
+	auth.Post("/me/2fa/confirm", middleware.RequireSessionAuth, authHandler.ConfirmTwoFactorSetup)
+	auth.Delete("/me/2fa", middleware.RequireSessionAuth, authHandler.DisableTwoFactor)
+	auth.Post("/me/2fa/recovery-codes", middleware.RequireSessionAuth, authHandler.RegenerateRecoveryCodes)
+
+	// Session management
+	auth.Get("/sessions", middleware.RequireSessionAuth, authHandler.ListSessions)
+	auth.Delete("/sessions/others", middleware.RequireSessionAuth, authHandler.RevokeOtherSessions)
+	auth.Delete("/sessions/:id", middleware.RequireSessionAuth, authHandler.RevokeSession)
+
+	// Personal access tokens
+	auth.Get("/me/tokens", middleware.RequireSessionAuth, tokenHandler.ListTokens)
+	auth.Post("/me/tokens", middleware.RequireSessionAuth, tokenHandler.CreateToken)
+	auth.Delete("/me/tokens/:id", middleware.RequireSessionAuth, tokenHandler.RevokeToken)
 
 	// Protected project routes
-	projects := api.Group("/projects", middleware.RequireAuth)
+	projects := api.Group("/projects", middleware.RequireAuth, apiLimiter)
 	projects.Get("/", projectHandler.GetAllProjects)
-	projects.Post("/", projectHandler.CreateProject)
+	projects.Post("/", middleware.RequirePermission(models.PermissionProjectCreate), projectHandler.CreateProject)
+	projects.Get("/templates", projectHandler.GetTemplates)
 	projects.Get("/:id", projectHandler.GetProject)
+	projects.Post("/:id/copy", middleware.RequirePermission(models.PermissionProjectCreate), projectHandler.CopyProject)
 	projects.Put("/:id", projectHandler.UpdateProject)
 	projects.Delete("/:id", projectHandler.DeleteProject)
 
+	// Project membership (managers add, change and remove members; members may leave)
+	projects.Get("/:id/members", projectMemberHandler.GetMembers)
+	projects.Post("/:id/members", projectMemberHandler.AddMember)
+	projects.Put("/:id/members/:userId", projectMemberHandler.UpdateMember)
+	projects.Delete("/:id/members/:userId", projectMemberHandler.RemoveMember)
+
+	// Groups as project members (every member of the group gets the group's role)
+	projects.Get("/:id/groups", projectMemberHandler.GetGroups)
+	projects.Post("/:id/groups", projectMemberHandler.AddGroup)
+	projects.Put("/:id/groups/:groupId", projectMemberHandler.UpdateGroup)
+	projects.Delete("/:id/groups/:groupId", projectMemberHandler.RemoveGroup)
+
+	// Project version (milestone) routes; changes are for project managers
+	projects.Get("/:id/versions", versionHandler.GetVersions)
+	projects.Post("/:id/versions", versionHandler.CreateVersion)
+	projects.Put("/:id/versions/:versionId", versionHandler.UpdateVersion)
+	projects.Delete("/:id/versions/:versionId", versionHandler.DeleteVersion)
+	projects.Get("/:id/roadmap", versionHandler.GetRoadmap)
+
+	// Project share link routes (project managers only)
+	projects.Get("/:id/share-links", shareLinkHandler.GetLinks)
+	projects.Post("/:id/share-links", shareLinkHandler.CreateLink)
+	projects.Delete("/:id/share-links/:linkId", shareLinkHandler.RevokeLink)
+	projects.Get("/:id/share-links/:linkId/accesses", shareLinkHandler.GetAccesses)
+
 	projects.Get("/:projectId/tasks", taskHandler.GetTasksByProject)
+	projects.Get("/:projectId/tasks/search", taskHandler.SearchTasks)
 	projects.Post("/:projectId/tasks", taskHandler.CreateTask)
 
 	// Protected task routes
-	tasks := api.Group("/tasks", middleware.RequireAuth)
+	tasks := api.Group("/tasks", middleware.RequireAuth, apiLimiter)
+	tasks.Get("/by-key/:key", taskHandler.GetTaskByKey)
 	tasks.Get("/:id", taskHandler.GetTask)
 	tasks.Put("/:id", taskHandler.UpdateTask)
 	tasks.Patch("/:id/complete", taskHandler.ToggleTaskCompletion)
@@ -74,32 +170,95 @@ func SetupRoutes(
 	tasks.Post("/:taskId/attachments", attachmentHandler.UploadAttachments)
 
 	// Protected timelog routes
-	timelogs := api.Group("/timelogs", middleware.RequireAuth)
+	timelogs := api.Group("/timelogs", middleware.RequireAuth, apiLimiter)
 	timelogs.Get("/:id", timeLogHandler.GetTimeLog)
 	timelogs.Delete("/:id", timeLogHandler.DeleteTimeLog)
 
-	comments := api.Group("/comments", middleware.RequireAuth)
+	comments := api.Group("/comments", middleware.RequireAuth, apiLimiter)
 	comments.Put("/:id", commentHandler.UpdateComment)
 	comments.Delete("/:id", commentHandler.DeleteComment)
 
 	// Attachment routes
-	attachments := api.Group("/attachments", middleware.RequireAuth)
+	attachments := api.Group("/attachments", middleware.RequireAuth, apiLimiter)
 	attachments.Get("/:id/download", attachmentHandler.DownloadAttachment)
 	attachments.Get("/:id/thumbnail", attachmentHandler.GetThumbnail)
 	attachments.Delete("/:id", attachmentHandler.DeleteAttachment)
 
-	// Admin user management routes (admin only)
-	users := api.Group("/users", middleware.RequireAuth, middleware.RequireRole("admin"))
+	// Trash: deleted projects and tasks until they are purged
+	trash := api.Group("/trash", middleware.RequireAuth, apiLimiter)
+	trash.Get("/", trashHandler.GetTrash)
+	trash.Post("/projects/:id/restore", trashHandler.RestoreProject)
+	trash.Post("/tasks/:id/restore", trashHandler.RestoreTask)
+
+	// User management routes (user.manage)
+	users := api.Group("/users", middleware.RequireAuth, apiLimiter, middleware.RequirePermission(models.PermissionUserManage))
 	users.Get("/", userHandler.GetUsers)
+
+	// Roles that can be assigned to users and invitations (registered before /:id)
+	users.Get("/roles", roleHandler.ListRoles)
+
+	// Invitations (registered before /:id)
+	users.Get("/invitations", invitationHandler.ListInvitations)
+	users.Post("/invitations", invitationHandler.CreateInvitation)
+	users.Post("/invitations/:id/resend", invitationHandler.ResendInvitation)
+	users.Delete("/invitations/:id", invitationHandler.RevokeInvitation)
+
 	users.Get("/:id", userHandler.GetUserByID)
 	users.Put("/:id/role", userHandler.UpdateUserRole)
 	users.Put("/:id/activate", userHandler.UpdateUserActivation)
+	users.Get("/:id/sessions", userHandler.GetUserSessions)
+	users.Delete("/:id/sessions", userHandler.RevokeAllUserSessions)
+	users.Delete("/:id/sessions/:sessionId", userHandler.RevokeUserSession)
+
+	admin := api.Group("/admin", middleware.RequireAuth, apiLimiter)
+
+	// Security audit log (audit.view)
+	audit := admin.Group("/audit", middleware.RequirePermission(models.PermissionAuditView))
+	audit.Get("/", auditHandler.ListAuditEntries)
+	audit.Get("/verify", auditHandler.VerifyAuditChain)
+
+	// Role and permission management (role.manage)
+	roles := admin.Group("/roles", middleware.RequirePermission(models.PermissionRoleManage))
+	roles.Get("/", roleHandler.ListRoles)
+	roles.Post("/", roleHandler.CreateRole)
+	roles.Put("/:name", roleHandler.UpdateRole)
+	roles.Delete("/:name", roleHandler.DeleteRole)
+
+	// Group management (group.manage)
+	groups := admin.Group("/groups", middleware.RequirePermission(models.PermissionGroupManage))
+	groups.Get("/", groupHandler.ListGroups)
+	groups.Post("/", groupHandler.CreateGroup)
+	groups.Put("/:id", groupHandler.UpdateGroup)
+	groups.Delete("/:id", groupHandler.DeleteGroup)
+	groups.Get("/:id/members", groupHandler.ListMembers)
+	groups.Post("/:id/members", groupHandler.AddMember)
+	groups.Delete("/:id/members/:userId", groupHandler.RemoveMember)
+
+	// Group list for project membership and task assignment pickers
+	api.Get("/groups", middleware.RequireAuth, apiLimiter, groupHandler.ListGroups)
+
+	// Project status management (project_status.manage)
+	projectStatuses := admin.Group("/project-statuses", middleware.RequirePermission(models.PermissionProjectStatusManage))
+	projectStatuses.Get("/", projectStatusHandler.ListStatuses)
+	projectStatuses.Post("/", projectStatusHandler.CreateStatus)
+	projectStatuses.Put("/:name", projectStatusHandler.UpdateStatus)
+	projectStatuses.Delete("/:name", projectStatusHandler.DeleteStatus)
+
+	// Status list for project forms and filters
+	api.Get("/project-statuses", middleware.RequireAuth, apiLimiter, projectStatusHandler.ListStatuses)
+
+	// Public read-only share link routes (the token is the credential)
+	share := api.Group("/share", shareLimiter)
+	share.Get("/:token", shareLinkHandler.GetSharedProject)
+	share.Get("/:token/tasks/:taskId/comments", shareLinkHandler.GetSharedComments)
+	share.Get("/:token/tasks/:taskId/attachments", shareLinkHandler.GetSharedAttachments)
+	share.Get("/:token/attachments/:attachmentId/download", shareLinkHandler.DownloadSharedAttachment)
 
 	// Dashboard route
-	api.Get("/dashboard", middleware.RequireAuth, dashboardHandler.GetDashboard)
+	api.Get("/dashboard", middleware.RequireAuth, apiLimiter, dashboardHandler.GetDashboard)
 
 	// Meeting routes
-	meetings := api.Group("/meetings", middleware.RequireAuth)
+	meetings := api.Group("/meetings", middleware.RequireAuth, apiLimiter)
 	meetings.Get("/", meetingHandler.ListMeetings)
 	meetings.Post("/", meetingHandler.CreateMeeting)
 	meetings.Get("/next", meetingHandler.GetNextMeeting)
@@ -197,9 +197,10 @@ func main() {
 		now := time.Now()
 
 		_, err = db.Exec(ctx, `
-			INSERT INTO tasks (id, project_id, title, description, priority, completed, category,
+			WITH seq AS (UPDATE projects SET task_sequence = task_sequence + 1 WHERE id = $2 RETURNING task_sequence)
+			INSERT INTO tasks (id, project_id, sequence_number, title, description, priority, completed, category,
 				estimated_hours, done_ratio, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			VALUES ($1, $2, (SELECT task_sequence FROM seq), $3, $4, $5, $6, $7, $8, $9, $10, $11)
 			ON CONFLICT (id) DO NOTHING
 		`, taskID, projectID, task.title, task.description, task.priority, task.completed,
 			task.category, task.estimatedHours, task.doneRatio, now, now)
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"project-management/config"
+	"project-management/models"
+	"project-management/repositories"
+	"project-management/services"
+
+	"github.com/google/uuid"
+)
+
+// initSearchTestDB connects to the migrated database in TEST_DATABASE_URL, or skips the test
+func initSearchTestDB(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping search integration tests")
+	}
+
+	t.Setenv("DATABASE_URL", databaseURL)
+	if err := config.InitDB(); err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+	t.Cleanup(config.CloseDB)
+}
+
+// TestNormalizePersian checks that the spellings of the same Persian text fold together.
+// Needs a migrated database in TEST_DATABASE_URL.
+func TestNormalizePersian(t *testing.T) {
+	initSearchTestDB(t)
+
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"arabic yeh", "عل\u064a", "عل\u06cc"},
+		{"arabic kaf", "\u0643تاب", "\u06a9تاب"},
+		{"persian digits", "۱۴۰۳", "1403"},
+		{"arabic-indic digits", "٢٠٢٤", "2024"},
+		{"zero-width non-joiner", "م\u06cc\u200cروم", "م\u06cc روم"},
+		{"diacritics and tatweel", "\u06a9\u064eت\u0640اب", "\u06a9تاب"},
+		{"latin case", "Report", "report"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			if err := config.DB.QueryRow(context.Background(), "SELECT normalize_persian($1)", tc.input).Scan(&got); err != nil {
+				t.Fatalf("normalize_persian: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("normalize_persian(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestTaskSearch checks what the task search matches, what it leaves out and how it highlights.
+// Needs a migrated database in TEST_DATABASE_URL.
+func TestTaskSearch(t *testing.T) {
+	initSearchTestDB(t)
+
+	ctx := context.Background()
+	userRepo := repositories.NewUserRepository(config.DB)
+	roleRepo := repositories.NewRoleRepository(config.DB)
+	groupRepo := repositories.NewGroupRepository(config.DB)
+	projectRepo := repositories.NewProjectRepository(config.DB)
+	memberRepo := repositories.NewProjectMemberRepository(config.DB)
+	taskRepo := repositories.NewTaskRepository(config.DB)
+	commentRepo := repositories.NewCommentRepository(config.DB)
+	access := services.NewProjectAccess(memberRepo, roleRepo, repositories.NewProjectStatusRepository(config.DB))
+	taskService := services.NewTaskService(taskRepo, projectRepo, userRepo, groupRepo, repositories.NewVersionRepository(config.DB), access, nil)
+
+	suffix := uuid.New().String()[:8]
+	newUser := func(name string) *models.User {
+		now := time.Now()
+		user := &models.User{
+			Username:        name + "_" + suffix,
+			Email:           name + "_" + suffix + "@example.com",
+			EmailVerifiedAt: &now,
+			Role:            models.SystemRoleUser,
+			IsActive:        true,
+		}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", name, err)
+		}
+		return user
+	}
+	newProject := func(identifier string, owner *models.User, parentID *uuid.UUID) *models.Project {
+		project, err := projectRepo.Create(ctx, models.CreateProjectRequest{
+			Title:      identifier,
+			Status:     "active",
+			Identifier: identifier + "-" + suffix,
+			ParentID:   parentID,
+		}, &owner.ID)
+		if err != nil {
+			t.Fatalf("create project %s: %v", identifier, err)
+		}
+		t.Cleanup(func() { projectRepo.Delete(ctx, project.ID, owner.ID) })
+		return project
+	}
+	date := func(value string) *time.Time {
+		d, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			t.Fatalf("parse date: %v", err)
+		}
+		return &d
+	}
+	newTask := func(projectID uuid.UUID, req models.CreateTaskRequest) *models.Task {
+		req.Priority = "Medium"
+		task, err := taskRepo.Create(ctx, projectID, req)
+		if err != nil {
+			t.Fatalf("create task %s: %v", req.Title, err)
+		}
+		return task
+	}
+
+	searcher := newUser("searcher")
+	stranger := newUser("stranger")
+
+	// The searcher manages the project and one subproject; the other subproject is private to a stranger
+	project := newProject("search", searcher, nil)
+	visibleSub := newProject("search-visible", searcher, &project.ID)
+	hiddenSub := newProject("search-hidden", stranger, &project.ID)
+
+	report := newTask(project.ID, models.CreateTaskRequest{
+		Title:     "Quarterly Report",
+		StartDate: date("2025-03-10"),
+		DueDate:   date("2025-03-20"),
+	})
+	persian := newTask(project.ID, models.CreateTaskRequest{Title: "گزارش مال\u06cc"})
+	guide := newTask(project.ID, models.CreateTaskRequest{Title: "\u0643تاب راهنما"})
+	kickoff := newTask(project.ID, models.CreateTaskRequest{Title: "Kickoff", Description: "Plan the first meeting"})
+	if _, err := commentRepo.Create(ctx, kickoff.ID, searcher.ID, models.CreateCommentRequest{Content: "Attach the budget spreadsheet"}); err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	archived := newTask(project.ID, models.CreateTaskRequest{Title: "Quarterly archive"})
+	if err := taskRepo.Delete(ctx, archived.ID, searcher.ID); err != nil {
+		t.Fatalf("delete task: %v", err)
+	}
+	visibleTask := newTask(visibleSub.ID, models.CreateTaskRequest{Title: "Visible budget"})
+	hiddenTask := newTask(hiddenSub.ID, models.CreateTaskRequest{Title: "Hidden budget"})
+
+	search := func(filter models.TaskSearchFilter) *models.TaskSearchResponse {
+		resp, err := taskService.SearchTasks(ctx, searcher.ID, searcher.Role, project.ID, filter, 1, 50)
+		if err != nil {
+			t.Fatalf("search %+v: %v", filter, err)
+		}
+		return resp
+	}
+	ids := func(resp *models.TaskSearchResponse) map[uuid.UUID]bool {
+		found := make(map[uuid.UUID]bool, len(resp.Tasks))
+		for _, task := range resp.Tasks {
+			found[task.ID] = true
+		}
+		return found
+	}
+
+	cases := []struct {
+		name    string
+		filter  models.TaskSearchFilter
+		want    []*models.Task
+		notWant []*models.Task
+	}{
+		{"whole word", models.TaskSearchFilter{Query: "quarterly"}, []*models.Task{report}, []*models.Task{archived}},
+		{"last word as prefix", models.TaskSearchFilter{Query: "quart"}, []*models.Task{report}, []*models.Task{archived}},
+		{"arabic yeh matches persian yeh", models.TaskSearchFilter{Query: "مال\u064a"}, []*models.Task{persian}, nil},
+		{"persian kaf matches arabic kaf", models.TaskSearchFilter{Query: "\u06a9تاب"}, []*models.Task{guide}, nil},
+		{"comment", models.TaskSearchFilter{Query: "spreadsheet"}, []*models.Task{kickoff}, []*models.Task{report}},
+		{"due date bounds are inclusive", models.TaskSearchFilter{DueDateFrom: date("2025-03-20"), DueDateTo: date("2025-03-20")}, []*models.Task{report}, []*models.Task{kickoff}},
+		{"due date before the bound", models.TaskSearchFilter{DueDateTo: date("2025-03-19")}, nil, []*models.Task{report}},
+		{"start date after the bound", models.TaskSearchFilter{StartDateFrom: date("2025-03-11")}, nil, []*models.Task{report}},
+		{"own project only", models.TaskSearchFilter{Query: "budget"}, []*models.Task{kickoff}, []*models.Task{visibleTask, hiddenTask}},
+		{"visible subprojects", models.TaskSearchFilter{Query: "budget", IncludeSubprojects: true}, []*models.Task{kickoff, visibleTask}, []*models.Task{hiddenTask}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			found := ids(search(tc.filter))
+			for _, task := range tc.want {
+				if !found[task.ID] {
+					t.Errorf("%q not found", task.Title)
+				}
+			}
+			for _, task := range tc.notWant {
+				if found[task.ID] {
+					t.Errorf("%q found", task.Title)
+				}
+			}
+		})
+	}
+
+	// Highlights keep the text as it was typed
+	resp := search(models.TaskSearchFilter{Query: "quarterly"})
+	if len(resp.Tasks) != 1 || resp.Tasks[0].TitleHighlight != "<mark>Quarterly</mark> Report" {
+		t.Errorf("title highlight = %+v, want <mark>Quarterly</mark> Report", resp.Tasks)
+	}
+	resp = search(models.TaskSearchFilter{Query: "spreadsheet"})
+	if len(resp.Tasks) != 1 || !strings.Contains(resp.Tasks[0].CommentHighlight, "<mark>spreadsheet</mark>") {
+		t.Errorf("comment highlight = %+v, want <mark>spreadsheet</mark>", resp.Tasks)
+	}
+
+	// A word matched only through normalization is marked in the normalized title
+	resp = search(models.TaskSearchFilter{Query: "\u06a9تاب"})
+	if len(resp.Tasks) != 1 || resp.Tasks[0].TitleHighlight != "<mark>\u06a9تاب</mark> راهنما" {
+		t.Errorf("normalized title highlight = %+v, want <mark>\u06a9تاب</mark> راهنما", resp.Tasks)
+	}
+}
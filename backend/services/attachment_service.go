@@ -20,31 +20,31 @@ import (
 type AttachmentService struct {
 	attachmentRepo    *repositories.AttachmentRepository
 	taskRepo          *repositories.TaskRepository
-	projectRepo       *repositories.ProjectRepository
 	fileStorageService *FileStorageService
 	fileValidationService *FileValidationService
+	access                *ProjectAccess
 }
 
 func NewAttachmentService(
 	attachmentRepo *repositories.AttachmentRepository,
 	taskRepo *repositories.TaskRepository,
-	projectRepo *repositories.ProjectRepository,
 	fileStorageService *FileStorageService,
 	fileValidationService *FileValidationService,
+	access *ProjectAccess,
 ) *AttachmentService {
 	return &AttachmentService{
 		attachmentRepo:        attachmentRepo,
 		taskRepo:              taskRepo,
-		projectRepo:           projectRepo,
 		fileStorageService:    fileStorageService,
 		fileValidationService: fileValidationService,
+		access:                access,
 	}
 }
 
 // UploadAttachments processes batch upload with individual error handling
-func (s *AttachmentService) UploadAttachments(ctx context.Context, taskID uuid.UUID, files []*multipart.FileHeader, userID *uuid.UUID) (*models.UploadResponse, error) {
-	// Verify task exists and user has access
-	if err := s.verifyTaskAccess(ctx, taskID, userID); err != nil {
+func (s *AttachmentService) UploadAttachments(ctx context.Context, taskID uuid.UUID, files []*multipart.FileHeader, userID *uuid.UUID, role string) (*models.UploadResponse, error) {
+	// Verify task exists and user may attach files
+	if err := s.verifyTaskAccess(ctx, taskID, userID, role, actionAttach); err != nil {
 		return nil, err
 	}
 
@@ -217,9 +217,9 @@ func (s *AttachmentService) generateThumbnail(originalPath string) (string, erro
 }
 
 // GetAttachmentsByTaskID retrieves all attachments for a task
-func (s *AttachmentService) GetAttachmentsByTaskID(ctx context.Context, taskID uuid.UUID, userID *uuid.UUID) (*models.AttachmentResponse, error) {
+func (s *AttachmentService) GetAttachmentsByTaskID(ctx context.Context, taskID uuid.UUID, userID *uuid.UUID, role string) (*models.AttachmentResponse, error) {
 	// Verify task access
-	if err := s.verifyTaskAccess(ctx, taskID, userID); err != nil {
+	if err := s.verifyTaskAccess(ctx, taskID, userID, role, actionView); err != nil {
 		return nil, err
 	}
 
@@ -248,7 +248,7 @@ func (s *AttachmentService) GetAttachmentsByTaskID(ctx context.Context, taskID u
 }
 
 // GetAttachmentByID retrieves a single attachment with access control
-func (s *AttachmentService) GetAttachmentByID(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID) (*models.TaskAttachment, error) {
+func (s *AttachmentService) GetAttachmentByID(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID, role string) (*models.TaskAttachment, error) {
 	// Get attachment with uploader info
 	attachment, err := s.attachmentRepo.GetByIDWithUploader(ctx, attachmentID)
 	if err != nil {
@@ -259,8 +259,11 @@ func (s *AttachmentService) GetAttachmentByID(ctx context.Context, attachmentID
 		return nil, fmt.Errorf("attachment not found")
 	}
 
-	// Verify task access
-	if err := s.verifyTaskAccess(ctx, attachment.TaskID, userID); err != nil {
+	// Verify task access; attachments of hidden tasks are reported as missing
+	if err := s.verifyTaskAccess(ctx, attachment.TaskID, userID, role, actionView); err != nil {
+		if err.Error() == "task not found" {
+			return nil, fmt.Errorf("attachment not found")
+		}
 		return nil, err
 	}
 
@@ -268,9 +271,9 @@ func (s *AttachmentService) GetAttachmentByID(ctx context.Context, attachmentID
 }
 
 // DeleteAttachment removes an attachment with proper cleanup
-func (s *AttachmentService) DeleteAttachment(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID) error {
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID, role string) error {
 	// Check if user can delete this attachment
-	canDelete, err := s.CanDeleteAttachment(ctx, attachmentID, userID)
+	canDelete, err := s.CanDeleteAttachment(ctx, attachmentID, userID, role)
 	if err != nil {
 		return err
 	}
@@ -314,84 +317,58 @@ func (s *AttachmentService) DeleteAttachment(ctx context.Context, attachmentID u
 	return nil
 }
 
-// verifyTaskAccess checks if user has access to the task
-func (s *AttachmentService) verifyTaskAccess(ctx context.Context, taskID uuid.UUID, userID *uuid.UUID) error {
-	hasAccess, err := s.HasTaskAccess(ctx, taskID, userID)
+// verifyTaskAccess checks the user's role in the task's project. Tasks hidden from the user
+// report "task not found"; a role that does not allow the action reports access denied.
+func (s *AttachmentService) verifyTaskAccess(ctx context.Context, taskID uuid.UUID, userID *uuid.UUID, role string, action projectAction) error {
+	projectRole, err := s.taskProjectRole(ctx, taskID, userID, role)
 	if err != nil {
 		return err
 	}
 
-	if !hasAccess {
+	allowed, err := s.access.Allows(ctx, projectRole, role, action)
+	if err != nil {
+		return fmt.Errorf("failed to check project access: %w", err)
+	}
+	if !allowed {
 		return fmt.Errorf("access denied: insufficient permissions")
 	}
+	if action.changeContent {
+		if err := s.access.RequireWritableTask(ctx, taskID); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// HasTaskAccess checks if user has access to the task (public method for external use)
-func (s *AttachmentService) HasTaskAccess(ctx context.Context, taskID uuid.UUID, userID *uuid.UUID) (bool, error) {
-	// Get task to verify it exists and get project ID
-	task, err := s.taskRepo.GetByID(ctx, taskID)
-	if err != nil {
-		return false, fmt.Errorf("failed to get task: %w", err)
+// taskProjectRole returns the user's role in the task's project
+func (s *AttachmentService) taskProjectRole(ctx context.Context, taskID uuid.UUID, userID *uuid.UUID, role string) (string, error) {
+	if userID == nil {
+		return "", fmt.Errorf("task not found")
 	}
 
-	if task == nil {
-		return false, fmt.Errorf("task not found")
+	projectRole, err := s.access.TaskRole(ctx, taskID, *userID, role)
+	if err == models.ErrNotFound {
+		return "", fmt.Errorf("task not found")
 	}
-
-	return s.HasProjectAccess(ctx, task.ProjectID, userID, task)
-}
-
-// HasProjectAccess checks if user has access to the project
-func (s *AttachmentService) HasProjectAccess(ctx context.Context, projectID uuid.UUID, userID *uuid.UUID, task *models.Task) (bool, error) {
-	// Get project to check access
-	project, err := s.projectRepo.GetByID(ctx, projectID)
 	if err != nil {
-		return false, fmt.Errorf("failed to get project: %w", err)
-	}
-
-	if project == nil {
-		return false, fmt.Errorf("project not found")
-	}
-
-	// Check access permissions
-	// We allow access if:
-	// 1. Project is public, OR
-	// 2. User is the project creator, OR
-	// 3. User is assigned to the task (if task provided), OR
-	// 4. User is the task author (if task provided)
-	if project.IsPublic {
-		return true, nil
-	}
-
-	if userID == nil {
-		return false, nil // No user ID provided for private project
-	}
-
-	// Check if user is project creator
-	if project.CreatedBy != nil && *project.CreatedBy == *userID {
-		return true, nil
+		return "", fmt.Errorf("failed to check project access: %w", err)
 	}
 
-	// Check task-specific permissions if task is provided
-	if task != nil {
-		// Check if user is task assignee
-		if task.AssigneeID != nil && *task.AssigneeID == *userID {
-			return true, nil
-		}
+	return projectRole, nil
+}
 
-		// Check if user is task author
-		if task.AuthorID != nil && *task.AuthorID == *userID {
-			return true, nil
-		}
+// HasTaskAccess checks if user can see the task's project (public method for external use)
+func (s *AttachmentService) HasTaskAccess(ctx context.Context, taskID uuid.UUID, userID *uuid.UUID, role string) (bool, error) {
+	_, err := s.taskProjectRole(ctx, taskID, userID, role)
+	if err != nil && err.Error() == "task not found" {
+		return false, nil
 	}
-
-	return false, nil
+	return err == nil, err
 }
 
 // CanDeleteAttachment checks if user can delete a specific attachment
-func (s *AttachmentService) CanDeleteAttachment(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID) (bool, error) {
+func (s *AttachmentService) CanDeleteAttachment(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID, role string) (bool, error) {
 	// Get attachment
 	attachment, err := s.attachmentRepo.GetByID(ctx, attachmentID)
 	if err != nil {
@@ -402,48 +379,35 @@ func (s *AttachmentService) CanDeleteAttachment(ctx context.Context, attachmentI
 		return false, fmt.Errorf("attachment not found")
 	}
 
-	// Check basic task access first
-	hasAccess, err := s.HasTaskAccess(ctx, attachment.TaskID, userID)
+	// Attachments of hidden tasks don't exist as far as the user is concerned
+	projectRole, err := s.taskProjectRole(ctx, attachment.TaskID, userID, role)
 	if err != nil {
+		if err.Error() == "task not found" {
+			return false, fmt.Errorf("attachment not found")
+		}
 		return false, err
 	}
 
-	if !hasAccess {
-		return false, nil
+	// Attachments of read-only projects stay as they are
+	if err := s.access.RequireWritableTask(ctx, attachment.TaskID); err != nil {
+		return false, err
 	}
 
-	// Additional deletion permissions:
-	// 1. User uploaded the attachment, OR
-	// 2. User has project-level access (creator)
-	if userID != nil {
-		// Check if user uploaded the attachment
-		if attachment.UploadedBy != nil && *attachment.UploadedBy == *userID {
-			return true, nil
-		}
-
-		// Check if user has project-level access
-		task, err := s.taskRepo.GetByID(ctx, attachment.TaskID)
-		if err != nil {
-			return false, fmt.Errorf("failed to get task: %w", err)
-		}
-
-		if task != nil {
-			project, err := s.projectRepo.GetByID(ctx, task.ProjectID)
-			if err != nil {
-				return false, fmt.Errorf("failed to get project: %w", err)
-			}
-
-			if project != nil && project.CreatedBy != nil && *project.CreatedBy == *userID {
-				return true, nil
-			}
+	// Deletion is allowed if:
+	// 1. User uploaded the attachment and may still attach files, OR
+	// 2. User manages the project or may delete any attachment
+	if attachment.UploadedBy != nil && *attachment.UploadedBy == *userID {
+		allowed, err := s.access.Allows(ctx, projectRole, role, actionAttach)
+		if err != nil || allowed {
+			return allowed, err
 		}
 	}
 
-	return false, nil
+	return s.access.Allows(ctx, projectRole, role, actionDeleteAnyFile)
 }
 
 // VerifyAttachmentAccess verifies user can access a specific attachment
-func (s *AttachmentService) VerifyAttachmentAccess(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID) error {
+func (s *AttachmentService) VerifyAttachmentAccess(ctx context.Context, attachmentID uuid.UUID, userID *uuid.UUID, role string) error {
 	// Get attachment
 	attachment, err := s.attachmentRepo.GetByID(ctx, attachmentID)
 	if err != nil {
@@ -455,5 +419,5 @@ func (s *AttachmentService) VerifyAttachmentAccess(ctx context.Context, attachme
 	}
 
 	// Verify task access
-	return s.verifyTaskAccess(ctx, attachment.TaskID, userID)
+	return s.verifyTaskAccess(ctx, attachment.TaskID, userID, role, actionView)
 }
\ No newline at end of file
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+// auditVerifyBatchSize is how many entries VerifyChain loads per query
+const auditVerifyBatchSize = 1000
+
+// AuditRequest identifies who made a request and from where
+type AuditRequest struct {
+	ActorID   *uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
+type auditRequestKey struct{}
+
+// WithAuditRequest attaches the request origin that audit entries recorded under ctx inherit
+func WithAuditRequest(ctx context.Context, req AuditRequest) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, req)
+}
+
+type AuditService interface {
+	Record(ctx context.Context, entry models.AuditEntry)
+	ListEntries(ctx context.Context, filter models.AuditFilter, page, limit int) ([]models.AuditEntry, int, error)
+	ExportCSV(ctx context.Context, filter models.AuditFilter, w io.Writer) error
+	VerifyChain(ctx context.Context) (*models.AuditChainStatus, error)
+}
+
+type auditService struct {
+	auditRepo repositories.AuditLogRepository
+}
+
+func NewAuditService(auditRepo repositories.AuditLogRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+// Record appends an entry, filling in the time and request origin. Failures are
+// logged rather than returned so auditing never blocks the action itself.
+func (s *auditService) Record(ctx context.Context, entry models.AuditEntry) {
+	// The database keeps microseconds; hash exactly what will be read back
+	entry.OccurredAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	if req, ok := ctx.Value(auditRequestKey{}).(AuditRequest); ok {
+		if entry.ActorID == nil {
+			entry.ActorID = req.ActorID
+		}
+		if entry.IPAddress == "" {
+			entry.IPAddress = req.IPAddress
+		}
+		if entry.UserAgent == "" {
+			entry.UserAgent = req.UserAgent
+		}
+	}
+
+	if err := s.auditRepo.Append(ctx, &entry); err != nil {
+		log.Printf("Failed to write audit log entry %s: %v", entry.EventType, err)
+	}
+}
+
+func (s *auditService) ListEntries(ctx context.Context, filter models.AuditFilter, page, limit int) ([]models.AuditEntry, int, error) {
+	offset := (page - 1) * limit
+	return s.auditRepo.List(ctx, filter, limit, offset)
+}
+
+// ExportCSV writes every matching entry, newest first
+func (s *auditService) ExportCSV(ctx context.Context, filter models.AuditFilter, w io.Writer) error {
+	entries, _, err := s.auditRepo.List(ctx, filter, -1, 0)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		"id", "occurred_at", "event_type", "outcome", "actor_id", "actor_email", "target_id", "target_email",
+		"ip_address", "user_agent", "details", "prev_hash", "hash",
+	})
+
+	for _, entry := range entries {
+		details := ""
+		if len(entry.Details) > 0 {
+			encoded, _ := json.Marshal(entry.Details)
+			details = string(encoded)
+		}
+
+		writer.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.OccurredAt.UTC().Format(time.RFC3339Nano),
+			entry.EventType,
+			entry.Outcome,
+			uuidOrEmpty(entry.ActorID),
+			csvSafe(stringOrEmpty(entry.ActorEmail)),
+			uuidOrEmpty(entry.TargetID),
+			csvSafe(stringOrEmpty(entry.TargetEmail)),
+			csvSafe(entry.IPAddress),
+			csvSafe(entry.UserAgent),
+			csvSafe(details),
+			entry.PrevHash,
+			entry.Hash,
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// VerifyChain recomputes every hash and checks each entry links to the one before it
+func (s *auditService) VerifyChain(ctx context.Context) (*models.AuditChainStatus, error) {
+	status := &models.AuditChainStatus{Valid: true}
+	prevHash := models.AuditGenesisHash
+	var afterID int64
+
+	for {
+		entries, err := s.auditRepo.ListChain(ctx, afterID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		brokenAt, checked, lastHash := verifyAuditChain(entries, prevHash)
+		status.EntriesChecked += int64(checked)
+		if brokenAt != nil {
+			status.Valid = false
+			status.BrokenAtID = brokenAt
+			return status, nil
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			return status, nil
+		}
+		prevHash = lastHash
+		afterID = entries[len(entries)-1].ID
+	}
+}
+
+// verifyAuditChain checks entries in chain order and returns the id of the first bad entry
+func verifyAuditChain(entries []models.AuditEntry, prevHash string) (*int64, int, string) {
+	for i := range entries {
+		entry := &entries[i]
+		if entry.PrevHash != prevHash || entry.ComputeHash() != entry.Hash {
+			id := entry.ID
+			return &id, i, prevHash
+		}
+		prevHash = entry.Hash
+	}
+	return nil, len(entries), prevHash
+}
+
+// csvSafe keeps spreadsheet programs from evaluating user-controlled cells as formulas
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func uuidOrEmpty(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+)
+
+func buildAuditChain(n int) []models.AuditEntry {
+	actor := uuid.New()
+	prevHash := models.AuditGenesisHash
+	entries := make([]models.AuditEntry, n)
+	for i := range entries {
+		entries[i] = models.AuditEntry{
+			ID:         int64(i + 1),
+			OccurredAt: time.Date(2026, 1, 1, 12, 0, i, 0, time.UTC),
+			EventType:  models.AuditEventLogin,
+			Outcome:    models.AuditOutcomeSuccess,
+			ActorID:    &actor,
+			TargetID:   &actor,
+			IPAddress:  "203.0.113.7",
+			Details:    map[string]string{"provider": "local"},
+			PrevHash:   prevHash,
+		}
+		entries[i].Hash = entries[i].ComputeHash()
+		prevHash = entries[i].Hash
+	}
+	return entries
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	entries := buildAuditChain(4)
+	if brokenAt, checked, _ := verifyAuditChain(entries, models.AuditGenesisHash); brokenAt != nil || checked != 4 {
+		t.Fatalf("intact chain reported broken at %v after %d entries", brokenAt, checked)
+	}
+
+	// Editing a field without recomputing the hash
+	tampered := buildAuditChain(4)
+	tampered[2].Outcome = models.AuditOutcomeFailure
+	if brokenAt, _, _ := verifyAuditChain(tampered, models.AuditGenesisHash); brokenAt == nil || *brokenAt != 3 {
+		t.Fatalf("edited entry: broken at %v, want 3", brokenAt)
+	}
+
+	// Re-sealing an edited entry still breaks the link to the next one
+	tampered[2].Hash = tampered[2].ComputeHash()
+	if brokenAt, _, _ := verifyAuditChain(tampered, models.AuditGenesisHash); brokenAt == nil || *brokenAt != 4 {
+		t.Fatalf("re-sealed entry: broken at %v, want 4", brokenAt)
+	}
+
+	// Deleting an entry from the middle
+	deleted := buildAuditChain(4)
+	deleted = append(deleted[:1], deleted[2:]...)
+	if brokenAt, _, _ := verifyAuditChain(deleted, models.AuditGenesisHash); brokenAt == nil || *brokenAt != 3 {
+		t.Fatalf("deleted entry: broken at %v, want 3", brokenAt)
+	}
+}
+
+func TestAuditHashIgnoresEmptyDetails(t *testing.T) {
+	entry := buildAuditChain(1)[0]
+	entry.Details = map[string]string{}
+	withEmpty := entry.ComputeHash()
+	entry.Details = nil
+	if entry.ComputeHash() != withEmpty {
+		t.Fatal("empty and missing details must hash the same")
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0":       "Mozilla/5.0",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"":                  "",
+	}
+	for in, want := range cases {
+		if got := csvSafe(in); got != want {
+			t.Fatalf("csvSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
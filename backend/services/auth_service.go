@@ -7,6 +7,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"log"
+	"net/mail"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,42 +27,147 @@ var (
 	ErrAccountLocked      = errors.New("حساب کاربری شما قفل شده است. لطفاً 30 دقیقه صبر کنید")
 	ErrAccountDeactivated = errors.New("حساب کاربری شما غیرفعال شده است")
 	ErrEmailExists        = errors.New("این ایمیل قبلاً ثبت شده است")
-	ErrWeakPassword       = errors.New("رمز عبور باید حداقل 8 کاراکتر و شامل حروف بزرگ، کوچک و اعداد باشد")
 	ErrInvalidToken       = errors.New("توکن نامعتبر یا منقضی شده است")
+	ErrTokenReused        = errors.New("استفاده مجدد از توکن شناسایی شد. لطفاً دوباره وارد شوید")
+
+	ErrInvalidTwoFactorCode     = errors.New("کد تأیید دو مرحله‌ای نادرست است")
+	ErrTwoFactorAlreadyEnabled  = errors.New("تأیید دو مرحله‌ای قبلاً فعال شده است")
+	ErrTwoFactorNotEnabled      = errors.New("تأیید دو مرحله‌ای فعال نیست")
+	ErrTwoFactorSetupNotStarted = errors.New("ابتدا فرایند فعال‌سازی تأیید دو مرحله‌ای را شروع کنید")
+
+	ErrSessionNotFound = errors.New("نشست یافت نشد")
+
+	ErrExternalAccount         = errors.New("رمز عبور این حساب توسط سرویس احراز هویت سازمانی مدیریت می‌شود")
+	ErrExternalEmailConflict   = errors.New("حسابی با این ایمیل وجود دارد و امکان اتصال خودکار آن به ورود یکپارچه نیست")
+	ErrInvalidExternalIdentity = errors.New("اطلاعات دریافتی از سرویس ورود یکپارچه ناقص است")
+
+	ErrEmailAlreadyVerified    = errors.New("ایمیل شما قبلاً تأیید شده است")
+	ErrVerificationRateLimited = errors.New("تعداد درخواست‌های ارسال ایمیل تأیید بیش از حد مجاز است. لطفاً بعداً دوباره تلاش کنید")
+	ErrExternalEmailChange     = errors.New("ایمیل این حساب توسط سرویس احراز هویت سازمانی مدیریت می‌شود")
+)
+
+// Two-factor login settings
+const (
+	twoFactorChallengeExpiry = 5 * time.Minute
+	recoveryCodeCount        = 10
+)
+
+// passwordChangeChallengeExpiry bounds how long an expired password can be replaced after login
+const passwordChangeChallengeExpiry = 10 * time.Minute
+
+// Email verification settings
+const (
+	emailVerificationExpiry      = 24 * time.Hour
+	emailVerificationResendLimit = 3
+	emailVerificationResendEvery = time.Hour
 )
 
+// TwoFactorRequiredError is returned by Login when the password is correct but
+// the account has 2FA enabled. The challenge token must be sent back with a code.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "تأیید دو مرحله‌ای لازم است"
+}
+
+// PasswordExpiredError is returned by Login when the password is correct but older
+// than the policy allows. The challenge token must be sent back with a new password.
+type PasswordExpiredError struct {
+	ChallengeToken string
+}
+
+func (e *PasswordExpiredError) Error() string {
+	return "رمز عبور شما منقضی شده است. لطفاً رمز عبور جدیدی انتخاب کنید"
+}
+
 type AuthService interface {
-	Register(ctx context.Context, req models.CreateUserRequest) (*models.User, string, string, error)
+	Register(ctx context.Context, req models.CreateUserRequest, userAgent, ipAddress string) (*models.User, string, string, error)
 	Login(ctx context.Context, req models.LoginRequest, userAgent, ipAddress string) (*models.User, string, string, error)
 	VerifyPassword(hashedPassword, password string) error
 	GenerateTokens(userID uuid.UUID, role string) (accessToken, refreshToken string, err error)
 	ValidateAccessToken(tokenString string) (*jwt.Token, error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	RefreshToken(ctx context.Context, refreshToken, userAgent, ipAddress string) (string, string, error)
 	HandleFailedLogin(ctx context.Context, userID uuid.UUID) error
 	RequestPasswordReset(ctx context.Context, email string) error
 	ResetPassword(ctx context.Context, token, newPassword string) error
 	RevokeSession(ctx context.Context, refreshToken string) error
 	UpdateProfile(ctx context.Context, userID uuid.UUID, req models.UpdateUserRequest) (*models.User, error)
-	ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordRequest) error
+	ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordRequest, userAgent, ipAddress string) (string, string, error)
+	ListSessions(ctx context.Context, userID uuid.UUID, currentRefreshToken string) ([]models.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentRefreshToken string) (int, error)
+	VerifyTwoFactorLogin(ctx context.Context, req models.TwoFactorLoginRequest, userAgent, ipAddress string) (*models.User, string, string, error)
+	ChangeExpiredPassword(ctx context.Context, req models.ExpiredPasswordChangeRequest, userAgent, ipAddress string) (*models.User, string, string, error)
+	GetPasswordPolicy() PasswordPolicy
+	GetTwoFactorStatus(ctx context.Context, userID uuid.UUID) (*models.TwoFactorStatus, error)
+	BeginTwoFactorSetup(ctx context.Context, userID uuid.UUID) (*models.TwoFactorSetup, error)
+	ConfirmTwoFactorSetup(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID, req models.DisableTwoFactorRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	LoginWithExternalIdentity(ctx context.Context, identity models.ExternalIdentity, userAgent, ipAddress string) (*models.User, string, string, error)
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerificationEmail(ctx context.Context, userID uuid.UUID) error
+	TwoFactorSetupRequired(ctx context.Context, user *models.User) bool
 }
 
 type authService struct {
 	userRepo          repositories.UserRepository
+	roleRepo          repositories.RoleRepository
 	sessionRepo       repositories.SessionRepository
 	passwordResetRepo repositories.PasswordResetRepository
+	recoveryCodeRepo  repositories.RecoveryCodeRepository
+	verificationRepo  repositories.EmailVerificationRepository
+	historyRepo       repositories.PasswordHistoryRepository
 	emailService      *EmailService
+	ldap              LDAPAuthenticator
+	policy            PasswordPolicy
+	auditService      AuditService
 }
 
-func NewAuthService(userRepo repositories.UserRepository, sessionRepo repositories.SessionRepository, passwordResetRepo repositories.PasswordResetRepository, emailService *EmailService) AuthService {
+func NewAuthService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, sessionRepo repositories.SessionRepository, passwordResetRepo repositories.PasswordResetRepository, recoveryCodeRepo repositories.RecoveryCodeRepository, verificationRepo repositories.EmailVerificationRepository, historyRepo repositories.PasswordHistoryRepository, emailService *EmailService, ldap LDAPAuthenticator, policy PasswordPolicy, auditService AuditService) AuthService {
 	return &authService{
 		userRepo:          userRepo,
+		roleRepo:          roleRepo,
 		sessionRepo:       sessionRepo,
 		passwordResetRepo: passwordResetRepo,
+		recoveryCodeRepo:  recoveryCodeRepo,
+		verificationRepo:  verificationRepo,
+		historyRepo:       historyRepo,
 		emailService:      emailService,
+		ldap:              ldap,
+		policy:            policy,
+		auditService:      auditService,
 	}
 }
 
-func (s *authService) Register(ctx context.Context, req models.CreateUserRequest) (*models.User, string, string, error) {
+// recordUserEvent audits an event that a user performed on their own account
+func (s *authService) recordUserEvent(ctx context.Context, eventType, outcome string, userID uuid.UUID, details map[string]string) {
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: eventType,
+		Outcome:   outcome,
+		ActorID:   &userID,
+		TargetID:  &userID,
+		Details:   details,
+	})
+}
+
+// recordFailedLogin audits a rejected sign-in; user is nil when no account matched
+func (s *authService) recordFailedLogin(ctx context.Context, identifier string, user *models.User, reason string) {
+	entry := models.AuditEntry{
+		EventType: models.AuditEventLogin,
+		Outcome:   models.AuditOutcomeFailure,
+		Details:   map[string]string{"identifier": identifier, "reason": reason},
+	}
+	if user != nil {
+		entry.ActorID = &user.ID
+		entry.TargetID = &user.ID
+	}
+	s.auditService.Record(ctx, entry)
+}
+
+func (s *authService) Register(ctx context.Context, req models.CreateUserRequest, userAgent, ipAddress string) (*models.User, string, string, error) {
 	// Validate input
 	if len(req.Username) < 3 || len(req.Username) > 50 {
 		return nil, "", "", errors.New("نام کاربری باید بین 3 تا 50 کاراکتر باشد")
@@ -73,8 +181,8 @@ func (s *authService) Register(ctx context.Context, req models.CreateUserRequest
 		return nil, "", "", errors.New("رمز عبور و تکرار آن مطابقت ندارند")
 	}
 
-	if !isStrongPassword(req.Password) {
-		return nil, "", "", ErrWeakPassword
+	if err := s.policy.Validate(req.Password); err != nil {
+		return nil, "", "", err
 	}
 
 	// Check if email already exists
@@ -85,7 +193,7 @@ func (s *authService) Register(ctx context.Context, req models.CreateUserRequest
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
+	hashedPassword, err := s.policy.Hash(req.Password)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -94,7 +202,7 @@ func (s *authService) Register(ctx context.Context, req models.CreateUserRequest
 	user := &models.User{
 		Username:     req.Username,
 		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Role:         "user",
 		IsActive:     true,
 	}
@@ -104,41 +212,115 @@ func (s *authService) Register(ctx context.Context, req models.CreateUserRequest
 		return nil, "", "", err
 	}
 
+	// The account stays unverified until the emailed link is opened
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		// Log error but don't fail the registration; the user can resend
+		fmt.Printf("Failed to send verification email: %v\n", err)
+	}
+
 	// Generate tokens
 	accessToken, refreshToken, err := s.GenerateTokens(user.ID, user.Role)
 	if err != nil {
 		return nil, "", "", err
 	}
 
+	// Store refresh token in sessions table
+	if err := s.createSession(ctx, user.ID, refreshToken, uuid.Nil, userAgent, ipAddress); err != nil {
+		return nil, "", "", err
+	}
+
 	return user, accessToken, refreshToken, nil
 }
 
 func (s *authService) Login(ctx context.Context, req models.LoginRequest, userAgent, ipAddress string) (*models.User, string, string, error) {
-	// Get user by email
-	req.Email = strings.ToLower(req.Email)
+	// Get user by email (with LDAP enabled, the directory username is accepted too)
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil && s.ldapEnabled() {
+		user, err = s.userRepo.GetByExternalSubject(ctx, models.AuthProviderLDAP, req.Email)
+	}
 	if err != nil {
+		if s.ldapEnabled() {
+			// Directory users are synced into users on their first login
+			return s.loginFirstTimeLDAPUser(ctx, req, userAgent, ipAddress)
+		}
+		s.recordFailedLogin(ctx, req.Email, nil, "unknown_account")
 		return nil, "", "", ErrInvalidCredentials
 	}
 
 	// Check if account is locked
 	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		s.recordFailedLogin(ctx, req.Email, user, "account_locked")
 		return nil, "", "", ErrAccountLocked
 	}
 
 	// Check if account is active
 	if !user.IsActive {
+		s.recordFailedLogin(ctx, req.Email, user, "account_deactivated")
 		return nil, "", "", ErrAccountDeactivated
 	}
 
-	// Verify password
-	err = s.VerifyPassword(user.PasswordHash, req.Password)
-	if err != nil {
-		// Handle failed login
-		s.HandleFailedLogin(ctx, user.ID)
-		return nil, "", "", ErrInvalidCredentials
+	// Verify password against bcrypt or the directory, depending on the account type
+	switch {
+	case user.AuthProvider == models.AuthProviderLDAP && user.ExternalSubject != nil && s.ldapEnabled():
+		identity, err := s.ldap.Authenticate(ctx, *user.ExternalSubject, req.Password)
+		if err == ErrLDAPInvalidCredentials {
+			// Handle failed login
+			s.recordFailedLogin(ctx, req.Email, user, "invalid_password")
+			s.HandleFailedLogin(ctx, user.ID)
+			return nil, "", "", ErrInvalidCredentials
+		}
+		if err != nil {
+			// Server outages must not lock accounts
+			log.Printf("LDAP authentication failed: %v", err)
+			return nil, "", "", ErrLDAPUnavailable
+		}
+		if identity.Role != "" && identity.Role != user.Role {
+			s.syncExternalRole(ctx, user, identity.Role)
+		}
+	case user.AuthProvider == models.AuthProviderLocal && user.HasPassword():
+		err = s.VerifyPassword(user.PasswordHash, req.Password)
+		if err != nil {
+			// Handle failed login
+			s.recordFailedLogin(ctx, req.Email, user, "invalid_password")
+			s.HandleFailedLogin(ctx, user.ID)
+			return nil, "", "", ErrInvalidCredentials
+		}
+
+		// An expired password must be replaced before any session is issued
+		if s.policy.Expired(user.PasswordChangedAt, time.Now()) {
+			challengeToken, err := s.generateChallenge(user.ID, "password_change", passwordChangeChallengeExpiry)
+			if err != nil {
+				return nil, "", "", err
+			}
+			return nil, "", "", &PasswordExpiredError{ChallengeToken: challengeToken}
+		}
+	default:
+		// SSO accounts have no local password
+		s.recordFailedLogin(ctx, req.Email, user, "external_account")
+		return nil, "", "", ErrExternalAccount
+	}
+
+	return s.finishPasswordLogin(ctx, user, userAgent, ipAddress)
+}
+
+// finishPasswordLogin runs after the password has been verified
+func (s *authService) finishPasswordLogin(ctx context.Context, user *models.User, userAgent, ipAddress string) (*models.User, string, string, error) {
+	// Accounts with 2FA only get a challenge token at this point. Failed attempts
+	// are not reset yet so wrong codes still count towards the lockout.
+	if user.TOTPEnabled {
+		challengeToken, err := s.generateChallenge(user.ID, "2fa_challenge", twoFactorChallengeExpiry)
+		if err != nil {
+			return nil, "", "", err
+		}
+		return nil, "", "", &TwoFactorRequiredError{ChallengeToken: challengeToken}
 	}
 
+	return s.completeLogin(ctx, user, userAgent, ipAddress)
+}
+
+// completeLogin resets lockout state, records the login and issues a new session
+func (s *authService) completeLogin(ctx context.Context, user *models.User, userAgent, ipAddress string) (*models.User, string, string, error) {
 	// Reset failed login attempts on successful login
 	if user.FailedLoginAttempts > 0 {
 		s.userRepo.UpdateFailedAttempts(ctx, user.ID, 0)
@@ -158,18 +340,28 @@ func (s *authService) Login(ctx context.Context, req models.LoginRequest, userAg
 	}
 
 	// Store refresh token in sessions table
-	refreshTokenHash := hashToken(refreshToken)
+	if err := s.createSession(ctx, user.ID, refreshToken, uuid.Nil, userAgent, ipAddress); err != nil {
+		return nil, "", "", err
+	}
+
+	s.recordUserEvent(ctx, models.AuditEventLogin, models.AuditOutcomeSuccess, user.ID, map[string]string{"provider": user.AuthProvider})
+
+	return user, accessToken, refreshToken, nil
+}
+
+// createSession stores a refresh token in the sessions table.
+// Pass uuid.Nil as familyID to start a new token family (fresh login).
+func (s *authService) createSession(ctx context.Context, userID uuid.UUID, refreshToken string, familyID uuid.UUID, userAgent, ipAddress string) error {
 	session := &models.Session{
-		UserID:           user.ID,
-		RefreshTokenHash: refreshTokenHash,
+		UserID:           userID,
+		FamilyID:         familyID,
+		RefreshTokenHash: hashToken(refreshToken),
 		UserAgent:        userAgent,
 		IPAddress:        ipAddress,
 		ExpiresAt:        time.Now().Add(config.JWTRefreshExpiry),
 		Revoked:          false,
 	}
-	s.sessionRepo.Create(ctx, session)
-
-	return user, accessToken, refreshToken, nil
+	return s.sessionRepo.Create(ctx, session)
 }
 
 func (s *authService) VerifyPassword(hashedPassword, password string) error {
@@ -186,22 +378,22 @@ func (s *authService) GenerateTokens(userID uuid.UUID, role string) (accessToken
 		"iat":     time.Now().Unix(),
 	}
 
-	accessTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessTokenObj.SignedString([]byte(config.JWTSecret))
+	accessToken, err = config.SignJWT(accessClaims)
 	if err != nil {
 		return "", "", err
 	}
 
 	// Generate refresh token (7 days)
+	// jti keeps tokens issued within the same second unique
 	refreshClaims := jwt.MapClaims{
 		"user_id": userID.String(),
+		"jti":     uuid.New().String(),
 		"type":    "refresh",
 		"exp":     time.Now().Add(config.JWTRefreshExpiry).Unix(),
 		"iat":     time.Now().Unix(),
 	}
 
-	refreshTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, err = refreshTokenObj.SignedString([]byte(config.JWTSecret))
+	refreshToken, err = config.SignJWT(refreshClaims)
 	if err != nil {
 		return "", "", err
 	}
@@ -210,13 +402,7 @@ func (s *authService) GenerateTokens(userID uuid.UUID, role string) (accessToken
 }
 
 func (s *authService) ValidateAccessToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Verify signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(config.JWTSecret), nil
-	})
+	token, err := jwt.Parse(tokenString, config.JWTKeyFunc)
 
 	if err != nil {
 		return nil, ErrInvalidToken
@@ -240,11 +426,12 @@ func (s *authService) ValidateAccessToken(tokenString string) (*jwt.Token, error
 	return token, nil
 }
 
-func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+// RefreshToken rotates a refresh token: the presented token is revoked and a new
+// session in the same token family is stored. Presenting a token that was already
+// rotated or revoked is treated as theft and revokes the whole family.
+func (s *authService) RefreshToken(ctx context.Context, refreshToken, userAgent, ipAddress string) (string, string, error) {
 	// Validate refresh token
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.JWTSecret), nil
-	})
+	token, err := jwt.Parse(refreshToken, config.JWTKeyFunc)
 
 	if err != nil || !token.Valid {
 		return "", "", ErrInvalidToken
@@ -261,10 +448,23 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (st
 		return "", "", ErrInvalidToken
 	}
 
-	// Check if session is revoked
+	// Look up the session for this token
 	refreshTokenHash := hashToken(refreshToken)
 	session, err := s.sessionRepo.GetByRefreshToken(ctx, refreshTokenHash)
-	if err != nil || session.Revoked || session.ExpiresAt.Before(time.Now()) {
+	if err != nil {
+		return "", "", ErrInvalidToken
+	}
+
+	// A revoked token being presented again means it leaked: revoke the family
+	if session.Revoked {
+		s.recordUserEvent(ctx, models.AuditEventRefreshTokenReuse, models.AuditOutcomeFailure, session.UserID, map[string]string{"session_id": session.ID.String()})
+		if err := s.sessionRepo.RevokeFamily(ctx, session.FamilyID); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrTokenReused
+	}
+
+	if session.ExpiresAt.Before(time.Now()) {
 		return "", "", ErrInvalidToken
 	}
 
@@ -275,7 +475,7 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (st
 	}
 
 	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
+	if err != nil || userID != session.UserID {
 		return "", "", ErrInvalidToken
 	}
 
@@ -284,14 +484,36 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (st
 		return "", "", ErrInvalidToken
 	}
 
+	// Deactivated users cannot keep their sessions alive
+	if !user.IsActive {
+		s.sessionRepo.RevokeFamily(ctx, session.FamilyID)
+		return "", "", ErrAccountDeactivated
+	}
+
+	// Revoke old refresh token. Losing this race to a concurrent request
+	// with the same token is also reuse.
+	rotated, err := s.sessionRepo.RevokeIfActive(ctx, refreshTokenHash)
+	if err != nil {
+		return "", "", err
+	}
+	if !rotated {
+		s.recordUserEvent(ctx, models.AuditEventRefreshTokenReuse, models.AuditOutcomeFailure, session.UserID, map[string]string{"session_id": session.ID.String()})
+		if err := s.sessionRepo.RevokeFamily(ctx, session.FamilyID); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrTokenReused
+	}
+
 	// Generate new tokens
 	newAccessToken, newRefreshToken, err := s.GenerateTokens(user.ID, user.Role)
 	if err != nil {
 		return "", "", err
 	}
 
-	// Revoke old refresh token
-	s.sessionRepo.Revoke(ctx, refreshTokenHash)
+	// Store the rotated refresh token in the same family
+	if err := s.createSession(ctx, user.ID, newRefreshToken, session.FamilyID, userAgent, ipAddress); err != nil {
+		return "", "", err
+	}
 
 	return newAccessToken, newRefreshToken, nil
 }
@@ -310,11 +532,323 @@ func (s *authService) HandleFailedLogin(ctx context.Context, userID uuid.UUID) e
 	if attempts >= 5 {
 		lockUntil := time.Now().Add(30 * time.Minute)
 		s.userRepo.LockAccount(ctx, userID, lockUntil)
+		s.auditService.Record(ctx, models.AuditEntry{
+			EventType: models.AuditEventAccountLocked,
+			Outcome:   models.AuditOutcomeSuccess,
+			TargetID:  &userID,
+			Details:   map[string]string{"failed_attempts": strconv.Itoa(attempts), "locked_until": lockUntil.UTC().Format(time.RFC3339)},
+		})
 	}
 
 	return nil
 }
 
+// VerifyTwoFactorLogin completes a login started with a 2FA challenge.
+// The code can be a TOTP code or an unused recovery code.
+func (s *authService) VerifyTwoFactorLogin(ctx context.Context, req models.TwoFactorLoginRequest, userAgent, ipAddress string) (*models.User, string, string, error) {
+	userID, err := s.parseChallenge(req.ChallengeToken, "2fa_challenge")
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", "", ErrInvalidToken
+	}
+
+	// Check if account is locked
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		s.recordFailedLogin(ctx, user.Email, user, "account_locked")
+		return nil, "", "", ErrAccountLocked
+	}
+
+	// Check if account is active
+	if !user.IsActive {
+		s.recordFailedLogin(ctx, user.Email, user, "account_deactivated")
+		return nil, "", "", ErrAccountDeactivated
+	}
+
+	if !user.TOTPEnabled {
+		return nil, "", "", ErrInvalidToken
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, req.Code)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if !ok {
+		s.recordFailedLogin(ctx, user.Email, user, "invalid_two_factor_code")
+		s.HandleFailedLogin(ctx, user.ID)
+		return nil, "", "", ErrInvalidTwoFactorCode
+	}
+
+	return s.completeLogin(ctx, user, userAgent, ipAddress)
+}
+
+// ChangeExpiredPassword replaces an expired password using the challenge from Login,
+// then continues the login (which may still require a 2FA code).
+func (s *authService) ChangeExpiredPassword(ctx context.Context, req models.ExpiredPasswordChangeRequest, userAgent, ipAddress string) (*models.User, string, string, error) {
+	userID, err := s.parseChallenge(req.ChallengeToken, "password_change")
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", "", ErrInvalidToken
+	}
+
+	if !user.IsActive {
+		return nil, "", "", ErrAccountDeactivated
+	}
+
+	// The challenge is only good for the password it was issued for
+	if !s.policy.Expired(user.PasswordChangedAt, time.Now()) {
+		return nil, "", "", ErrInvalidToken
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, "", "", err
+	}
+	s.recordUserEvent(ctx, models.AuditEventPasswordChanged, models.AuditOutcomeSuccess, user.ID, map[string]string{"reason": "expired"})
+
+	return s.finishPasswordLogin(ctx, user, userAgent, ipAddress)
+}
+
+// GetPasswordPolicy returns the active policy so clients can show the rules
+func (s *authService) GetPasswordPolicy() PasswordPolicy {
+	return s.policy
+}
+
+// TwoFactorSetupRequired reports whether an administrator still has to enable 2FA
+func (s *authService) TwoFactorSetupRequired(ctx context.Context, user *models.User) bool {
+	if user.TOTPEnabled {
+		return false
+	}
+	administrator, err := isAdministrator(ctx, s.roleRepo, user.Role)
+	if err != nil {
+		log.Printf("Failed to check the role of %s: %v", user.Email, err)
+		return false
+	}
+	return administrator
+}
+
+// GetTwoFactorStatus returns whether 2FA is enabled and how many recovery codes are left
+func (s *authService) GetTwoFactorStatus(ctx context.Context, userID uuid.UUID) (*models.TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("کاربر یافت نشد")
+	}
+
+	status := &models.TwoFactorStatus{Enabled: user.TOTPEnabled}
+	if user.TOTPEnabled {
+		status.RecoveryCodesRemaining, err = s.recoveryCodeRepo.CountUnused(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// BeginTwoFactorSetup generates a new pending TOTP secret for the user.
+// 2FA is not active until the secret is confirmed with a valid code.
+func (s *authService) BeginTwoFactorSetup(ctx context.Context, userID uuid.UUID) (*models.TwoFactorSetup, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("کاربر یافت نشد")
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateTOTP(ctx, userID, &secret, false); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(secret, user.Email),
+	}, nil
+}
+
+// ConfirmTwoFactorSetup enables 2FA once the user proves their authenticator works
+// and returns freshly generated recovery codes (shown only once)
+func (s *authService) ConfirmTwoFactorSetup(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("کاربر یافت نشد")
+	}
+
+	if user.TOTPEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if user.TOTPSecret == nil {
+		return nil, ErrTwoFactorSetupNotStarted
+	}
+
+	step, ok := verifyTOTP(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.userRepo.UpdateTOTP(ctx, userID, user.TOTPSecret, true); err != nil {
+		return nil, err
+	}
+
+	// The confirmation code must not be usable for a login right after
+	if _, err := s.userRepo.UpdateTOTPLastStep(ctx, userID, step); err != nil {
+		return nil, err
+	}
+	s.recordUserEvent(ctx, models.AuditEventTwoFactorEnabled, models.AuditOutcomeSuccess, userID, nil)
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+// DisableTwoFactor turns off 2FA after verifying the password and a current code
+func (s *authService) DisableTwoFactor(ctx context.Context, userID uuid.UUID, req models.DisableTwoFactorRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.New("کاربر یافت نشد")
+	}
+
+	if !user.TOTPEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	// Password-less (SSO) accounts confirm with the second factor only
+	if user.HasPassword() {
+		if err := s.VerifyPassword(user.PasswordHash, req.Password); err != nil {
+			return errors.New("رمز عبور فعلی نادرست است")
+		}
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := s.userRepo.UpdateTOTP(ctx, userID, nil, false); err != nil {
+		return err
+	}
+	s.recordUserEvent(ctx, models.AuditEventTwoFactorDisabled, models.AuditOutcomeSuccess, userID, nil)
+
+	return s.recoveryCodeRepo.DeleteByUser(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after verifying a current code
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("کاربر یافت نشد")
+	}
+
+	if !user.TOTPEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+// verifySecondFactor accepts a TOTP code (each time step only once) or an unused recovery code
+func (s *authService) verifySecondFactor(ctx context.Context, user *models.User, code string) (bool, error) {
+	if user.TOTPSecret != nil {
+		if step, ok := verifyTOTP(*user.TOTPSecret, code, time.Now()); ok {
+			return s.userRepo.UpdateTOTPLastStep(ctx, user.ID, step)
+		}
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	return s.recoveryCodeRepo.Use(ctx, user.ID, hashToken(normalized))
+}
+
+// issueRecoveryCodes generates new recovery codes, stores their hashes and returns the plain codes
+func (s *authService) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := s.recoveryCodeRepo.ReplaceForUser(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateChallenge issues a short-lived token proving the password step succeeded.
+// challengeType keeps 2FA and expired-password challenges from being swapped.
+func (s *authService) generateChallenge(userID uuid.UUID, challengeType string, expiry time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID.String(),
+		"jti":     uuid.New().String(),
+		"type":    challengeType,
+		"exp":     time.Now().Add(expiry).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+
+	return config.SignJWT(claims)
+}
+
+// parseChallenge validates a challenge token of the given type and returns its user ID
+func (s *authService) parseChallenge(tokenString, challengeType string) (uuid.UUID, error) {
+	token, err := jwt.Parse(tokenString, config.JWTKeyFunc)
+	if err != nil || !token.Valid {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	tokenType, ok := claims["type"].(string)
+	if !ok || tokenType != challengeType {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	return userID, nil
+}
+
 // RequestPasswordReset generates a password reset token and sends it via email
 func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
 	// Always return success to prevent email enumeration
@@ -325,6 +859,16 @@ func (s *authService) RequestPasswordReset(ctx context.Context, email string) er
 
 	// If user doesn't exist, silently succeed (security best practice)
 	if err != nil || user == nil {
+		s.auditService.Record(ctx, models.AuditEntry{
+			EventType: models.AuditEventPasswordResetRequest,
+			Outcome:   models.AuditOutcomeFailure,
+			Details:   map[string]string{"email": email, "reason": "unknown_account"},
+		})
+		return nil
+	}
+
+	// SSO accounts have no password to reset
+	if user.AuthProvider != models.AuthProviderLocal {
 		return nil
 	}
 
@@ -349,6 +893,11 @@ func (s *authService) RequestPasswordReset(ctx context.Context, email string) er
 	if err != nil {
 		return err
 	}
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventPasswordResetRequest,
+		Outcome:   models.AuditOutcomeSuccess,
+		TargetID:  &user.ID,
+	})
 
 	// Send email with plain token (not the hash)
 	err = s.emailService.SendPasswordResetEmail(user.Email, token)
@@ -362,36 +911,26 @@ func (s *authService) RequestPasswordReset(ctx context.Context, email string) er
 
 // ResetPassword validates token and updates user password
 func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
-	// Validate new password strength
-	if !isStrongPassword(newPassword) {
-		return ErrWeakPassword
-	}
-
 	// Hash the received token to match database
 	tokenHash := hashToken(token)
 
 	// Lookup token
 	resetToken, err := s.passwordResetRepo.GetByToken(ctx, tokenHash)
 	if err != nil || resetToken == nil {
+		s.auditService.Record(ctx, models.AuditEntry{
+			EventType: models.AuditEventPasswordReset,
+			Outcome:   models.AuditOutcomeFailure,
+			Details:   map[string]string{"reason": "unknown_token"},
+		})
 		return ErrInvalidToken
 	}
 
-	// Check if token is expired
-	if time.Now().After(resetToken.ExpiresAt) {
-		return ErrInvalidToken
-	}
-
-	// Check if token was already used
-	if resetToken.Used {
+	// Expired and already used tokens are rejected alike
+	if time.Now().After(resetToken.ExpiresAt) || resetToken.Used {
+		s.recordUserEvent(ctx, models.AuditEventPasswordReset, models.AuditOutcomeFailure, resetToken.UserID, map[string]string{"reason": "expired_or_used_token"})
 		return ErrInvalidToken
 	}
 
-	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
-	if err != nil {
-		return err
-	}
-
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, resetToken.UserID)
 	if err != nil || user == nil {
@@ -399,9 +938,7 @@ func (s *authService) ResetPassword(ctx context.Context, token, newPassword stri
 	}
 
 	// Update user password
-	user.PasswordHash = string(hashedPassword)
-	err = s.userRepo.Update(ctx, user)
-	if err != nil {
+	if err := s.setPassword(ctx, user, newPassword); err != nil {
 		return err
 	}
 
@@ -417,7 +954,11 @@ func (s *authService) ResetPassword(ctx context.Context, token, newPassword stri
 		return err
 	}
 
-	return nil
+	s.recordUserEvent(ctx, models.AuditEventPasswordReset, models.AuditOutcomeSuccess, user.ID, nil)
+
+	// Sign out every device that may have been using the old password
+	_, err = s.sessionRepo.RevokeAllByUser(ctx, user.ID, uuid.Nil)
+	return err
 }
 
 // RevokeSession revokes a session by marking it as revoked in the database
@@ -426,12 +967,21 @@ func (s *authService) RevokeSession(ctx context.Context, refreshToken string) er
 	tokenHash := hashToken(refreshToken)
 
 	// Revoke the session
-	return s.sessionRepo.Revoke(ctx, tokenHash)
+	if err := s.sessionRepo.Revoke(ctx, tokenHash); err != nil {
+		return err
+	}
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventLogout,
+		Outcome:   models.AuditOutcomeSuccess,
+	})
+	return nil
 }
 
-// UpdateProfile updates user profile information
+// UpdateProfile updates user profile information.
+// Changing the email address resets its verification and sends a new link.
 func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, req models.UpdateUserRequest) (*models.User, error) {
 	// Validate input
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
 	if req.Username == "" || req.Email == "" {
 		return nil, errors.New("نام کاربری و ایمیل نمی‌توانند خالی باشند")
 	}
@@ -440,17 +990,30 @@ func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, req m
 		return nil, errors.New("ایمیل نامعتبر است")
 	}
 
-	// Check if email is already taken by another user
-	existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
-	if err == nil && existingUser.ID != userID {
-		return nil, ErrEmailExists
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("کاربر یافت نشد")
 	}
 
-	// Update user
-	user := &models.User{
-		ID:       userID,
-		Username: req.Username,
-		Email:    req.Email,
+	emailChanged := req.Email != user.Email
+	if emailChanged {
+		// Directory and SSO addresses are synced from the provider
+		if user.AuthProvider != models.AuthProviderLocal {
+			return nil, ErrExternalEmailChange
+		}
+
+		// Check if email is already taken by another user
+		existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
+		if err == nil && existingUser.ID != userID {
+			return nil, ErrEmailExists
+		}
+	}
+
+	// Update user, keeping role, status and password as they are
+	user.Username = req.Username
+	user.Email = req.Email
+	if emailChanged {
+		user.EmailVerifiedAt = nil
 	}
 
 	err = s.userRepo.Update(ctx, user)
@@ -458,71 +1021,268 @@ func (s *authService) UpdateProfile(ctx context.Context, userID uuid.UUID, req m
 		return nil, err
 	}
 
+	if emailChanged {
+		if err := s.sendVerificationEmail(ctx, user); err != nil {
+			// Log error but don't fail the request; the user can resend
+			fmt.Printf("Failed to send verification email: %v\n", err)
+		}
+	}
+
 	// Return updated user
 	return s.userRepo.GetByID(ctx, userID)
 }
 
-// ChangePassword changes user password after verifying current password
-func (s *authService) ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordRequest) error {
-	// Validate input
-	if req.CurrentPassword == "" || req.NewPassword == "" {
-		return errors.New("رمز عبور فعلی و جدید نمی‌توانند خالی باشند")
+// VerifyEmail confirms the address a verification token was issued for
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	// Hash the received token to match database
+	tokenHash := hashToken(token)
+
+	// Lookup token
+	verification, err := s.verificationRepo.GetByToken(ctx, tokenHash)
+	if err != nil || verification == nil {
+		return ErrInvalidToken
 	}
 
-	if !isStrongPassword(req.NewPassword) {
-		return ErrWeakPassword
+	// Check if token is expired or was already used
+	if verification.Used || time.Now().After(verification.ExpiresAt) {
+		return ErrInvalidToken
 	}
 
-	// Get current user
+	// The address must still be the account's current one
+	verified, err := s.userRepo.MarkEmailVerified(ctx, verification.UserID, verification.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrInvalidToken
+	}
+
+	// Mark token as used
+	return s.verificationRepo.MarkAsUsed(ctx, tokenHash)
+}
+
+// ResendVerificationEmail issues a new verification link, replacing outstanding ones
+func (s *authService) ResendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return errors.New("کاربر یافت نشد")
 	}
 
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	// Limit how many emails a single account can trigger
+	sent, err := s.verificationRepo.CountCreatedSince(ctx, userID, time.Now().Add(-emailVerificationResendEvery))
+	if err != nil {
+		return err
+	}
+	if sent >= emailVerificationResendLimit {
+		return ErrVerificationRateLimited
+	}
+
+	if err := s.verificationRepo.InvalidateForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// sendVerificationEmail stores a new verification token for the user's current address and emails it
+func (s *authService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	// Generate cryptographically secure 32-byte token
+	token, err := generateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	verification := &models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(emailVerificationExpiry),
+		Used:      false,
+	}
+	if err := s.verificationRepo.Create(ctx, verification); err != nil {
+		return err
+	}
+
+	// Send email with plain token (not the hash)
+	return s.emailService.SendEmailVerificationEmail(user.Email, token)
+}
+
+// ChangePassword changes user password after verifying current password.
+// All existing sessions are revoked and a new session is issued for the calling device.
+func (s *authService) ChangePassword(ctx context.Context, userID uuid.UUID, req models.ChangePasswordRequest, userAgent, ipAddress string) (string, string, error) {
+	// Validate input
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return "", "", errors.New("رمز عبور فعلی و جدید نمی‌توانند خالی باشند")
+	}
+
+	// Get current user
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", "", errors.New("کاربر یافت نشد")
+	}
+
+	if user.AuthProvider != models.AuthProviderLocal || !user.HasPassword() {
+		return "", "", ErrExternalAccount
+	}
+
 	// Verify current password
 	if err := s.VerifyPassword(user.PasswordHash, req.CurrentPassword); err != nil {
-		return errors.New("رمز عبور فعلی نادرست است")
+		s.recordUserEvent(ctx, models.AuditEventPasswordChanged, models.AuditOutcomeFailure, user.ID, map[string]string{"reason": "invalid_password"})
+		return "", "", errors.New("رمز عبور فعلی نادرست است")
 	}
 
-	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	// Update password
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return "", "", err
+	}
+	s.recordUserEvent(ctx, models.AuditEventPasswordChanged, models.AuditOutcomeSuccess, user.ID, nil)
+
+	// Revoke every session, including the current one
+	if _, err := s.sessionRepo.RevokeAllByUser(ctx, userID, uuid.Nil); err != nil {
+		return "", "", err
+	}
+
+	// Keep the calling device signed in with a fresh session
+	accessToken, refreshToken, err := s.GenerateTokens(user.ID, user.Role)
 	if err != nil {
-		return errors.New("خطا در هش کردن رمز عبور")
+		return "", "", err
 	}
 
-	// Update password
-	user.PasswordHash = string(hashedPassword)
-	err = s.userRepo.Update(ctx, user)
-	return err
+	if err := s.createSession(ctx, user.ID, refreshToken, uuid.Nil, userAgent, ipAddress); err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
 }
 
-// Helper functions
+// setPassword validates a new password against the policy and recent passwords, then stores it.
+// The replaced hash is kept in the password history.
+func (s *authService) setPassword(ctx context.Context, user *models.User, newPassword string) error {
+	if err := s.policy.Validate(newPassword); err != nil {
+		return err
+	}
 
-func isValidEmail(email string) bool {
-	return strings.Contains(email, "@") && strings.Contains(email, ".")
+	reused, err := s.isRecentPassword(ctx, user, newPassword)
+	if err != nil {
+		return err
+	}
+	if reused {
+		return &PasswordPolicyError{Violations: []PasswordViolation{{
+			Code:    PasswordReused,
+			Message: fmt.Sprintf("رمز عبور جدید نباید با %d رمز عبور اخیر شما یکسان باشد", s.policy.HistorySize),
+		}}}
+	}
+
+	hashedPassword, err := s.policy.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	if s.policy.HistorySize > 1 && user.HasPassword() {
+		if err := s.historyRepo.Record(ctx, user.ID, user.PasswordHash, s.policy.HistorySize-1); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	user.PasswordHash = hashedPassword
+	user.PasswordChangedAt = &now
+	return nil
 }
 
-func isStrongPassword(password string) bool {
-	if len(password) < 8 {
-		return false
+// isRecentPassword compares the password with the current one and the stored history
+func (s *authService) isRecentPassword(ctx context.Context, user *models.User, password string) (bool, error) {
+	if s.policy.HistorySize <= 0 {
+		return false, nil
 	}
 
-	hasUpper := false
-	hasLower := false
-	hasDigit := false
+	hashes := []string{}
+	if user.HasPassword() {
+		hashes = append(hashes, user.PasswordHash)
+	}
+	if s.policy.HistorySize > 1 {
+		previous, err := s.historyRepo.ListRecent(ctx, user.ID, s.policy.HistorySize-1)
+		if err != nil {
+			return false, err
+		}
+		hashes = append(hashes, previous...)
+	}
 
-	for _, char := range password {
-		switch {
-		case 'A' <= char && char <= 'Z':
-			hasUpper = true
-		case 'a' <= char && char <= 'z':
-			hasLower = true
-		case '0' <= char && char <= '9':
-			hasDigit = true
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return true, nil
 		}
 	}
+	return false, nil
+}
 
-	return hasUpper && hasLower && hasDigit
+// ListSessions returns the user's active sessions, marking the one matching currentRefreshToken
+func (s *authService) ListSessions(ctx context.Context, userID uuid.UUID, currentRefreshToken string) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if currentRefreshToken != "" {
+		currentHash := hashToken(currentRefreshToken)
+		for i := range sessions {
+			sessions[i].IsCurrent = sessions[i].RefreshTokenHash == currentHash
+		}
+	}
+
+	return sessions, nil
+}
+
+// RevokeUserSession revokes one of the user's sessions (and its rotated tokens)
+func (s *authService) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	revoked, err := s.sessionRepo.RevokeByID(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions revokes all of the user's sessions except the current one
+func (s *authService) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentRefreshToken string) (int, error) {
+	keepFamily := uuid.Nil
+	if currentRefreshToken != "" {
+		current, err := s.sessionRepo.GetByRefreshToken(ctx, hashToken(currentRefreshToken))
+		if err == nil && current.UserID == userID && !current.Revoked {
+			keepFamily = current.FamilyID
+		}
+	}
+
+	// Without an identifiable current session there is nothing to keep
+	if keepFamily == uuid.Nil {
+		return 0, ErrSessionNotFound
+	}
+
+	return s.sessionRepo.RevokeAllByUser(ctx, userID, keepFamily)
+}
+
+// Helper functions
+
+// isValidEmail accepts a bare addr-spec (no display name) whose domain has a dot
+func isValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || address.Name != "" {
+		return false
+	}
+
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	return strings.Contains(domain, ".") && !strings.HasPrefix(domain, ".") && !strings.HasSuffix(domain, ".")
 }
 
 func hashToken(token string) string {
@@ -530,6 +1290,23 @@ func hashToken(token string) string {
 	return base64.StdEncoding.EncodeToString(hash[:])
 }
 
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 7)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(bytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode strips separators and case so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
 func generateSecureToken() (string, error) {
 	bytes := make([]byte, 32)
 	_, err := rand.Read(bytes)
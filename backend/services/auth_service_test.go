@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"project-management/config"
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+// fakeUserRepository keeps users in memory by ID
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) GetByExternalSubject(ctx context.Context, provider, subject string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.AuthProvider == provider && user.ExternalSubject != nil && *user.ExternalSubject == subject {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *fakeUserRepository) Update(ctx context.Context, user *models.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+// fakeSessionRepository keeps sessions in memory with the family semantics of the real repository.
+// beforeRevokeIfActive, when set, runs once before RevokeIfActive, e.g. to let a concurrent request in.
+type fakeSessionRepository struct {
+	repositories.SessionRepository
+	mu                   sync.Mutex
+	sessions             []*models.Session
+	beforeRevokeIfActive func()
+}
+
+func (r *fakeSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session.ID = uuid.New()
+	if session.FamilyID == uuid.Nil {
+		session.FamilyID = session.ID
+	}
+	copied := *session
+	r.sessions = append(r.sessions, &copied)
+	return nil
+}
+
+func (r *fakeSessionRepository) GetByRefreshToken(ctx context.Context, tokenHash string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, session := range r.sessions {
+		if session.RefreshTokenHash == tokenHash {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("session not found")
+}
+
+func (r *fakeSessionRepository) RevokeIfActive(ctx context.Context, tokenHash string) (bool, error) {
+	if hook := r.beforeRevokeIfActive; hook != nil {
+		r.beforeRevokeIfActive = nil
+		hook()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, session := range r.sessions {
+		if session.RefreshTokenHash == tokenHash && !session.Revoked {
+			session.Revoked = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeSessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID {
+			session.Revoked = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	active := []models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID && !session.Revoked && session.ExpiresAt.After(time.Now()) {
+			active = append(active, *session)
+		}
+	}
+	return active, nil
+}
+
+// RevokeByID revokes the session's family, only when the session belongs to the user
+func (r *fakeSessionRepository) RevokeByID(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	familyID := uuid.Nil
+	for _, session := range r.sessions {
+		if session.ID == sessionID && session.UserID == userID {
+			familyID = session.FamilyID
+		}
+	}
+	revoked := false
+	for _, session := range r.sessions {
+		if familyID != uuid.Nil && session.FamilyID == familyID && session.UserID == userID && !session.Revoked {
+			session.Revoked = true
+			revoked = true
+		}
+	}
+	return revoked, nil
+}
+
+func (r *fakeSessionRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID, exceptFamilyID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, session := range r.sessions {
+		if session.UserID == userID && !session.Revoked && session.FamilyID != exceptFamilyID {
+			session.Revoked = true
+			count++
+		}
+	}
+	return count, nil
+}
+
+// byToken returns the stored session of a refresh token
+func (r *fakeSessionRepository) byToken(t *testing.T, refreshToken string) *models.Session {
+	t.Helper()
+	session, err := r.GetByRefreshToken(context.Background(), hashToken(refreshToken))
+	if err != nil {
+		t.Fatalf("no session for the refresh token: %v", err)
+	}
+	return session
+}
+
+// fakeAuditService records the entries it is given
+type fakeAuditService struct {
+	AuditService
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+func (a *fakeAuditService) Record(ctx context.Context, entry models.AuditEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+}
+
+// newTestAuthService signs tokens with a test secret and keeps everything it stores in memory
+func newTestAuthService(t *testing.T, users ...*models.User) (*authService, *fakeUserRepository, *fakeSessionRepository) {
+	t.Helper()
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	t.Setenv("JWT_VERIFICATION_KEY_FILES", "")
+	t.Setenv("JWT_SECRET", "auth-service-test-secret")
+	if err := config.InitJWTKeys(); err != nil {
+		t.Fatalf("InitJWTKeys: %v", err)
+	}
+
+	userRepo := &fakeUserRepository{users: map[uuid.UUID]*models.User{}}
+	for _, user := range users {
+		userRepo.users[user.ID] = user
+	}
+	sessionRepo := &fakeSessionRepository{}
+	service := NewAuthService(userRepo, &fakeRoleRepository{}, sessionRepo, nil, nil, nil, nil, nil, nil, PasswordPolicy{}, &fakeAuditService{})
+	return service.(*authService), userRepo, sessionRepo
+}
+
+func TestExternalLoginKeepsCustomRole(t *testing.T) {
+	subject := "jdoe"
+	newExternalUser := func(role string) *models.User {
+		sub := subject + "-" + role
+		return &models.User{
+			ID:              uuid.New(),
+			Username:        sub,
+			Email:           sub + "@example.com",
+			Role:            role,
+			AuthProvider:    models.AuthProviderOIDC,
+			ExternalSubject: &sub,
+			IsActive:        true,
+		}
+	}
+	auditor := newExternalUser("auditor")
+	member := newExternalUser(models.SystemRoleUser)
+	service, userRepo, _ := newTestAuthService(t, auditor, member)
+	ctx := context.Background()
+
+	// The provider's groups map to the built-in roles only; they must not undo a role given in the app
+	for _, tc := range []struct {
+		user     *models.User
+		mapped   string
+		wantRole string
+	}{
+		{auditor, models.SystemRoleUser, "auditor"},
+		{member, models.SystemRoleAdmin, models.SystemRoleAdmin},
+	} {
+		identity := models.ExternalIdentity{
+			Provider: models.AuthProviderOIDC,
+			Subject:  *tc.user.ExternalSubject,
+			Email:    tc.user.Email,
+			Role:     tc.mapped,
+		}
+		user, _, _, err := service.LoginWithExternalIdentity(ctx, identity, "test", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("login as %s: %v", tc.user.Role, err)
+		}
+		if user.Role != tc.wantRole || userRepo.users[tc.user.ID].Role != tc.wantRole {
+			t.Fatalf("role after login with %s = %q (stored %q), want %q", tc.mapped, user.Role, userRepo.users[tc.user.ID].Role, tc.wantRole)
+		}
+	}
+}
+
+// signIn stores a new session for the user, as a login does, and returns its refresh token
+func signIn(t *testing.T, service *authService, user *models.User) string {
+	t.Helper()
+	_, refreshToken, err := service.GenerateTokens(user.ID, user.Role)
+	if err != nil {
+		t.Fatalf("GenerateTokens: %v", err)
+	}
+	if err := service.createSession(context.Background(), user.ID, refreshToken, uuid.Nil, "test", "127.0.0.1"); err != nil {
+		t.Fatalf("createSession: %v", err)
+	}
+	return refreshToken
+}
+
+func newTestUser() *models.User {
+	return &models.User{ID: uuid.New(), Username: "jdoe", Email: "jdoe@example.com", Role: models.SystemRoleUser, IsActive: true}
+}
+
+func TestRefreshTokenRotatesWithinFamily(t *testing.T) {
+	user := newTestUser()
+	service, _, sessions := newTestAuthService(t, user)
+	ctx := context.Background()
+
+	oldToken := signIn(t, service, user)
+	_, newToken, err := service.RefreshToken(ctx, oldToken, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if newToken == oldToken {
+		t.Fatal("refresh returned the presented token")
+	}
+
+	old, rotated := sessions.byToken(t, oldToken), sessions.byToken(t, newToken)
+	if !old.Revoked {
+		t.Error("rotated token is still active")
+	}
+	if rotated.Revoked || rotated.FamilyID != old.FamilyID || rotated.UserID != user.ID {
+		t.Errorf("new session = %+v, want an active session of user %s in family %s", rotated, user.ID, old.FamilyID)
+	}
+}
+
+func TestRefreshTokenReplayRevokesFamily(t *testing.T) {
+	user := newTestUser()
+	service, _, sessions := newTestAuthService(t, user)
+	ctx := context.Background()
+
+	stolen := signIn(t, service, user)
+	_, current, err := service.RefreshToken(ctx, stolen, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	otherDevice := signIn(t, service, user)
+
+	if _, _, err := service.RefreshToken(ctx, stolen, "test", "127.0.0.1"); err != ErrTokenReused {
+		t.Fatalf("replay of a rotated token: err = %v, want ErrTokenReused", err)
+	}
+	if !sessions.byToken(t, current).Revoked {
+		t.Error("the family's current token survived the replay")
+	}
+	if _, _, err := service.RefreshToken(ctx, current, "test", "127.0.0.1"); err != ErrTokenReused {
+		t.Errorf("refresh with the revoked current token: err = %v, want ErrTokenReused", err)
+	}
+	if sessions.byToken(t, otherDevice).Revoked {
+		t.Error("a session of another family was revoked")
+	}
+}
+
+func TestRefreshTokenConcurrentRefreshCountsAsReuse(t *testing.T) {
+	user := newTestUser()
+	service, _, sessions := newTestAuthService(t, user)
+	ctx := context.Background()
+
+	token := signIn(t, service, user)
+
+	// The second request passes every check, then loses the revocation to the first
+	var winner string
+	sessions.beforeRevokeIfActive = func() {
+		var err error
+		if _, winner, err = service.RefreshToken(ctx, token, "test", "127.0.0.1"); err != nil {
+			t.Errorf("first refresh: %v", err)
+		}
+	}
+	if _, _, err := service.RefreshToken(ctx, token, "test", "127.0.0.1"); err != ErrTokenReused {
+		t.Fatalf("losing refresh: err = %v, want ErrTokenReused", err)
+	}
+	if winner == "" {
+		t.Fatal("first refresh did not run")
+	}
+	if !sessions.byToken(t, winner).Revoked {
+		t.Error("the winning refresh's token survived the reuse")
+	}
+}
+
+func TestListSessionsMarksCurrent(t *testing.T) {
+	user := newTestUser()
+	service, _, sessions := newTestAuthService(t, user)
+	ctx := context.Background()
+
+	current := signIn(t, service, user)
+	other := signIn(t, service, user)
+	signIn(t, service, newTestUser())
+
+	list, err := service.ListSessions(ctx, user.ID, current)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("listed %d sessions, want the user's 2", len(list))
+	}
+	for _, session := range list {
+		if want := session.ID == sessions.byToken(t, current).ID; session.IsCurrent != want {
+			t.Errorf("session %s is_current = %v, want %v", session.ID, session.IsCurrent, want)
+		}
+	}
+
+	if err := service.RevokeUserSession(ctx, user.ID, sessions.byToken(t, other).ID); err != nil {
+		t.Fatalf("RevokeUserSession: %v", err)
+	}
+	if list, _ = service.ListSessions(ctx, user.ID, current); len(list) != 1 || !list[0].IsCurrent {
+		t.Errorf("sessions after revoking the other = %+v, want only the current one", list)
+	}
+}
+
+func TestRevokeUserSessionRevokesFamily(t *testing.T) {
+	user := newTestUser()
+	service, _, sessions := newTestAuthService(t, user)
+	ctx := context.Background()
+
+	first := signIn(t, service, user)
+	_, rotated, err := service.RefreshToken(ctx, first, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	kept := signIn(t, service, user)
+
+	// Revoking the device by its first session also ends the token it was rotated to
+	if err := service.RevokeUserSession(ctx, user.ID, sessions.byToken(t, first).ID); err != nil {
+		t.Fatalf("RevokeUserSession: %v", err)
+	}
+	if !sessions.byToken(t, rotated).Revoked {
+		t.Error("the rotated token of the revoked session is still active")
+	}
+	if sessions.byToken(t, kept).Revoked {
+		t.Error("another session of the user was revoked")
+	}
+	if err := service.RevokeUserSession(ctx, user.ID, sessions.byToken(t, rotated).ID); err != ErrSessionNotFound {
+		t.Errorf("revoking an already revoked session: err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestRevokeUserSessionOfAnotherUser(t *testing.T) {
+	user, other := newTestUser(), newTestUser()
+	service, _, sessions := newTestAuthService(t, user, other)
+	ctx := context.Background()
+
+	signIn(t, service, user)
+	victim := signIn(t, service, other)
+
+	if err := service.RevokeUserSession(ctx, user.ID, sessions.byToken(t, victim).ID); err != ErrSessionNotFound {
+		t.Fatalf("revoking another user's session: err = %v, want ErrSessionNotFound", err)
+	}
+	if sessions.byToken(t, victim).Revoked {
+		t.Error("another user's session was revoked")
+	}
+}
+
+func TestRevokeOtherSessionsKeepsCurrentFamily(t *testing.T) {
+	user, other := newTestUser(), newTestUser()
+	service, _, sessions := newTestAuthService(t, user, other)
+	ctx := context.Background()
+
+	first := signIn(t, service, user)
+	_, current, err := service.RefreshToken(ctx, first, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	laptop := signIn(t, service, user)
+	phone := signIn(t, service, user)
+	stranger := signIn(t, service, other)
+
+	count, err := service.RevokeOtherSessions(ctx, user.ID, current)
+	if err != nil {
+		t.Fatalf("RevokeOtherSessions: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("revoked %d sessions, want 2", count)
+	}
+	if sessions.byToken(t, current).Revoked {
+		t.Error("the current session was revoked")
+	}
+	for name, token := range map[string]string{"laptop": laptop, "phone": phone} {
+		if !sessions.byToken(t, token).Revoked {
+			t.Errorf("%s session is still active", name)
+		}
+	}
+	if sessions.byToken(t, stranger).Revoked {
+		t.Error("another user's session was revoked")
+	}
+
+	// Someone else's token does not identify a current session of this user
+	if _, err := service.RevokeOtherSessions(ctx, user.ID, stranger); err != ErrSessionNotFound {
+		t.Errorf("with another user's token: err = %v, want ErrSessionNotFound", err)
+	}
+}
@@ -12,15 +12,18 @@ import (
 var (
 	ErrCommentNotFound     = errors.New("کامنت یافت نشد")
 	ErrCommentUnauthorized = errors.New("شما مجوز ویرایش یا حذف این کامنت را ندارید")
+	ErrCommentForbidden    = errors.New("نقش شما در این پروژه اجازه ثبت کامنت را نمی‌دهد")
+	ErrCommentReadOnly     = errors.New("این پروژه فقط خواندنی است و کامنت‌های آن قابل تغییر نیست")
 )
 
 type CommentService struct {
 	repo     *repositories.CommentRepository
 	taskRepo *repositories.TaskRepository
+	access   *ProjectAccess
 }
 
-func NewCommentService(repo *repositories.CommentRepository, taskRepo *repositories.TaskRepository) *CommentService {
-	return &CommentService{repo: repo, taskRepo: taskRepo}
+func NewCommentService(repo *repositories.CommentRepository, taskRepo *repositories.TaskRepository, access *ProjectAccess) *CommentService {
+	return &CommentService{repo: repo, taskRepo: taskRepo, access: access}
 }
 
 func (s *CommentService) GetCommentsByTaskID(ctx context.Context, taskID uuid.UUID) ([]models.Comment, error) {
@@ -31,10 +34,10 @@ func (s *CommentService) GetCommentsByTaskID(ctx context.Context, taskID uuid.UU
 	return s.repo.GetByTaskID(ctx, taskID)
 }
 
-func (s *CommentService) GetCommentsByTaskIDWithUser(ctx context.Context, taskID uuid.UUID) ([]models.CommentWithUser, error) {
-	task, err := s.taskRepo.GetByID(ctx, taskID)
-	if err != nil || task == nil {
-		return nil, ErrCommentNotFound
+// GetCommentsByTaskIDWithUser returns the task's comments when the user can see its project
+func (s *CommentService) GetCommentsByTaskIDWithUser(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, role string) ([]models.CommentWithUser, error) {
+	if _, err := s.access.TaskRole(ctx, taskID, userID, role); err != nil {
+		return nil, commentAccessError(err)
 	}
 	return s.repo.GetByTaskIDWithUser(ctx, taskID)
 }
@@ -43,13 +46,12 @@ func (s *CommentService) GetCommentByID(ctx context.Context, id uuid.UUID) (*mod
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *CommentService) CreateComment(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, req models.CreateCommentRequest) (*models.CommentWithUser, error) {
+func (s *CommentService) CreateComment(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, role string, req models.CreateCommentRequest) (*models.CommentWithUser, error) {
 	if req.Content == "" {
 		return nil, errors.New("متن کامنت نمی‌تواند خالی باشد")
 	}
-	task, err := s.taskRepo.GetByID(ctx, taskID)
-	if err != nil || task == nil {
-		return nil, ErrCommentNotFound
+	if _, err := s.access.RequireTask(ctx, taskID, userID, role, actionComment); err != nil {
+		return nil, commentAccessError(err)
 	}
 	comment, err := s.repo.Create(ctx, taskID, userID, req)
 	if err != nil {
@@ -59,7 +61,8 @@ func (s *CommentService) CreateComment(ctx context.Context, taskID uuid.UUID, us
 	return s.repo.GetByIDWithUser(ctx, comment.ID)
 }
 
-func (s *CommentService) UpdateComment(ctx context.Context, id uuid.UUID, userID uuid.UUID, req models.UpdateCommentRequest) (*models.CommentWithUser, error) {
+// UpdateComment lets authors edit their own comments while they can still comment on the project
+func (s *CommentService) UpdateComment(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string, req models.UpdateCommentRequest) (*models.CommentWithUser, error) {
 	if req.Content == "" {
 		return nil, errors.New("متن کامنت نمی‌تواند خالی باشد")
 	}
@@ -67,6 +70,9 @@ func (s *CommentService) UpdateComment(ctx context.Context, id uuid.UUID, userID
 	if err != nil || comment == nil {
 		return nil, ErrCommentNotFound
 	}
+	if _, err := s.access.RequireTask(ctx, comment.TaskID, userID, role, actionComment); err != nil {
+		return nil, commentAccessError(err)
+	}
 	if comment.UserID != userID {
 		return nil, ErrCommentUnauthorized
 	}
@@ -78,13 +84,41 @@ func (s *CommentService) UpdateComment(ctx context.Context, id uuid.UUID, userID
 	return s.repo.GetByIDWithUser(ctx, id)
 }
 
-func (s *CommentService) DeleteComment(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+// DeleteComment lets authors delete their own comments and project managers delete any
+func (s *CommentService) DeleteComment(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) error {
 	comment, err := s.repo.GetByID(ctx, id)
 	if err != nil || comment == nil {
 		return ErrCommentNotFound
 	}
+	projectRole, err := s.access.TaskRole(ctx, comment.TaskID, userID, role)
+	if err != nil {
+		return commentAccessError(err)
+	}
+	if err := s.access.RequireWritableTask(ctx, comment.TaskID); err != nil {
+		return commentAccessError(err)
+	}
 	if comment.UserID != userID {
-		return ErrCommentUnauthorized
+		allowed, err := s.access.Allows(ctx, projectRole, role, actionModerate)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrCommentUnauthorized
+		}
 	}
 	return s.repo.Delete(ctx, id)
 }
+
+// commentAccessError maps project access failures to the comment errors handlers understand
+func commentAccessError(err error) error {
+	switch err {
+	case models.ErrNotFound:
+		return ErrCommentNotFound
+	case ErrProjectForbidden:
+		return ErrCommentForbidden
+	case ErrProjectReadOnly:
+		return ErrCommentReadOnly
+	default:
+		return err
+	}
+}
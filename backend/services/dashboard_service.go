@@ -11,12 +11,14 @@ import (
 type DashboardService struct {
 	dashboardRepo *repositories.DashboardRepository
 	meetingRepo   *repositories.MeetingRepository
+	roleRepo      repositories.RoleRepository
 }
 
-func NewDashboardService(dashboardRepo *repositories.DashboardRepository, meetingRepo *repositories.MeetingRepository) *DashboardService {
+func NewDashboardService(dashboardRepo *repositories.DashboardRepository, meetingRepo *repositories.MeetingRepository, roleRepo repositories.RoleRepository) *DashboardService {
 	return &DashboardService{
 		dashboardRepo: dashboardRepo,
 		meetingRepo:   meetingRepo,
+		roleRepo:      roleRepo,
 	}
 }
 
@@ -24,8 +26,12 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, userID uuid.UUI
 	var resp models.DashboardResponse
 	var err error
 
-	// 1. Get Statistics
-	resp.Statistics, err = s.dashboardRepo.GetStatistics(ctx, userID, userRole)
+	// 1. Get Statistics; team figures are for those who manage users
+	includeTeam, err := s.roleRepo.HasPermission(ctx, userRole, models.PermissionUserManage)
+	if err != nil {
+		return nil, err
+	}
+	resp.Statistics, err = s.dashboardRepo.GetStatistics(ctx, userID, userRole, includeTeam)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +43,7 @@ func (s *DashboardService) GetDashboardData(ctx context.Context, userID uuid.UUI
 	}
 
 	// 3. Get User Tasks (limit 5)
-	resp.UserTasks, err = s.dashboardRepo.GetUserTasks(ctx, userID, 5)
+	resp.UserTasks, err = s.dashboardRepo.GetUserTasks(ctx, userID, userRole, 5)
 	if err != nil {
 		return nil, err
 	}
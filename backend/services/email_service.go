@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"html"
 	"net/smtp"
 	"os"
 	"strings"
@@ -151,6 +152,252 @@ func (s *EmailService) getPasswordResetEmailTemplate(resetLink string) string {
 	return fmt.Sprintf(template, resetLink, resetLink)
 }
 
+// SendEmailVerificationEmail sends the address confirmation email with Persian template
+func (s *EmailService) SendEmailVerificationEmail(to, token string) error {
+	verifyLink := fmt.Sprintf("%s/#/verify-email?token=%s", s.appURL, token)
+
+	subject := "تأیید آدرس ایمیل"
+	body := s.getEmailVerificationTemplate(verifyLink)
+
+	return s.SendEmail(to, subject, body)
+}
+
+// getEmailVerificationTemplate returns Persian RTL email template
+func (s *EmailService) getEmailVerificationTemplate(verifyLink string) string {
+	template := `
+<!DOCTYPE html>
+<html dir="rtl" lang="fa">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Tahoma, Arial, sans-serif;
+            direction: rtl;
+            text-align: right;
+            background-color: #f4f4f4;
+            padding: 20px;
+        }
+        .container {
+            max-width: 600px;
+            margin: 0 auto;
+            background-color: #ffffff;
+            padding: 30px;
+            border-radius: 10px;
+            box-shadow: 0 2px 5px rgba(0,0,0,0.1);
+        }
+        h2 {
+            color: #333333;
+            margin-bottom: 20px;
+        }
+        p {
+            color: #555555;
+            line-height: 1.6;
+            margin-bottom: 15px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 30px;
+            background-color: #3b82f6;
+            color: #ffffff;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            margin-top: 30px;
+            padding-top: 20px;
+            border-top: 1px solid #eeeeee;
+            color: #999999;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h2>تأیید آدرس ایمیل</h2>
+        <p>سلام،</p>
+        <p>برای تأیید آدرس ایمیل حساب کاربری خود، روی دکمه زیر کلیک کنید:</p>
+        <p style="text-align: center;">
+            <a href="%s" class="button">تأیید ایمیل</a>
+        </p>
+        <p>یا می‌توانید لینک زیر را کپی کرده و در مرورگر خود باز کنید:</p>
+        <p style="word-break: break-all; background-color: #f9fafb; padding: 10px; border-radius: 5px;">%s</p>
+        <p>این لینک تا 24 ساعت معتبر است. اگر شما این حساب را ایجاد نکرده‌اید، این ایمیل را نادیده بگیرید.</p>
+        <div class="footer">
+            <p>این ایمیل به صورت خودکار ارسال شده است. لطفاً به آن پاسخ ندهید.</p>
+        </div>
+    </div>
+</body>
+</html>
+`
+	return fmt.Sprintf(template, verifyLink, verifyLink)
+}
+
+// SendInvitationEmail sends an account invitation email with Persian template
+func (s *EmailService) SendInvitationEmail(to, token string) error {
+	acceptLink := fmt.Sprintf("%s/#/accept-invite?token=%s", s.appURL, token)
+
+	subject := "دعوت به سامانه مدیریت پروژه"
+	body := s.getInvitationTemplate(acceptLink)
+
+	return s.SendEmail(to, subject, body)
+}
+
+// getInvitationTemplate returns Persian RTL email template
+func (s *EmailService) getInvitationTemplate(acceptLink string) string {
+	template := `
+<!DOCTYPE html>
+<html dir="rtl" lang="fa">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Tahoma, Arial, sans-serif;
+            direction: rtl;
+            text-align: right;
+            background-color: #f4f4f4;
+            padding: 20px;
+        }
+        .container {
+            max-width: 600px;
+            margin: 0 auto;
+            background-color: #ffffff;
+            padding: 30px;
+            border-radius: 10px;
+            box-shadow: 0 2px 5px rgba(0,0,0,0.1);
+        }
+        h2 {
+            color: #333333;
+            margin-bottom: 20px;
+        }
+        p {
+            color: #555555;
+            line-height: 1.6;
+            margin-bottom: 15px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 30px;
+            background-color: #3b82f6;
+            color: #ffffff;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            margin-top: 30px;
+            padding-top: 20px;
+            border-top: 1px solid #eeeeee;
+            color: #999999;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h2>دعوت به سامانه مدیریت پروژه</h2>
+        <p>سلام،</p>
+        <p>شما به سامانه مدیریت پروژه دعوت شده‌اید. برای انتخاب نام کاربری و رمز عبور و فعال‌سازی حساب خود، روی دکمه زیر کلیک کنید:</p>
+        <p style="text-align: center;">
+            <a href="%s" class="button">پذیرش دعوت</a>
+        </p>
+        <p>یا می‌توانید لینک زیر را کپی کرده و در مرورگر خود باز کنید:</p>
+        <p style="word-break: break-all; background-color: #f9fafb; padding: 10px; border-radius: 5px;">%s</p>
+        <p>این لینک تا 7 روز معتبر است و فقط یک بار قابل استفاده است. اگر انتظار این دعوت را نداشتید، این ایمیل را نادیده بگیرید.</p>
+        <div class="footer">
+            <p>این ایمیل به صورت خودکار ارسال شده است. لطفاً به آن پاسخ ندهید.</p>
+        </div>
+    </div>
+</body>
+</html>
+`
+	return fmt.Sprintf(template, acceptLink, acceptLink)
+}
+
+// SendTaskAssignedEmail tells a user that a task was assigned to them or to one of their groups
+func (s *EmailService) SendTaskAssignedEmail(to, taskTitle, projectTitle, groupName string) error {
+	dashboardLink := fmt.Sprintf("%s/#/dashboard", s.appURL)
+
+	// Titles are user input; keep them from breaking out of the Subject header
+	subject := "وظیفه جدید: " + strings.NewReplacer("\r", " ", "\n", " ").Replace(taskTitle)
+	body := s.getTaskAssignedTemplate(taskTitle, projectTitle, groupName, dashboardLink)
+
+	return s.SendEmail(to, subject, body)
+}
+
+// getTaskAssignedTemplate returns Persian RTL email template
+func (s *EmailService) getTaskAssignedTemplate(taskTitle, projectTitle, groupName, dashboardLink string) string {
+	assignedTo := "شما"
+	if groupName != "" {
+		assignedTo = fmt.Sprintf("گروه «%s» که شما عضو آن هستید", html.EscapeString(groupName))
+	}
+
+	template := `
+<!DOCTYPE html>
+<html dir="rtl" lang="fa">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body {
+            font-family: Tahoma, Arial, sans-serif;
+            direction: rtl;
+            text-align: right;
+            background-color: #f4f4f4;
+            padding: 20px;
+        }
+        .container {
+            max-width: 600px;
+            margin: 0 auto;
+            background-color: #ffffff;
+            padding: 30px;
+            border-radius: 10px;
+            box-shadow: 0 2px 5px rgba(0,0,0,0.1);
+        }
+        h2 {
+            color: #333333;
+            margin-bottom: 20px;
+        }
+        p {
+            color: #555555;
+            line-height: 1.6;
+            margin-bottom: 15px;
+        }
+        .button {
+            display: inline-block;
+            padding: 12px 30px;
+            background-color: #3b82f6;
+            color: #ffffff;
+            text-decoration: none;
+            border-radius: 5px;
+            margin: 20px 0;
+        }
+        .footer {
+            margin-top: 30px;
+            padding-top: 20px;
+            border-top: 1px solid #eeeeee;
+            color: #999999;
+            font-size: 12px;
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h2>وظیفه جدید</h2>
+        <p>سلام،</p>
+        <p>وظیفه «%s» در پروژه «%s» به %s واگذار شد.</p>
+        <p style="text-align: center;">
+            <a href="%s" class="button">مشاهده داشبورد</a>
+        </p>
+        <div class="footer">
+            <p>این ایمیل به صورت خودکار ارسال شده است. لطفاً به آن پاسخ ندهید.</p>
+        </div>
+    </div>
+</body>
+</html>
+`
+	return fmt.Sprintf(template, html.EscapeString(taskTitle), html.EscapeString(projectTitle), assignedTo, dashboardLink)
+}
+
 // getEnv gets environment variable with default value
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
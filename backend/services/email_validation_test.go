@@ -0,0 +1,29 @@
+package services
+
+import "testing"
+
+func TestIsValidEmail(t *testing.T) {
+	cases := []struct {
+		in   string
+		want bool
+	}{
+		{"user@example.com", true},
+		{"first.last+tag@mail.example.ir", true},
+		{"user@localhost", false},
+		{"user@example.", false},
+		{"user@.example.com", false},
+		{"@example.com", false},
+		{"user.example.com", false},
+		{"a@b@example.com", false},
+		{"Jane <jane@example.com>", false},
+		{" user@example.com", false},
+		{"user @example.com", false},
+		{"", false},
+	}
+
+	for _, tc := range cases {
+		if got := isValidEmail(tc.in); got != tc.want {
+			t.Fatalf("isValidEmail(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"project-management/models"
+)
+
+const maxUsernameLength = 50
+
+// LoginWithExternalIdentity signs in a user authenticated by an external provider (SSO).
+// Unknown identities are provisioned just-in-time; the role is synced from the provider
+// on every login when it sends group information. Local 2FA is not applied because the
+// provider is responsible for the authentication strength.
+func (s *authService) LoginWithExternalIdentity(ctx context.Context, identity models.ExternalIdentity, userAgent, ipAddress string) (*models.User, string, string, error) {
+	identity.Email = strings.ToLower(strings.TrimSpace(identity.Email))
+	if identity.Provider == "" || identity.Subject == "" || !isValidEmail(identity.Email) {
+		return nil, "", "", ErrInvalidExternalIdentity
+	}
+
+	user, err := s.userRepo.GetByExternalSubject(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		user, err = s.provisionExternalUser(ctx, identity)
+		if err != nil {
+			return nil, "", "", err
+		}
+	} else if identity.Role != "" && identity.Role != user.Role {
+		s.syncExternalRole(ctx, user, identity.Role)
+	}
+
+	if !user.IsActive {
+		s.recordFailedLogin(ctx, identity.Email, user, "account_deactivated")
+		return nil, "", "", ErrAccountDeactivated
+	}
+
+	// completeLogin persists the (possibly updated) role together with last_login_at
+	return s.completeLogin(ctx, user, userAgent, ipAddress)
+}
+
+// provisionExternalUser links an existing account by verified email or creates a password-less one
+func (s *authService) provisionExternalUser(ctx context.Context, identity models.ExternalIdentity) (*models.User, error) {
+	existing, _ := s.userRepo.GetByEmail(ctx, identity.Email)
+	if existing != nil {
+		// Only link when the provider vouches for the address and the account is not tied elsewhere
+		if !identity.EmailVerified || existing.ExternalSubject != nil {
+			return nil, ErrExternalEmailConflict
+		}
+
+		if err := s.userRepo.LinkExternalIdentity(ctx, existing.ID, identity.Provider, identity.Subject); err != nil {
+			return nil, err
+		}
+		existing.AuthProvider = identity.Provider
+		existing.ExternalSubject = &identity.Subject
+
+		// The provider vouches for the address, so a pending verification is settled
+		if !existing.IsEmailVerified() {
+			if _, err := s.userRepo.MarkEmailVerified(ctx, existing.ID, existing.Email); err != nil {
+				return nil, err
+			}
+			now := time.Now()
+			existing.EmailVerifiedAt = &now
+		}
+
+		if identity.Role != "" && identity.Role != existing.Role {
+			s.syncExternalRole(ctx, existing, identity.Role)
+		}
+		return existing, nil
+	}
+
+	username, err := s.availableUsername(ctx, identity.Username, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	role := identity.Role
+	if role == "" {
+		role = models.SystemRoleUser
+	}
+
+	subject := identity.Subject
+	var verifiedAt *time.Time
+	if identity.EmailVerified {
+		now := time.Now()
+		verifiedAt = &now
+	}
+
+	user := &models.User{
+		Username:        username,
+		Email:           identity.Email,
+		EmailVerifiedAt: verifiedAt,
+		Role:            role,
+		AuthProvider:    identity.Provider,
+		ExternalSubject: &subject,
+		IsActive:        true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// syncExternalRole applies the provider's role, but never demotes the last active admin.
+// Providers only map groups to the built-in roles, so a custom role given in the app is kept.
+func (s *authService) syncExternalRole(ctx context.Context, user *models.User, role string) {
+	if user.Role != models.SystemRoleAdmin && user.Role != models.SystemRoleUser {
+		return
+	}
+
+	wasAdministrator, err := isAdministrator(ctx, s.roleRepo, user.Role)
+	if err != nil {
+		log.Printf("Keeping role for %s: %v", user.Email, err)
+		return
+	}
+	staysAdministrator, err := isAdministrator(ctx, s.roleRepo, role)
+	if err != nil {
+		log.Printf("Keeping role for %s: %v", user.Email, err)
+		return
+	}
+	if wasAdministrator && !staysAdministrator {
+		count, err := s.userRepo.CountActiveWithPermission(ctx, models.PermissionRoleManage)
+		if err != nil || count <= 1 {
+			log.Printf("Keeping admin role for %s: cannot demote the last active admin", user.Email)
+			return
+		}
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventRoleChanged,
+		Outcome:   models.AuditOutcomeSuccess,
+		TargetID:  &user.ID,
+		Details:   map[string]string{"from": user.Role, "to": role, "source": user.AuthProvider},
+	})
+	user.Role = role
+}
+
+// availableUsername derives a unique username from the preferred one or the email local part
+func (s *authService) availableUsername(ctx context.Context, preferred, email string) (string, error) {
+	base := sanitizeUsername(preferred)
+	if len(base) < 3 {
+		base = sanitizeUsername(strings.SplitN(email, "@", 2)[0])
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 2; ; i++ {
+		exists, err := s.userRepo.UsernameExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		suffix := fmt.Sprintf("%d", i)
+		if len(base)+len(suffix) > maxUsernameLength {
+			base = base[:maxUsernameLength-len(suffix)]
+		}
+		candidate = base + suffix
+	}
+}
+
+// sanitizeUsername keeps characters that are safe in usernames and truncates to the column size
+func sanitizeUsername(value string) string {
+	var b strings.Builder
+	for _, char := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case 'a' <= char && char <= 'z', '0' <= char && char <= '9', char == '.', char == '_', char == '-':
+			b.WriteRune(char)
+		}
+		if b.Len() == maxUsernameLength {
+			break
+		}
+	}
+	return b.String()
+}
+
+func (s *authService) ldapEnabled() bool {
+	return s.ldap != nil && s.ldap.Enabled()
+}
+
+// loginFirstTimeLDAPUser authenticates a login name unknown locally against the directory
+// and syncs the entry into users before continuing with the normal password login.
+func (s *authService) loginFirstTimeLDAPUser(ctx context.Context, req models.LoginRequest, userAgent, ipAddress string) (*models.User, string, string, error) {
+	identity, err := s.ldap.Authenticate(ctx, req.Email, req.Password)
+	if err == ErrLDAPInvalidCredentials {
+		s.recordFailedLogin(ctx, req.Email, nil, "invalid_password")
+		return nil, "", "", ErrInvalidCredentials
+	}
+	if err != nil {
+		log.Printf("LDAP authentication failed: %v", err)
+		return nil, "", "", ErrLDAPUnavailable
+	}
+	identity.Email = strings.ToLower(strings.TrimSpace(identity.Email))
+	if !isValidEmail(identity.Email) {
+		return nil, "", "", ErrInvalidExternalIdentity
+	}
+
+	// The login name may differ from the directory username (e.g. mail vs uid)
+	user, err := s.userRepo.GetByExternalSubject(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		user, err = s.provisionExternalUser(ctx, *identity)
+		if err != nil {
+			return nil, "", "", err
+		}
+	} else if identity.Role != "" && identity.Role != user.Role {
+		s.syncExternalRole(ctx, user, identity.Role)
+	}
+
+	// A linked existing account keeps its lock and activation state
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		s.recordFailedLogin(ctx, req.Email, user, "account_locked")
+		return nil, "", "", ErrAccountLocked
+	}
+	if !user.IsActive {
+		s.recordFailedLogin(ctx, req.Email, user, "account_deactivated")
+		return nil, "", "", ErrAccountDeactivated
+	}
+
+	return s.finishPasswordLogin(ctx, user, userAgent, ipAddress)
+}
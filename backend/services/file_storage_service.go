@@ -113,6 +113,17 @@ func (s *FileStorageService) StoreFile(src io.Reader, filePath string) error {
 	return nil
 }
 
+// CopyFile duplicates a stored file at a new path
+func (s *FileStorageService) CopyFile(srcPath, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", srcPath, err)
+	}
+	defer src.Close()
+
+	return s.StoreFile(src, dstPath)
+}
+
 // DeleteFile removes a file from storage
 func (s *FileStorageService) DeleteFile(filePath string) error {
 	if filePath == "" {
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGroupNotFound       = errors.New("گروه یافت نشد")
+	ErrGroupExists         = errors.New("گروهی با این نام قبلاً تعریف شده است")
+	ErrInvalidGroupName    = errors.New("نام گروه الزامی است و حداکثر ۱۰۰ کاراکتر دارد")
+	ErrGroupMemberExists   = errors.New("کاربر قبلاً عضو این گروه است")
+	ErrGroupMemberNotFound = errors.New("کاربر عضو این گروه نیست")
+	ErrGroupMemberUser     = errors.New("کاربر یافت نشد یا غیرفعال است")
+)
+
+type GroupService interface {
+	ListGroups(ctx context.Context) ([]models.Group, error)
+	GetGroup(ctx context.Context, id uuid.UUID) (*models.Group, error)
+	CreateGroup(ctx context.Context, req models.CreateGroupRequest) (*models.Group, error)
+	UpdateGroup(ctx context.Context, id uuid.UUID, req models.UpdateGroupRequest) (*models.Group, error)
+	DeleteGroup(ctx context.Context, id uuid.UUID) error
+	ListMembers(ctx context.Context, id uuid.UUID) ([]models.GroupMember, error)
+	AddMember(ctx context.Context, id uuid.UUID, req models.AddGroupMemberRequest) ([]models.GroupMember, error)
+	RemoveMember(ctx context.Context, id, userID uuid.UUID) error
+}
+
+type groupService struct {
+	groupRepo repositories.GroupRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewGroupService(groupRepo repositories.GroupRepository, userRepo repositories.UserRepository) GroupService {
+	return &groupService{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+	}
+}
+
+func (s *groupService) ListGroups(ctx context.Context) ([]models.Group, error) {
+	return s.groupRepo.List(ctx)
+}
+
+func (s *groupService) GetGroup(ctx context.Context, id uuid.UUID) (*models.Group, error) {
+	group, err := s.groupRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func (s *groupService) CreateGroup(ctx context.Context, req models.CreateGroupRequest) (*models.Group, error) {
+	name, err := normalizeGroupName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &models.Group{Name: name, Description: strings.TrimSpace(req.Description)}
+	err = s.groupRepo.Create(ctx, group)
+	if errors.Is(err, repositories.ErrGroupNameTaken) {
+		return nil, ErrGroupExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (s *groupService) UpdateGroup(ctx context.Context, id uuid.UUID, req models.UpdateGroupRequest) (*models.Group, error) {
+	name, err := normalizeGroupName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := s.groupRepo.Update(ctx, id, name, strings.TrimSpace(req.Description))
+	if errors.Is(err, repositories.ErrGroupNameTaken) {
+		return nil, ErrGroupExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrGroupNotFound
+	}
+	return group, nil
+}
+
+func (s *groupService) DeleteGroup(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.groupRepo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+func (s *groupService) ListMembers(ctx context.Context, id uuid.UUID) ([]models.GroupMember, error) {
+	if _, err := s.GetGroup(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.ListMembers(ctx, id)
+}
+
+// AddMember adds an active user to the group and returns the updated member list
+func (s *groupService) AddMember(ctx context.Context, id uuid.UUID, req models.AddGroupMemberRequest) ([]models.GroupMember, error) {
+	if _, err := s.GetGroup(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	var err error
+	if req.UserID == uuid.Nil && req.Email != "" {
+		user, err = s.userRepo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(req.Email)))
+	} else {
+		user, err = s.userRepo.GetByID(ctx, req.UserID)
+	}
+	if err != nil || user == nil || !user.IsActive {
+		return nil, ErrGroupMemberUser
+	}
+
+	added, err := s.groupRepo.AddMember(ctx, id, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrGroupMemberExists
+	}
+	return s.groupRepo.ListMembers(ctx, id)
+}
+
+func (s *groupService) RemoveMember(ctx context.Context, id, userID uuid.UUID) error {
+	removed, err := s.groupRepo.RemoveMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrGroupMemberNotFound
+	}
+	return nil
+}
+
+// normalizeGroupName trims the name and enforces the column limit
+func normalizeGroupName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return "", ErrInvalidGroupName
+	}
+	return name, nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvitationNotFound        = errors.New("دعوت‌نامه یافت نشد")
+	ErrInvalidInvitation         = errors.New("دعوت‌نامه نامعتبر، لغو شده یا منقضی شده است")
+	ErrInvitationPending         = errors.New("برای این ایمیل یک دعوت‌نامه فعال وجود دارد")
+	ErrInvitationClosed          = errors.New("این دعوت‌نامه قبلاً پذیرفته یا لغو شده است")
+	ErrInvalidRole               = errors.New("نقش انتخاب‌شده تعریف نشده است")
+	ErrInvitationProjectNotFound = errors.New("یکی از پروژه‌های انتخاب‌شده یافت نشد")
+	ErrUsernameExists            = errors.New("این نام کاربری قبلاً ثبت شده است")
+)
+
+// invitationExpiry is how long an emailed invitation link stays valid
+const invitationExpiry = 7 * 24 * time.Hour
+
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, invitedBy uuid.UUID, req models.CreateInvitationRequest) (*models.UserInvitation, error)
+	ListInvitations(ctx context.Context) ([]models.UserInvitation, error)
+	ResendInvitation(ctx context.Context, id uuid.UUID) (*models.UserInvitation, error)
+	RevokeInvitation(ctx context.Context, id uuid.UUID) error
+	GetInvitationPreview(ctx context.Context, token string) (*models.InvitationPreview, error)
+	AcceptInvitation(ctx context.Context, req models.AcceptInvitationRequest) (*models.User, error)
+}
+
+type invitationService struct {
+	invitationRepo repositories.InvitationRepository
+	userRepo       repositories.UserRepository
+	roleRepo       repositories.RoleRepository
+	projectRepo    *repositories.ProjectRepository
+	emailService   *EmailService
+	policy         PasswordPolicy
+}
+
+func NewInvitationService(invitationRepo repositories.InvitationRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, projectRepo *repositories.ProjectRepository, emailService *EmailService, policy PasswordPolicy) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		projectRepo:    projectRepo,
+		emailService:   emailService,
+		policy:         policy,
+	}
+}
+
+// CreateInvitation stores an invitation and emails its single-use link
+func (s *invitationService) CreateInvitation(ctx context.Context, invitedBy uuid.UUID, req models.CreateInvitationRequest) (*models.UserInvitation, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if !isValidEmail(email) {
+		return nil, errors.New("فرمت ایمیل نامعتبر است")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.SystemRoleUser
+	}
+	if err := requireRoleExists(ctx, s.roleRepo, role); err != nil {
+		return nil, err
+	}
+
+	if existing, _ := s.userRepo.GetByEmail(ctx, email); existing != nil {
+		return nil, ErrEmailExists
+	}
+
+	pending, err := s.invitationRepo.HasPending(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrInvitationPending
+	}
+
+	projectIDs, err := s.validateProjects(ctx, req.ProjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.UserInvitation{
+		Email:      email,
+		Role:       role,
+		ProjectIDs: projectIDs,
+		TokenHash:  hashToken(token),
+		InvitedBy:  &invitedBy,
+		ExpiresAt:  time.Now().Add(invitationExpiry),
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+	invitation.Status = invitationStatus(invitation, time.Now())
+
+	// Send email with plain token (not the hash)
+	if err := s.emailService.SendInvitationEmail(email, token); err != nil {
+		// Log error but don't fail the request; the admin can resend
+		fmt.Printf("Failed to send invitation email: %v\n", err)
+	}
+
+	return invitation, nil
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context) ([]models.UserInvitation, error) {
+	invitations, err := s.invitationRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range invitations {
+		invitations[i].Status = invitationStatus(&invitations[i], now)
+	}
+	return invitations, nil
+}
+
+// ResendInvitation issues a fresh token and expiry; the previously emailed link stops working
+func (s *invitationService) ResendInvitation(ctx context.Context, id uuid.UUID) (*models.UserInvitation, error) {
+	invitation, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(invitationExpiry)
+	reissued, err := s.invitationRepo.Reissue(ctx, id, hashToken(token), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !reissued {
+		return nil, ErrInvitationClosed
+	}
+
+	invitation.ExpiresAt = expiresAt
+	invitation.Status = invitationStatus(invitation, time.Now())
+
+	if err := s.emailService.SendInvitationEmail(invitation.Email, token); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.invitationRepo.GetByID(ctx, id); err != nil {
+		return ErrInvitationNotFound
+	}
+
+	revoked, err := s.invitationRepo.Revoke(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvitationClosed
+	}
+	return nil
+}
+
+// GetInvitationPreview returns what the accept page shows for a still-open invitation
+func (s *invitationService) GetInvitationPreview(ctx context.Context, token string) (*models.InvitationPreview, error) {
+	invitation, err := s.openInvitation(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InvitationPreview{
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+	}, nil
+}
+
+// AcceptInvitation creates the invited account with the chosen username and password
+func (s *invitationService) AcceptInvitation(ctx context.Context, req models.AcceptInvitationRequest) (*models.User, error) {
+	invitation, err := s.openInvitation(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate input
+	username := strings.TrimSpace(req.Username)
+	if len(username) < 3 || len(username) > 50 {
+		return nil, errors.New("نام کاربری باید بین 3 تا 50 کاراکتر باشد")
+	}
+
+	if req.Password != req.PasswordConfirmation {
+		return nil, errors.New("رمز عبور و تکرار آن مطابقت ندارند")
+	}
+
+	if err := s.policy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
+	if existing, _ := s.userRepo.GetByEmail(ctx, invitation.Email); existing != nil {
+		return nil, ErrEmailExists
+	}
+
+	exists, err := s.userRepo.UsernameExists(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrUsernameExists
+	}
+
+	// Hash password
+	hashedPassword, err := s.policy.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// The invitee proved ownership of the address by opening the emailed link
+	now := time.Now()
+	user := &models.User{
+		Username:        username,
+		Email:           invitation.Email,
+		EmailVerifiedAt: &now,
+		PasswordHash:    hashedPassword,
+		Role:            invitation.Role,
+		IsActive:        true,
+	}
+
+	accepted, err := s.invitationRepo.Accept(ctx, invitation.ID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, ErrInvalidInvitation
+	}
+
+	return user, nil
+}
+
+// openInvitation looks up an invitation by its plaintext token and checks it can still be accepted
+func (s *invitationService) openInvitation(ctx context.Context, token string) (*models.UserInvitation, error) {
+	if token == "" {
+		return nil, ErrInvalidInvitation
+	}
+
+	invitation, err := s.invitationRepo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	if invitationStatus(invitation, time.Now()) != models.InvitationStatusPending {
+		return nil, ErrInvalidInvitation
+	}
+
+	return invitation, nil
+}
+
+// validateProjects removes duplicates and checks that every project exists
+func (s *invitationService) validateProjects(ctx context.Context, projectIDs []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(projectIDs))
+	var unique []uuid.UUID
+
+	for _, id := range projectIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		project, err := s.projectRepo.GetByID(ctx, id)
+		if err != nil || project == nil {
+			return nil, ErrInvitationProjectNotFound
+		}
+
+		unique = append(unique, id)
+	}
+
+	return unique, nil
+}
+
+// invitationStatus derives the status shown to admins from the invitation timestamps
+func invitationStatus(invitation *models.UserInvitation, now time.Time) string {
+	switch {
+	case invitation.AcceptedAt != nil:
+		return models.InvitationStatusAccepted
+	case invitation.RevokedAt != nil:
+		return models.InvitationStatusRevoked
+	case !now.Before(invitation.ExpiresAt):
+		return models.InvitationStatusExpired
+	default:
+		return models.InvitationStatusPending
+	}
+}
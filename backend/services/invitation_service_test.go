@@ -0,0 +1,31 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"project-management/models"
+)
+
+func TestInvitationStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	cases := []struct {
+		name       string
+		invitation models.UserInvitation
+		want       string
+	}{
+		{"open", models.UserInvitation{ExpiresAt: now.Add(time.Hour)}, models.InvitationStatusPending},
+		{"expired", models.UserInvitation{ExpiresAt: past}, models.InvitationStatusExpired},
+		{"revoked", models.UserInvitation{ExpiresAt: now.Add(time.Hour), RevokedAt: &past}, models.InvitationStatusRevoked},
+		// Acceptance wins over later expiry
+		{"accepted", models.UserInvitation{ExpiresAt: past, AcceptedAt: &past}, models.InvitationStatusAccepted},
+	}
+
+	for _, tc := range cases {
+		if got := invitationStatus(&tc.invitation, now); got != tc.want {
+			t.Fatalf("%s: status = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"project-management/models"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	ErrLDAPInvalidCredentials = errors.New("نام کاربری یا رمز عبور سازمانی نادرست است")
+	ErrLDAPUnavailable        = errors.New("سرویس احراز هویت سازمانی در دسترس نیست")
+)
+
+const ldapTimeout = 10 * time.Second
+
+// LDAPConfig configures binding against an LDAP / Active Directory server
+type LDAPConfig struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+
+	// BindDNTemplate enables direct bind; {username} is replaced with the escaped login name
+	BindDNTemplate string
+
+	// Search-then-bind settings, used when BindDNTemplate is empty
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	UserFilter   string
+
+	// Attribute mapping
+	UsernameAttribute string
+	EmailAttribute    string
+	GroupAttribute    string
+	AdminGroups       []string
+}
+
+// LDAPAuthenticator verifies directory credentials as an alternative to bcrypt passwords
+type LDAPAuthenticator interface {
+	Enabled() bool
+	Authenticate(ctx context.Context, username, password string) (*models.ExternalIdentity, error)
+}
+
+type ldapAuthenticator struct {
+	config LDAPConfig
+}
+
+func NewLDAPAuthenticator(config LDAPConfig) LDAPAuthenticator {
+	if config.UsernameAttribute == "" {
+		config.UsernameAttribute = "uid"
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = "mail"
+	}
+	if config.UserFilter == "" {
+		config.UserFilter = "(uid={username})"
+	}
+	return &ldapAuthenticator{config: config}
+}
+
+func (a *ldapAuthenticator) Enabled() bool {
+	return a.config.URL != ""
+}
+
+// Authenticate binds as the user and returns the directory identity.
+// ErrLDAPInvalidCredentials means the password was wrong; other errors are infrastructure failures.
+func (a *ldapAuthenticator) Authenticate(ctx context.Context, username, password string) (*models.ExternalIdentity, error) {
+	if !a.Enabled() {
+		return nil, ErrLDAPUnavailable
+	}
+
+	// An empty password would be an unauthenticated bind, which most servers accept
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	conn, err := a.connect()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLDAPUnavailable, err)
+	}
+	defer conn.Close()
+
+	attributes := []string{a.config.UsernameAttribute, a.config.EmailAttribute}
+	if a.config.GroupAttribute != "" {
+		attributes = append(attributes, a.config.GroupAttribute)
+	}
+
+	var entry *ldap.Entry
+	if a.config.BindDNTemplate != "" {
+		userDN := fillLDAPTemplate(a.config.BindDNTemplate, username, ldap.EscapeDN)
+		if err := bindAs(conn, userDN, password); err != nil {
+			return nil, err
+		}
+
+		// Active Directory UPN templates (user@domain) are not DNs; look the entry up by filter instead
+		request := a.userSearchRequest(username, attributes)
+		if strings.Contains(userDN, "=") {
+			request = ldap.NewSearchRequest(
+				userDN, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, int(ldapTimeout.Seconds()), false,
+				"(objectClass=*)", attributes, nil,
+			)
+		}
+
+		entry, err = searchOne(conn, request)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if a.config.BindDN != "" {
+			if err := conn.Bind(a.config.BindDN, a.config.BindPassword); err != nil {
+				return nil, fmt.Errorf("%w: service account bind failed: %v", ErrLDAPUnavailable, err)
+			}
+		}
+
+		entry, err = searchOne(conn, a.userSearchRequest(username, attributes))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := bindAs(conn, entry.DN, password); err != nil {
+			return nil, err
+		}
+	}
+
+	identity := models.ExternalIdentity{
+		Provider:      models.AuthProviderLDAP,
+		Subject:       strings.ToLower(entry.GetAttributeValue(a.config.UsernameAttribute)),
+		Email:         entry.GetAttributeValue(a.config.EmailAttribute),
+		EmailVerified: true, // Directory addresses are managed by administrators
+		Username:      entry.GetAttributeValue(a.config.UsernameAttribute),
+	}
+	if identity.Subject == "" {
+		return nil, ErrInvalidExternalIdentity
+	}
+
+	if a.config.GroupAttribute != "" {
+		identity.Role = ldapRoleForGroups(entry.GetAttributeValues(a.config.GroupAttribute), a.config.AdminGroups)
+	}
+
+	return &identity, nil
+}
+
+func (a *ldapAuthenticator) connect() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.config.InsecureSkipVerify}
+	if parsed, err := url.Parse(a.config.URL); err == nil {
+		tlsConfig.ServerName = parsed.Hostname()
+	}
+
+	conn, err := ldap.DialURL(a.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if a.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (a *ldapAuthenticator) userSearchRequest(username string, attributes []string) *ldap.SearchRequest {
+	return ldap.NewSearchRequest(
+		a.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false,
+		fillLDAPTemplate(a.config.UserFilter, username, ldap.EscapeFilter), attributes, nil,
+	)
+}
+
+// bindAs binds with user credentials, separating wrong passwords from server errors
+func bindAs(conn *ldap.Conn, dn, password string) error {
+	err := conn.Bind(dn, password)
+	if err == nil {
+		return nil
+	}
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return ErrLDAPInvalidCredentials
+	}
+	return fmt.Errorf("%w: %v", ErrLDAPUnavailable, err)
+}
+
+// searchOne expects exactly one entry; unknown or ambiguous users count as invalid credentials
+func searchOne(conn *ldap.Conn, request *ldap.SearchRequest) (*ldap.Entry, error) {
+	result, err := conn.Search(request)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, ErrLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("%w: %v", ErrLDAPUnavailable, err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrLDAPInvalidCredentials
+	}
+	return result.Entries[0], nil
+}
+
+// fillLDAPTemplate substitutes {username} after escaping it for the target syntax
+func fillLDAPTemplate(template, username string, escape func(string) string) string {
+	return strings.ReplaceAll(template, "{username}", escape(username))
+}
+
+// ldapRoleForGroups matches group values (usually DNs) against admin groups given as DN or CN
+func ldapRoleForGroups(groups, adminGroups []string) string {
+	for _, group := range groups {
+		names := []string{group}
+		if dn, err := ldap.ParseDN(group); err == nil && len(dn.RDNs) > 0 && len(dn.RDNs[0].Attributes) > 0 {
+			names = append(names, dn.RDNs[0].Attributes[0].Value)
+		}
+		for _, adminGroup := range adminGroups {
+			for _, name := range names {
+				if strings.EqualFold(strings.TrimSpace(adminGroup), name) {
+					return models.SystemRoleAdmin
+				}
+			}
+		}
+	}
+	return models.SystemRoleUser
+}
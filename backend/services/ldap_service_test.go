@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestFillLDAPTemplate_EscapesUsername(t *testing.T) {
+	dn := fillLDAPTemplate("uid={username},ou=people,dc=example,dc=com", "doe,admin", ldap.EscapeDN)
+	if dn != `uid=doe\,admin,ou=people,dc=example,dc=com` {
+		t.Fatalf("unexpected DN: %s", dn)
+	}
+
+	filter := fillLDAPTemplate("(|(uid={username})(mail={username}))", "*)(uid=*", ldap.EscapeFilter)
+	if filter != `(|(uid=\2a\29\28uid=\2a)(mail=\2a\29\28uid=\2a))` {
+		t.Fatalf("unexpected filter: %s", filter)
+	}
+}
+
+func TestLDAPRoleForGroups(t *testing.T) {
+	groups := []string{
+		"CN=Developers,OU=Groups,DC=corp,DC=example,DC=com",
+		"CN=PM Admins,OU=Groups,DC=corp,DC=example,DC=com",
+	}
+
+	if role := ldapRoleForGroups(groups, []string{"pm admins"}); role != "admin" {
+		t.Fatalf("match by CN: role = %q, want admin", role)
+	}
+	if role := ldapRoleForGroups(groups, []string{"cn=pm admins,ou=groups,dc=corp,dc=example,dc=com"}); role != "admin" {
+		t.Fatalf("match by DN: role = %q, want admin", role)
+	}
+	if role := ldapRoleForGroups(groups, []string{"Administrators"}); role != "user" {
+		t.Fatalf("no match: role = %q, want user", role)
+	}
+}
+
+func TestLDAPAuthenticate_RejectsEmptyPassword(t *testing.T) {
+	// Must not reach the server: an empty password is an unauthenticated bind
+	authenticator := NewLDAPAuthenticator(LDAPConfig{URL: "ldap://127.0.0.1:1"})
+	if _, err := authenticator.Authenticate(context.Background(), "jane", ""); err != ErrLDAPInvalidCredentials {
+		t.Fatalf("err = %v, want ErrLDAPInvalidCredentials", err)
+	}
+}
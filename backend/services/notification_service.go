@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+// NotificationService emails users about task assignments; group assignments fan out to every member
+type NotificationService struct {
+	userRepo     repositories.UserRepository
+	groupRepo    repositories.GroupRepository
+	projectRepo  *repositories.ProjectRepository
+	access       *ProjectAccess
+	emailService *EmailService
+}
+
+func NewNotificationService(userRepo repositories.UserRepository, groupRepo repositories.GroupRepository, projectRepo *repositories.ProjectRepository, access *ProjectAccess, emailService *EmailService) *NotificationService {
+	return &NotificationService{
+		userRepo:     userRepo,
+		groupRepo:    groupRepo,
+		projectRepo:  projectRepo,
+		access:       access,
+		emailService: emailService,
+	}
+}
+
+// TaskAssigned notifies the newly assigned user and group members in the background.
+// The actor is not told about their own change, nobody receives the email twice, and
+// users who cannot see the task's project are skipped.
+func (s *NotificationService) TaskAssigned(task *models.Task, userID, groupID *uuid.UUID, actorID uuid.UUID) {
+	if s == nil || task == nil || (userID == nil && groupID == nil) {
+		return
+	}
+	go s.sendTaskAssigned(*task, userID, groupID, actorID)
+}
+
+func (s *NotificationService) sendTaskAssigned(task models.Task, userID, groupID *uuid.UUID, actorID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	projectTitle := ""
+	if project, err := s.projectRepo.GetByID(ctx, task.ProjectID); err == nil && project != nil {
+		projectTitle = project.Title
+	}
+
+	notified := map[uuid.UUID]bool{actorID: true}
+
+	if userID != nil && !notified[*userID] {
+		notified[*userID] = true
+		user, err := s.userRepo.GetByID(ctx, *userID)
+		if err != nil || user == nil {
+			log.Printf("Task %s assignment notification: cannot load user %s: %v", task.ID, *userID, err)
+		} else if user.IsActive && user.IsEmailVerified() && s.canSee(ctx, task, user) {
+			s.send(user.Email, task, projectTitle, "")
+		}
+	}
+
+	if groupID != nil {
+		group, err := s.groupRepo.GetByID(ctx, *groupID)
+		if err != nil || group == nil {
+			log.Printf("Task %s assignment notification: cannot load group %s: %v", task.ID, *groupID, err)
+			return
+		}
+		members, err := s.groupRepo.GetNotifiableMembers(ctx, group.ID)
+		if err != nil {
+			log.Printf("Task %s assignment notification: cannot list members of group %s: %v", task.ID, group.ID, err)
+			return
+		}
+		for _, member := range members {
+			if notified[member.ID] {
+				continue
+			}
+			notified[member.ID] = true
+			if s.canSee(ctx, task, &member) {
+				s.send(member.Email, task, projectTitle, group.Name)
+			}
+		}
+	}
+}
+
+// canSee reports whether the user has a role in the task's project; failed checks count as no
+func (s *NotificationService) canSee(ctx context.Context, task models.Task, user *models.User) bool {
+	if _, err := s.access.Role(ctx, task.ProjectID, user.ID, user.Role); err != nil {
+		if !errors.Is(err, models.ErrNotFound) {
+			log.Printf("Task %s assignment notification: cannot check access of user %s: %v", task.ID, user.ID, err)
+		}
+		return false
+	}
+	return true
+}
+
+func (s *NotificationService) send(to string, task models.Task, projectTitle, groupName string) {
+	if err := s.emailService.SendTaskAssignedEmail(to, task.Title, projectTitle, groupName); err != nil {
+		log.Printf("Task %s assignment notification to %s failed: %v", task.ID, to, err)
+	}
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"project-management/models"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrOIDCDisabled     = errors.New("ورود یکپارچه (SSO) پیکربندی نشده است")
+	ErrOIDCInvalidState = errors.New("درخواست ورود یکپارچه نامعتبر یا منقضی شده است")
+	ErrOIDCExchange     = errors.New("تأیید هویت با سرویس ورود یکپارچه ناموفق بود")
+)
+
+// oidcStateExpiry bounds the time between redirecting to the provider and the callback
+const oidcStateExpiry = 10 * time.Minute
+
+// OIDCConfig configures the OpenID Connect authorization code flow
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Claim mapping
+	EmailClaim    string
+	UsernameClaim string
+	GroupsClaim   string
+	AdminGroups   []string
+
+	// StateSecret signs the short-lived login state cookie
+	StateSecret string
+}
+
+type OIDCService interface {
+	Enabled() bool
+	BeginLogin(ctx context.Context) (authURL string, stateToken string, err error)
+	CompleteLogin(ctx context.Context, stateToken, state, code string) (*models.ExternalIdentity, error)
+}
+
+type oidcService struct {
+	config OIDCConfig
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(config OIDCConfig) OIDCService {
+	if config.EmailClaim == "" {
+		config.EmailClaim = "email"
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "preferred_username"
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	return &oidcService{config: config}
+}
+
+func (s *oidcService) Enabled() bool {
+	return s.config.IssuerURL != "" && s.config.ClientID != ""
+}
+
+// BeginLogin returns the provider authorization URL and a signed token holding
+// state, nonce and PKCE verifier, to be stored in a cookie until the callback.
+func (s *oidcService) BeginLogin(ctx context.Context) (string, string, error) {
+	if !s.Enabled() {
+		return "", "", ErrOIDCDisabled
+	}
+
+	oauthConfig, _, err := s.clients(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := generateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	stateToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"type":     "oidc_state",
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(oidcStateExpiry).Unix(),
+	}).SignedString([]byte(s.config.StateSecret))
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return authURL, stateToken, nil
+}
+
+// CompleteLogin exchanges the authorization code and returns the verified identity
+func (s *oidcService) CompleteLogin(ctx context.Context, stateToken, state, code string) (*models.ExternalIdentity, error) {
+	if !s.Enabled() {
+		return nil, ErrOIDCDisabled
+	}
+
+	claims, err := s.parseStateToken(stateToken)
+	if err != nil || code == "" || subtle.ConstantTimeCompare([]byte(claims["state"].(string)), []byte(state)) != 1 {
+		return nil, ErrOIDCInvalidState
+	}
+
+	oauthConfig, verifier, err := s.clients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(claims["verifier"].(string)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCExchange, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: id_token missing from token response", ErrOIDCExchange)
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCExchange, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(claims["nonce"].(string))) != 1 {
+		return nil, ErrOIDCInvalidState
+	}
+
+	var idClaims map[string]interface{}
+	if err := idToken.Claims(&idClaims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCExchange, err)
+	}
+
+	identity := mapOIDCClaims(idToken.Subject, idClaims, s.config)
+	return &identity, nil
+}
+
+// clients lazily discovers the provider so the API can start while the IdP is unreachable
+func (s *oidcService) clients(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.config.IssuerURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrOIDCExchange, err)
+		}
+		s.provider = provider
+	}
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     s.config.ClientID,
+		ClientSecret: s.config.ClientSecret,
+		RedirectURL:  s.config.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       s.config.Scopes,
+	}
+	verifier := s.provider.Verifier(&oidc.Config{ClientID: s.config.ClientID})
+
+	return oauthConfig, verifier, nil
+}
+
+func (s *oidcService) parseStateToken(stateToken string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(stateToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrOIDCInvalidState
+		}
+		return []byte(s.config.StateSecret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrOIDCInvalidState
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != "oidc_state" {
+		return nil, ErrOIDCInvalidState
+	}
+	for _, key := range []string{"state", "nonce", "verifier"} {
+		if _, ok := claims[key].(string); !ok {
+			return nil, ErrOIDCInvalidState
+		}
+	}
+
+	return claims, nil
+}
+
+// mapOIDCClaims applies the configured claim mapping to ID token claims
+func mapOIDCClaims(subject string, claims map[string]interface{}, config OIDCConfig) models.ExternalIdentity {
+	identity := models.ExternalIdentity{
+		Provider: models.AuthProviderOIDC,
+		Subject:  subject,
+	}
+
+	identity.Email, _ = claims[config.EmailClaim].(string)
+	identity.Username, _ = claims[config.UsernameClaim].(string)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+
+	// Role is only decided when the provider sends the groups claim at all
+	if config.GroupsClaim != "" {
+		if raw, present := claims[config.GroupsClaim]; present {
+			identity.Role = roleForGroups(claimStrings(raw), config.AdminGroups)
+		}
+	}
+
+	return identity
+}
+
+// roleForGroups returns the admin role if any group is an admin group, the user role otherwise
+func roleForGroups(groups, adminGroups []string) string {
+	for _, group := range groups {
+		for _, adminGroup := range adminGroups {
+			if strings.EqualFold(group, adminGroup) {
+				return models.SystemRoleAdmin
+			}
+		}
+	}
+	return models.SystemRoleUser
+}
+
+// claimStrings accepts a claim that is either a string or a list of strings
+func claimStrings(raw interface{}) []string {
+	switch value := raw.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
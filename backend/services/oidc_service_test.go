@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockOIDCIssuer is a minimal OpenID provider: discovery, JWKS and a token endpoint
+// that checks the PKCE verifier and issues an RS256-signed ID token.
+type mockOIDCIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+	code     string
+	claims   jwt.MapClaims
+
+	// Captured from the authorization request
+	nonce     string
+	challenge string
+}
+
+func newMockOIDCIssuer(t *testing.T, clientID string) *mockOIDCIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	m := &mockOIDCIssuer{key: key, clientID: clientID, code: "valid-code"}
+	mux := http.NewServeMux()
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                m.server.URL,
+			"authorization_endpoint":                m.server.URL + "/authorize",
+			"token_endpoint":                        m.server.URL + "/token",
+			"jwks_uri":                              m.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": "test-key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != m.code || base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   m.server.URL,
+			"aud":   m.clientID,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": m.nonce,
+		}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+
+		idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		idToken.Header["kid"] = "test-key"
+		signed, err := idToken.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     signed,
+		})
+	})
+
+	return m
+}
+
+// authorize simulates the browser visiting the authorization URL
+func (m *mockOIDCIssuer) authorize(t *testing.T, authURL string) (state string) {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	query := parsed.Query()
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL is missing the PKCE challenge: %s", authURL)
+	}
+	if query.Get("client_id") != m.clientID {
+		t.Fatalf("unexpected client_id %q", query.Get("client_id"))
+	}
+
+	m.nonce = query.Get("nonce")
+	m.challenge = query.Get("code_challenge")
+	return query.Get("state")
+}
+
+func newTestOIDCService(issuerURL string) OIDCService {
+	return NewOIDCService(OIDCConfig{
+		IssuerURL:    issuerURL,
+		ClientID:     "project-management",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/api/auth/oidc/callback",
+		GroupsClaim:  "groups",
+		AdminGroups:  []string{"pm-admins"},
+		StateSecret:  "test-state-secret",
+	})
+}
+
+func TestOIDCLogin_AgainstMockIssuer(t *testing.T) {
+	issuer := newMockOIDCIssuer(t, "project-management")
+	issuer.claims = jwt.MapClaims{
+		"sub":                "user-123",
+		"email":              "Jane@Example.com",
+		"email_verified":     true,
+		"preferred_username": "jane",
+		"groups":             []string{"staff", "pm-admins"},
+	}
+
+	service := newTestOIDCService(issuer.server.URL)
+	ctx := context.Background()
+
+	authURL, stateToken, err := service.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	state := issuer.authorize(t, authURL)
+
+	identity, err := service.CompleteLogin(ctx, stateToken, state, issuer.code)
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+
+	if identity.Subject != "user-123" || identity.Email != "Jane@Example.com" || identity.Username != "jane" {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+	if !identity.EmailVerified {
+		t.Fatalf("expected email to be verified")
+	}
+	if identity.Role != "admin" {
+		t.Fatalf("role = %q, want admin", identity.Role)
+	}
+}
+
+func TestOIDCLogin_RejectsTamperedState(t *testing.T) {
+	issuer := newMockOIDCIssuer(t, "project-management")
+	issuer.claims = jwt.MapClaims{"sub": "user-123", "email": "jane@example.com"}
+
+	service := newTestOIDCService(issuer.server.URL)
+	ctx := context.Background()
+
+	authURL, stateToken, err := service.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	issuer.authorize(t, authURL)
+
+	if _, err := service.CompleteLogin(ctx, stateToken, "forged-state", issuer.code); !errors.Is(err, ErrOIDCInvalidState) {
+		t.Fatalf("forged state: err = %v, want ErrOIDCInvalidState", err)
+	}
+
+	// A state token signed with another secret must be rejected as well
+	other := NewOIDCService(OIDCConfig{IssuerURL: issuer.server.URL, ClientID: "project-management", StateSecret: "other"})
+	_, foreignToken, err := other.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	if _, err := service.CompleteLogin(ctx, foreignToken, "anything", issuer.code); !errors.Is(err, ErrOIDCInvalidState) {
+		t.Fatalf("foreign state token: err = %v, want ErrOIDCInvalidState", err)
+	}
+}
+
+func TestOIDCLogin_RejectsWrongCode(t *testing.T) {
+	issuer := newMockOIDCIssuer(t, "project-management")
+	issuer.claims = jwt.MapClaims{"sub": "user-123", "email": "jane@example.com"}
+
+	service := newTestOIDCService(issuer.server.URL)
+	ctx := context.Background()
+
+	authURL, stateToken, err := service.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	state := issuer.authorize(t, authURL)
+
+	if _, err := service.CompleteLogin(ctx, stateToken, state, "stolen-code"); !errors.Is(err, ErrOIDCExchange) {
+		t.Fatalf("err = %v, want ErrOIDCExchange", err)
+	}
+}
+
+func TestMapOIDCClaims(t *testing.T) {
+	config := OIDCConfig{EmailClaim: "mail", UsernameClaim: "uid", GroupsClaim: "roles", AdminGroups: []string{"Admins"}}
+
+	identity := mapOIDCClaims("sub-1", map[string]interface{}{
+		"mail":  "a@example.com",
+		"uid":   "alice",
+		"roles": "admins",
+	}, config)
+	if identity.Email != "a@example.com" || identity.Username != "alice" || identity.Role != "admin" {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+
+	identity = mapOIDCClaims("sub-2", map[string]interface{}{
+		"mail":  "b@example.com",
+		"roles": []interface{}{"developers"},
+	}, config)
+	if identity.Role != "user" {
+		t.Fatalf("role = %q, want user", identity.Role)
+	}
+
+	// Without a groups claim the existing role is left untouched
+	identity = mapOIDCClaims("sub-3", map[string]interface{}{"mail": "c@example.com"}, config)
+	if identity.Role != "" {
+		t.Fatalf("role = %q, want empty", identity.Role)
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password policy violation codes, returned to clients alongside the Persian message
+const (
+	PasswordTooShort       = "PASSWORD_TOO_SHORT"
+	PasswordMissingUpper   = "PASSWORD_MISSING_UPPERCASE"
+	PasswordMissingLower   = "PASSWORD_MISSING_LOWERCASE"
+	PasswordMissingDigit   = "PASSWORD_MISSING_DIGIT"
+	PasswordMissingSymbol  = "PASSWORD_MISSING_SYMBOL"
+	PasswordReused         = "PASSWORD_REUSED"
+	PasswordBreached       = "PASSWORD_BREACHED"
+	PasswordPolicyViolated = "PASSWORD_POLICY_VIOLATION"
+)
+
+// PasswordViolation is a single failed password rule
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a proposed password fails
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return strings.Join(messages, "؛ ")
+}
+
+// Codes returns the violation codes in rule order
+func (e *PasswordPolicyError) Codes() []string {
+	codes := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		codes[i] = violation.Code
+	}
+	return codes
+}
+
+// BreachedPasswordList reports whether a password appears in a known breach corpus
+type BreachedPasswordList interface {
+	Contains(password string) (bool, error)
+}
+
+// PasswordPolicy holds the configurable rules for local account passwords
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+
+	// MaxAge forces a password change after this long; zero disables expiry
+	MaxAge time.Duration
+
+	// HistorySize is how many recent passwords (including the current one) cannot be reused; zero disables
+	HistorySize int
+
+	BcryptCost int
+
+	// Breached is optional; nil skips the breached-password check
+	Breached BreachedPasswordList
+}
+
+// Validate checks the password against the composition rules and the breached list
+func (p PasswordPolicy) Validate(password string) error {
+	var violations []PasswordViolation
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, PasswordViolation{PasswordTooShort, fmt.Sprintf("رمز عبور باید حداقل %d کاراکتر باشد", p.MinLength)})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, PasswordViolation{PasswordMissingUpper, "رمز عبور باید شامل حروف بزرگ باشد"})
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, PasswordViolation{PasswordMissingLower, "رمز عبور باید شامل حروف کوچک باشد"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, PasswordViolation{PasswordMissingDigit, "رمز عبور باید شامل اعداد باشد"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, PasswordViolation{PasswordMissingSymbol, "رمز عبور باید شامل نمادهای خاص باشد"})
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			// A broken list must not block every password change
+			fmt.Printf("Breached password check failed: %v\n", err)
+		} else if breached {
+			violations = append(violations, PasswordViolation{PasswordBreached, "این رمز عبور در نشت‌های اطلاعاتی شناخته‌شده دیده شده است. رمز دیگری انتخاب کنید"})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Hash hashes the password with the configured bcrypt cost
+func (p PasswordPolicy) Hash(password string) (string, error) {
+	cost := p.BcryptCost
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	return string(hash), err
+}
+
+// Expired reports whether a password set at changedAt is past the maximum age
+func (p PasswordPolicy) Expired(changedAt *time.Time, now time.Time) bool {
+	if p.MaxAge <= 0 || changedAt == nil {
+		return false
+	}
+	return now.Sub(*changedAt) > p.MaxAge
+}
+
+// breachedPasswordDirectory reads a k-anonymity range directory: one file per
+// 5-character SHA-1 prefix (e.g. 21BD1.txt) holding "SUFFIX:COUNT" lines, as
+// produced by the Pwned Passwords downloader.
+type breachedPasswordDirectory struct {
+	dir string
+}
+
+func NewBreachedPasswordDirectory(dir string) BreachedPasswordList {
+	return &breachedPasswordDirectory{dir: dir}
+}
+
+func (b *breachedPasswordDirectory) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(b.dir, prefix+".txt"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(candidate, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	cases := []struct {
+		password string
+		want     []string
+	}{
+		{"Str0ng!Password", nil},
+		{"Sh0rt!", []string{PasswordTooShort}},
+		{"lowercase1!x", []string{PasswordMissingUpper}},
+		{"UPPERCASE1!X", []string{PasswordMissingLower}},
+		{"NoDigits!Here", []string{PasswordMissingDigit}},
+		{"NoSymbols1Here", []string{PasswordMissingSymbol}},
+		{"", []string{PasswordTooShort, PasswordMissingUpper, PasswordMissingLower, PasswordMissingDigit, PasswordMissingSymbol}},
+	}
+
+	for _, tc := range cases {
+		err := policy.Validate(tc.password)
+		if tc.want == nil {
+			if err != nil {
+				t.Fatalf("Validate(%q) = %v, want nil", tc.password, err)
+			}
+			continue
+		}
+
+		var policyErr *PasswordPolicyError
+		if !errors.As(err, &policyErr) {
+			t.Fatalf("Validate(%q) = %v, want *PasswordPolicyError", tc.password, err)
+		}
+		if got := policyErr.Codes(); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("Validate(%q) codes = %v, want %v", tc.password, got, tc.want)
+		}
+	}
+}
+
+func TestPasswordPolicyExpired(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-91 * 24 * time.Hour)
+	recent := now.Add(-24 * time.Hour)
+
+	policy := PasswordPolicy{MaxAge: 90 * 24 * time.Hour}
+	if !policy.Expired(&old, now) {
+		t.Fatal("password older than max age should be expired")
+	}
+	if policy.Expired(&recent, now) {
+		t.Fatal("recent password should not be expired")
+	}
+	if policy.Expired(nil, now) {
+		t.Fatal("unknown change time should not be expired")
+	}
+	if (PasswordPolicy{}).Expired(&old, now) {
+		t.Fatal("zero max age should disable expiry")
+	}
+}
+
+func TestBreachedPasswordDirectory(t *testing.T) {
+	dir := t.TempDir()
+	// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	content := "0018A45C4D1DEF81644B54AB7F969B88D65:1\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\n"
+	if err := os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := PasswordPolicy{Breached: NewBreachedPasswordDirectory(dir)}
+
+	var policyErr *PasswordPolicyError
+	if err := policy.Validate("password"); !errors.As(err, &policyErr) || !reflect.DeepEqual(policyErr.Codes(), []string{PasswordBreached}) {
+		t.Fatalf("Validate(breached) = %v, want %s", err, PasswordBreached)
+	}
+	if err := policy.Validate("not-in-the-list"); err != nil {
+		t.Fatalf("Validate(missing range file) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+// ErrProjectForbidden is returned when the user can see the project but their role does not allow the action
+var ErrProjectForbidden = errors.New("insufficient project permissions")
+
+// ErrProjectReadOnly is returned for content changes in a project whose status is read-only, e.g. archived
+var ErrProjectReadOnly = errors.New("project is read-only")
+
+// projectAction is a change within a project: allowed from minRole upwards, or in any
+// visible project when the user's system role grants permission. Content changes are
+// refused while the project's status is read-only.
+type projectAction struct {
+	minRole       string
+	permission    string
+	changeContent bool
+}
+
+// Project actions; anyone who can see a project may read it
+var (
+	actionView          = projectAction{minRole: models.ProjectRoleViewer}
+	actionCreateTask    = projectAction{minRole: models.ProjectRoleReporter, changeContent: true}
+	actionComment       = projectAction{minRole: models.ProjectRoleReporter, changeContent: true}
+	actionAttach        = projectAction{minRole: models.ProjectRoleReporter, changeContent: true}
+	actionEditTask      = projectAction{minRole: models.ProjectRoleDeveloper, permission: models.PermissionTaskEdit, changeContent: true}
+	actionLogTime       = projectAction{minRole: models.ProjectRoleDeveloper, changeContent: true}
+	actionDeleteTask    = projectAction{minRole: models.ProjectRoleManager, permission: models.PermissionTaskDelete, changeContent: true}
+	actionModerate      = projectAction{minRole: models.ProjectRoleManager, changeContent: true}
+	actionDeleteAnyFile = projectAction{minRole: models.ProjectRoleManager, permission: models.PermissionAttachmentDeleteAny, changeContent: true}
+	actionManageMembers = projectAction{minRole: models.ProjectRoleManager, permission: models.PermissionProjectManageMembers}
+	actionManageProject = projectAction{minRole: models.ProjectRoleManager}
+)
+
+// ProjectRoleRepository is the part of *repositories.ProjectMemberRepository that ProjectAccess reads
+type ProjectRoleRepository interface {
+	GetProjectRole(ctx context.Context, projectID, userID uuid.UUID, systemRole string) (string, bool, error)
+	GetDeletedProjectRole(ctx context.Context, projectID, userID uuid.UUID, systemRole string) (string, bool, error)
+	GetTaskProjectRole(ctx context.Context, taskID, userID uuid.UUID, systemRole string) (string, bool, error)
+	GetGroup(ctx context.Context, projectID, groupID uuid.UUID) (*models.ProjectGroup, error)
+}
+
+// ProjectAccess is the single membership check behind every project-scoped service
+type ProjectAccess struct {
+	memberRepo ProjectRoleRepository
+	roleRepo   repositories.RoleRepository
+	statusRepo repositories.ProjectStatusRepository
+}
+
+func NewProjectAccess(memberRepo ProjectRoleRepository, roleRepo repositories.RoleRepository, statusRepo repositories.ProjectStatusRepository) *ProjectAccess {
+	return &ProjectAccess{memberRepo: memberRepo, roleRepo: roleRepo, statusRepo: statusRepo}
+}
+
+// Role returns the user's effective role in the project. Missing projects and projects hidden
+// from the user both yield models.ErrNotFound, so their existence is not revealed.
+func (a *ProjectAccess) Role(ctx context.Context, projectID, userID uuid.UUID, systemRole string) (string, error) {
+	role, exists, err := a.memberRepo.GetProjectRole(ctx, projectID, userID, systemRole)
+	if err != nil {
+		return "", err
+	}
+	return visibleRole(role, exists)
+}
+
+// TaskRole is Role for the project the task belongs to; missing tasks also yield models.ErrNotFound
+func (a *ProjectAccess) TaskRole(ctx context.Context, taskID, userID uuid.UUID, systemRole string) (string, error) {
+	role, exists, err := a.memberRepo.GetTaskProjectRole(ctx, taskID, userID, systemRole)
+	if err != nil {
+		return "", err
+	}
+	return visibleRole(role, exists)
+}
+
+// HasGroup reports whether the group is a member of the project
+func (a *ProjectAccess) HasGroup(ctx context.Context, projectID, groupID uuid.UUID) (bool, error) {
+	group, err := a.memberRepo.GetGroup(ctx, projectID, groupID)
+	if err != nil {
+		return false, err
+	}
+	return group != nil, nil
+}
+
+// Require checks the user may perform the action in the project
+func (a *ProjectAccess) Require(ctx context.Context, projectID, userID uuid.UUID, systemRole string, action projectAction) (string, error) {
+	role, err := a.Role(ctx, projectID, userID, systemRole)
+	if err != nil {
+		return "", err
+	}
+	if err := a.authorize(ctx, role, systemRole, action); err != nil {
+		return role, err
+	}
+	if action.changeContent {
+		return role, a.RequireWritable(ctx, projectID)
+	}
+	return role, nil
+}
+
+// RequireTask checks the user may perform the action in the task's project
+func (a *ProjectAccess) RequireTask(ctx context.Context, taskID, userID uuid.UUID, systemRole string, action projectAction) (string, error) {
+	role, err := a.TaskRole(ctx, taskID, userID, systemRole)
+	if err != nil {
+		return "", err
+	}
+	if err := a.authorize(ctx, role, systemRole, action); err != nil {
+		return role, err
+	}
+	if action.changeContent {
+		return role, a.RequireWritableTask(ctx, taskID)
+	}
+	return role, nil
+}
+
+// RequireDeleted is Require for a project in the trash, e.g. before restoring it. The project's
+// status is not checked, as nothing in it changes until it is back.
+func (a *ProjectAccess) RequireDeleted(ctx context.Context, projectID, userID uuid.UUID, systemRole string, action projectAction) error {
+	role, exists, err := a.memberRepo.GetDeletedProjectRole(ctx, projectID, userID, systemRole)
+	if err != nil {
+		return err
+	}
+	role, err = visibleRole(role, exists)
+	if err != nil {
+		return err
+	}
+	return a.authorize(ctx, role, systemRole, action)
+}
+
+// RequireWritable returns ErrProjectReadOnly when the project's status freezes its content
+func (a *ProjectAccess) RequireWritable(ctx context.Context, projectID uuid.UUID) error {
+	readOnly, err := a.statusRepo.IsProjectReadOnly(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if readOnly {
+		return ErrProjectReadOnly
+	}
+	return nil
+}
+
+// RequireWritableTask is RequireWritable for the project the task belongs to
+func (a *ProjectAccess) RequireWritableTask(ctx context.Context, taskID uuid.UUID) error {
+	readOnly, err := a.statusRepo.IsTaskProjectReadOnly(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if readOnly {
+		return ErrProjectReadOnly
+	}
+	return nil
+}
+
+// Allows reports whether a user with the given project role may perform the action
+func (a *ProjectAccess) Allows(ctx context.Context, projectRole, systemRole string, action projectAction) (bool, error) {
+	err := a.authorize(ctx, projectRole, systemRole, action)
+	if err == ErrProjectForbidden {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// HasPermission reports whether the system role grants the permission
+func (a *ProjectAccess) HasPermission(ctx context.Context, systemRole, permission string) (bool, error) {
+	return a.roleRepo.HasPermission(ctx, systemRole, permission)
+}
+
+// authorize returns ErrProjectForbidden unless the project role or the system role's permissions allow the action
+func (a *ProjectAccess) authorize(ctx context.Context, projectRole, systemRole string, action projectAction) error {
+	if models.ProjectRoleAtLeast(projectRole, action.minRole) {
+		return nil
+	}
+	if action.permission != "" {
+		granted, err := a.roleRepo.HasPermission(ctx, systemRole, action.permission)
+		if err != nil {
+			return err
+		}
+		if granted {
+			return nil
+		}
+	}
+	return ErrProjectForbidden
+}
+
+// visibleRole turns a hidden or missing project into models.ErrNotFound
+func visibleRole(role string, exists bool) (string, error) {
+	if !exists || role == "" {
+		return "", models.ErrNotFound
+	}
+	return role, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrCopyAttachmentsWithoutTasks = errors.New("attachments can only be copied together with tasks")
+
+// GetTemplates returns the project templates the user can see
+func (s *ProjectService) GetTemplates(ctx context.Context, userID uuid.UUID, role string, projectIDs []uuid.UUID) ([]models.Project, error) {
+	projects, _, err := s.repo.List(ctx, userID, role, models.ProjectFilter{
+		Templates:  true,
+		ProjectIDs: projectIDs,
+		Sort:       "title",
+	})
+	return projects, err
+}
+
+// CopyProject creates a project from one the user can see, usually a template. Copying members needs
+// the member management right on the source. Stored files are duplicated before the database copy and
+// removed again if it fails.
+func (s *ProjectService) CopyProject(ctx context.Context, sourceID uuid.UUID, userID uuid.UUID, role string, req models.CopyProjectRequest) (*models.Project, error) {
+	if _, err := s.access.Role(ctx, sourceID, userID, role); err != nil {
+		return nil, err
+	}
+	if req.Members {
+		if _, err := s.access.Require(ctx, sourceID, userID, role, actionManageMembers); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Title == "" {
+		return nil, models.ErrValidation
+	}
+	if req.Attachments && !req.Tasks {
+		return nil, ErrCopyAttachmentsWithoutTasks
+	}
+
+	status, err := s.resolveNewProjectStatus(ctx, req.Status)
+	if err != nil {
+		return nil, err
+	}
+	req.Status = status
+
+	if err := s.ValidateProjectIdentifier(req.Identifier); err != nil {
+		return nil, err
+	}
+	if req.ParentID != nil {
+		if err := s.validateParent(ctx, nil, *req.ParentID, userID, role); err != nil {
+			return nil, err
+		}
+	}
+
+	dayShift := 0
+	if req.StartDate != nil {
+		reference, err := s.repo.GetCopyReferenceDate(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		dayShift = copyDayShift(reference, *req.StartDate)
+	}
+
+	var files []models.CopiedFile
+	if req.Attachments {
+		files, err = s.copyAttachmentFiles(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	project, err := s.repo.Copy(ctx, sourceID, req, userID, dayShift, files)
+	if err != nil {
+		s.removeCopiedFiles(files)
+		return nil, err
+	}
+	return project, nil
+}
+
+// copyAttachmentFiles duplicates the stored files of the project's attachments under new names
+func (s *ProjectService) copyAttachmentFiles(ctx context.Context, projectID uuid.UUID) ([]models.CopiedFile, error) {
+	attachments, err := s.attachmentRepo.GetByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]models.CopiedFile, 0, len(attachments))
+	for _, attachment := range attachments {
+		filePath, storedFilename, err := s.fileStorage.GenerateSecureFilePath(attachment.OriginalFilename)
+		if err == nil {
+			err = s.fileStorage.CopyFile(attachment.FilePath, filePath)
+		}
+		if err != nil {
+			s.removeCopiedFiles(files)
+			return nil, err
+		}
+		file := models.CopiedFile{SourceID: attachment.ID, StoredFilename: storedFilename, FilePath: filePath}
+
+		// A thumbnail that cannot be copied is left out; the copy still has the file itself
+		if attachment.HasThumbnail && attachment.ThumbnailPath != nil {
+			if thumbnailPath, _, err := s.fileStorage.GenerateThumbnailPath(filePath); err == nil && s.fileStorage.CopyFile(*attachment.ThumbnailPath, thumbnailPath) == nil {
+				file.ThumbnailPath = &thumbnailPath
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (s *ProjectService) removeCopiedFiles(files []models.CopiedFile) {
+	for _, file := range files {
+		thumbnailPath := ""
+		if file.ThumbnailPath != nil {
+			thumbnailPath = *file.ThumbnailPath
+		}
+		s.fileStorage.DeleteFileWithThumbnail(file.FilePath, thumbnailPath)
+	}
+}
+
+// copyDayShift is the number of days dates move so that reference lands on start
+func copyDayShift(reference *time.Time, start time.Time) int {
+	if reference == nil {
+		return 0
+	}
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	return int(day(start).Sub(day(*reference)).Hours() / 24)
+}
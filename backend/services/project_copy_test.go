@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCopyDayShift(t *testing.T) {
+	reference := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	if got := copyDayShift(nil, reference); got != 0 {
+		t.Fatalf("copyDayShift without reference = %d, want 0", got)
+	}
+
+	cases := []struct {
+		start time.Time
+		want  int
+	}{
+		{time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), 10},
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), -9},
+		// Only the calendar day of the new start date counts
+		{time.Date(2026, 3, 11, 23, 30, 0, 0, time.UTC), 1},
+		{reference, 0},
+	}
+	for _, tc := range cases {
+		if got := copyDayShift(&reference, tc.start); got != tc.want {
+			t.Fatalf("copyDayShift(%s) = %d, want %d", tc.start.Format(time.RFC3339), got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+)
+
+func TestProjectCursorRoundTrip(t *testing.T) {
+	for _, cursor := range []models.ProjectCursor{
+		{Sort: "title", Key: "وب سایت", ID: uuid.New()},
+		{Sort: "created_at", Key: "2026-01-01 00:00:00+00", ID: uuid.New(), OpenFirst: true, Closed: true},
+	} {
+		decoded, err := DecodeProjectCursor(EncodeProjectCursor(cursor))
+		if err != nil {
+			t.Fatalf("DecodeProjectCursor: %v", err)
+		}
+		if *decoded != cursor {
+			t.Fatalf("decoded cursor = %+v, want %+v", *decoded, cursor)
+		}
+	}
+
+	for _, token := range []string{"not base64!", "e30", EncodeProjectCursor(models.ProjectCursor{Sort: "title"})} {
+		if _, err := DecodeProjectCursor(token); err != ErrInvalidProjectCursor {
+			t.Fatalf("DecodeProjectCursor(%q): err = %v, want ErrInvalidProjectCursor", token, err)
+		}
+	}
+}
+
+func TestListProjectsRejectsBadSortAndCursor(t *testing.T) {
+	service := NewProjectService(nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	if _, err := service.ListProjects(ctx, uuid.New(), "user", models.ProjectFilter{Sort: "owner"}); err != ErrInvalidProjectSort {
+		t.Fatalf("unknown sort: err = %v, want ErrInvalidProjectSort", err)
+	}
+
+	// A cursor only continues the ordering it was issued for
+	filter := models.ProjectFilter{Sort: "created_at", After: &models.ProjectCursor{Sort: "title", Key: "a", ID: uuid.New()}}
+	if _, err := service.ListProjects(ctx, uuid.New(), "user", filter); err != ErrInvalidProjectCursor {
+		t.Fatalf("cursor for another sort: err = %v, want ErrInvalidProjectCursor", err)
+	}
+
+	// The default order lists open projects first, so its cursors do not continue an explicit sort
+	filter = models.ProjectFilter{Sort: "created_at", After: &models.ProjectCursor{Sort: "created_at", Key: "a", ID: uuid.New(), OpenFirst: true}}
+	if _, err := service.ListProjects(ctx, uuid.New(), "user", filter); err != ErrInvalidProjectCursor {
+		t.Fatalf("open-first cursor for an explicit sort: err = %v, want ErrInvalidProjectCursor", err)
+	}
+}
+
+func TestProjectPageSize(t *testing.T) {
+	cases := map[int]int{0: defaultProjectPageSize, -5: defaultProjectPageSize, 20: 20, 1000: maxProjectPageSize}
+	for limit, want := range cases {
+		if got := ProjectPageSize(limit); got != want {
+			t.Fatalf("ProjectPageSize(%d) = %d, want %d", limit, got, want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidProjectRole    = errors.New("role must be one of manager, developer, reporter, viewer")
+	ErrProjectMemberExists   = errors.New("user is already a member of this project")
+	ErrProjectMemberNotFound = errors.New("project member not found")
+	ErrProjectMemberUser     = errors.New("user not found or inactive")
+	ErrProjectGroupExists    = errors.New("group is already a member of this project")
+	ErrProjectGroupNotFound  = errors.New("project group not found")
+	ErrProjectGroupUnknown   = errors.New("group not found")
+)
+
+type ProjectMemberService struct {
+	repo      *repositories.ProjectMemberRepository
+	userRepo  repositories.UserRepository
+	groupRepo repositories.GroupRepository
+	access    *ProjectAccess
+}
+
+func NewProjectMemberService(repo *repositories.ProjectMemberRepository, userRepo repositories.UserRepository, groupRepo repositories.GroupRepository, access *ProjectAccess) *ProjectMemberService {
+	return &ProjectMemberService{repo: repo, userRepo: userRepo, groupRepo: groupRepo, access: access}
+}
+
+// GetMembers lists a project's members for anyone who can see the project
+func (s *ProjectMemberService) GetMembers(ctx context.Context, projectID, userID uuid.UUID, systemRole string) ([]models.ProjectMember, error) {
+	if _, err := s.access.Role(ctx, projectID, userID, systemRole); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByProjectID(ctx, projectID)
+}
+
+// AddMember grants a user a role in the project (managers only)
+func (s *ProjectMemberService) AddMember(ctx context.Context, projectID, userID uuid.UUID, systemRole string, req models.AddProjectMemberRequest) (*models.ProjectMember, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageMembers); err != nil {
+		return nil, err
+	}
+	if req.Role == "" {
+		req.Role = models.ProjectRoleDeveloper
+	}
+	if !models.IsValidProjectRole(req.Role) {
+		return nil, ErrInvalidProjectRole
+	}
+
+	var user *models.User
+	var err error
+	if req.UserID == uuid.Nil && req.Email != "" {
+		user, err = s.userRepo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(req.Email)))
+	} else {
+		user, err = s.userRepo.GetByID(ctx, req.UserID)
+	}
+	if err != nil || user == nil || !user.IsActive {
+		return nil, ErrProjectMemberUser
+	}
+
+	added, err := s.repo.Add(ctx, projectID, user.ID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrProjectMemberExists
+	}
+
+	return s.repo.Get(ctx, projectID, user.ID)
+}
+
+// UpdateMemberRole changes a member's role (managers only)
+func (s *ProjectMemberService) UpdateMemberRole(ctx context.Context, projectID, memberID, userID uuid.UUID, systemRole string, req models.UpdateProjectMemberRequest) (*models.ProjectMember, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageMembers); err != nil {
+		return nil, err
+	}
+	if !models.IsValidProjectRole(req.Role) {
+		return nil, ErrInvalidProjectRole
+	}
+
+	updated, err := s.repo.UpdateRole(ctx, projectID, memberID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrProjectMemberNotFound
+	}
+
+	return s.repo.Get(ctx, projectID, memberID)
+}
+
+// RemoveMember removes a member; managers may remove anyone and members may leave
+func (s *ProjectMemberService) RemoveMember(ctx context.Context, projectID, memberID, userID uuid.UUID, systemRole string) error {
+	action := actionManageMembers
+	if memberID == userID {
+		action = actionView
+	}
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, action); err != nil {
+		return err
+	}
+
+	removed, err := s.repo.Remove(ctx, projectID, memberID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrProjectMemberNotFound
+	}
+	return nil
+}
+
+// GetGroups lists the groups that are members of a project for anyone who can see it
+func (s *ProjectMemberService) GetGroups(ctx context.Context, projectID, userID uuid.UUID, systemRole string) ([]models.ProjectGroup, error) {
+	if _, err := s.access.Role(ctx, projectID, userID, systemRole); err != nil {
+		return nil, err
+	}
+	return s.repo.GetGroupsByProjectID(ctx, projectID)
+}
+
+// AddGroup grants every member of a group a role in the project (managers only)
+func (s *ProjectMemberService) AddGroup(ctx context.Context, projectID, userID uuid.UUID, systemRole string, req models.AddProjectGroupRequest) (*models.ProjectGroup, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageMembers); err != nil {
+		return nil, err
+	}
+	if req.Role == "" {
+		req.Role = models.ProjectRoleDeveloper
+	}
+	if !models.IsValidProjectRole(req.Role) {
+		return nil, ErrInvalidProjectRole
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, req.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrProjectGroupUnknown
+	}
+
+	added, err := s.repo.AddGroup(ctx, projectID, group.ID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrProjectGroupExists
+	}
+
+	return s.repo.GetGroup(ctx, projectID, group.ID)
+}
+
+// UpdateGroupRole changes the role a group has in the project (managers only)
+func (s *ProjectMemberService) UpdateGroupRole(ctx context.Context, projectID, groupID, userID uuid.UUID, systemRole string, req models.UpdateProjectMemberRequest) (*models.ProjectGroup, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageMembers); err != nil {
+		return nil, err
+	}
+	if !models.IsValidProjectRole(req.Role) {
+		return nil, ErrInvalidProjectRole
+	}
+
+	updated, err := s.repo.UpdateGroupRole(ctx, projectID, groupID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrProjectGroupNotFound
+	}
+
+	return s.repo.GetGroup(ctx, projectID, groupID)
+}
+
+// RemoveGroup takes a group out of the project (managers only)
+func (s *ProjectMemberService) RemoveGroup(ctx context.Context, projectID, groupID, userID uuid.UUID, systemRole string) error {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageMembers); err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveGroup(ctx, projectID, groupID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrProjectGroupNotFound
+	}
+	return nil
+}
@@ -2,8 +2,9 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"net/url"
 	"project-management/models"
 	"project-management/repositories"
@@ -12,56 +13,121 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrParentProjectNotFound = errors.New("parent project not found")
+	ErrProjectParentCycle    = errors.New("a project cannot be moved under itself or one of its subprojects")
+	ErrProjectHasSubprojects = errors.New("project has subprojects; delete them first or pass cascade=true")
+	ErrUnknownProjectStatus  = errors.New("unknown project status")
+	ErrInvalidProjectSort    = errors.New("unknown sort field")
+	ErrInvalidProjectCursor  = errors.New("invalid or expired cursor")
+)
+
+const (
+	defaultProjectPageSize = 50
+	maxProjectPageSize     = 100
+)
+
 type ProjectService struct {
-	repo *repositories.ProjectRepository
+	repo           *repositories.ProjectRepository
+	statusRepo     repositories.ProjectStatusRepository
+	attachmentRepo *repositories.AttachmentRepository
+	fileStorage    *FileStorageService
+	access         *ProjectAccess
 }
 
-func NewProjectService(repo *repositories.ProjectRepository) *ProjectService {
-	return &ProjectService{repo: repo}
+func NewProjectService(repo *repositories.ProjectRepository, statusRepo repositories.ProjectStatusRepository, attachmentRepo *repositories.AttachmentRepository, fileStorage *FileStorageService, access *ProjectAccess) *ProjectService {
+	return &ProjectService{repo: repo, statusRepo: statusRepo, attachmentRepo: attachmentRepo, fileStorage: fileStorage, access: access}
 }
 
 func (s *ProjectService) GetAllProjects(ctx context.Context) ([]models.Project, error) {
 	return s.repo.GetAll(ctx)
 }
 
-// GetProjectsByUser returns all projects for admins, or only projects created by the user for regular users
-func (s *ProjectService) GetProjectsByUser(ctx context.Context, userID uuid.UUID, role string) ([]models.Project, error) {
-	// Admins can see all projects
-	if role == "admin" {
-		return s.repo.GetAll(ctx)
+// ListProjects returns the projects the user can see that match the filter. Without a limit every
+// match is returned; with one, the page carries a cursor for the next page.
+func (s *ProjectService) ListProjects(ctx context.Context, userID uuid.UUID, role string, filter models.ProjectFilter) (*models.ProjectPage, error) {
+	if !isProjectSortField(filter.Sort) {
+		return nil, ErrInvalidProjectSort
+	}
+	if filter.After != nil && (filter.After.Sort != filter.Sort || filter.After.OpenFirst != filter.OpenFirst) {
+		return nil, ErrInvalidProjectCursor
+	}
+	if filter.Limit > maxProjectPageSize {
+		filter.Limit = maxProjectPageSize
 	}
 
-	// Regular users only see projects they created OR public projects
-	allProjects, err := s.repo.GetAll(ctx)
+	projects, next, err := s.repo.List(ctx, userID, role, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Filter projects by user_id, created_by, or is_public
-	var userProjects []models.Project
-	for _, p := range allProjects {
-		if (p.UserID != nil && *p.UserID == userID) || (p.CreatedBy != nil && *p.CreatedBy == userID) || p.IsPublic {
-			userProjects = append(userProjects, p)
+	page := &models.ProjectPage{Projects: projects}
+	if next != nil {
+		page.NextCursor = EncodeProjectCursor(*next)
+		page.HasMore = true
+	}
+	return page, nil
+}
+
+// ProjectPageSize turns a requested page size into one ListProjects accepts
+func ProjectPageSize(limit int) int {
+	if limit <= 0 {
+		return defaultProjectPageSize
+	}
+	if limit > maxProjectPageSize {
+		return maxProjectPageSize
+	}
+	return limit
+}
+
+func isProjectSortField(field string) bool {
+	for _, f := range models.ProjectSortFields {
+		if f == field {
+			return true
 		}
 	}
+	return false
+}
 
-	return userProjects, nil
+// EncodeProjectCursor renders a cursor as an opaque URL-safe token
+func EncodeProjectCursor(cursor models.ProjectCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
 }
 
-func (s *ProjectService) GetProjectByID(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+// DecodeProjectCursor parses a token made by EncodeProjectCursor
+func DecodeProjectCursor(token string) (*models.ProjectCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidProjectCursor
+	}
+	var cursor models.ProjectCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidProjectCursor
+	}
+	return &cursor, nil
+}
+
+// GetProjectByID returns the project when the user can see it
+func (s *ProjectService) GetProjectByID(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) (*models.Project, error) {
+	if _, err := s.access.Role(ctx, id, userID, role); err != nil {
+		return nil, err
+	}
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *ProjectService) CreateProject(ctx context.Context, req models.CreateProjectRequest, createdBy *uuid.UUID) (*models.Project, error) {
+func (s *ProjectService) CreateProject(ctx context.Context, req models.CreateProjectRequest, createdBy *uuid.UUID, role string) (*models.Project, error) {
 	if req.Title == "" {
 		return nil, models.ErrValidation
 	}
-	if req.Status == "" {
-		req.Status = "active"
+	status, err := s.resolveNewProjectStatus(ctx, req.Status)
+	if err != nil {
+		return nil, err
 	}
+	req.Status = status
 
 	// Validate identifier
-	if err := s.ValidateProjectIdentifier(ctx, req.Identifier, nil); err != nil {
+	if err := s.ValidateProjectIdentifier(req.Identifier); err != nil {
 		return nil, err
 	}
 
@@ -70,16 +136,50 @@ func (s *ProjectService) CreateProject(ctx context.Context, req models.CreatePro
 		return nil, err
 	}
 
+	if req.ParentID != nil && createdBy != nil {
+		if err := s.validateParent(ctx, nil, *req.ParentID, *createdBy, role); err != nil {
+			return nil, err
+		}
+	}
+
 	return s.repo.Create(ctx, req, createdBy)
 }
 
-func (s *ProjectService) UpdateProject(ctx context.Context, id uuid.UUID, req models.UpdateProjectRequest) (*models.Project, error) {
+func (s *ProjectService) UpdateProject(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string, req models.UpdateProjectRequest) (*models.Project, error) {
+	if _, err := s.access.Require(ctx, id, userID, role, actionManageProject); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, models.ErrNotFound
+	}
+
 	if req.Title == "" {
 		return nil, models.ErrValidation
 	}
 
+	if req.IsTemplate == nil {
+		req.IsTemplate = &current.IsTemplate
+	}
+
+	if !req.ParentSet {
+		req.ParentID = current.ParentID
+	}
+
+	if req.Status == "" {
+		req.Status = current.Status
+	} else if req.Status != current.Status {
+		if err := s.ValidateProjectStatus(ctx, req.Status); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate identifier with exclusion of current project
-	if err := s.ValidateProjectIdentifier(ctx, req.Identifier, &id); err != nil {
+	if err := s.ValidateProjectIdentifier(req.Identifier); err != nil {
 		return nil, err
 	}
 
@@ -88,42 +188,151 @@ func (s *ProjectService) UpdateProject(ctx context.Context, id uuid.UUID, req mo
 		return nil, err
 	}
 
-	return s.repo.Update(ctx, id, req)
-}
+	// Moving the project needs manager rights on the new parent as well
+	if req.ParentID != nil && !sameID(req.ParentID, current.ParentID) {
+		if err := s.validateParent(ctx, &id, *req.ParentID, userID, role); err != nil {
+			return nil, err
+		}
+	}
 
-func (s *ProjectService) DeleteProject(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	return s.repo.Update(ctx, id, req)
 }
 
-// ValidateProjectIdentifier validates the project identifier format and checks uniqueness
-func (s *ProjectService) ValidateProjectIdentifier(ctx context.Context, identifier string, excludeID *uuid.UUID) error {
-	if identifier == "" {
-		return errors.New("identifier is required")
+// DeleteProject moves a project to the trash; with subprojects it refuses unless cascade is set,
+// and then the user must be able to manage every subproject too
+func (s *ProjectService) DeleteProject(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string, cascade bool) error {
+	if _, err := s.access.Require(ctx, id, userID, role, actionManageProject); err != nil {
+		return err
 	}
 
-	// Validate format: only alphanumeric, underscore, and hyphen allowed
-	matched, err := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, identifier)
+	descendants, err := s.repo.GetDescendantIDs(ctx, id)
 	if err != nil {
 		return err
 	}
-	if !matched {
-		return errors.New("identifier can only contain alphanumeric characters, underscores, and hyphens")
+	if len(descendants) == 0 {
+		return s.repo.Delete(ctx, id, userID)
+	}
+	if !cascade {
+		return ErrProjectHasSubprojects
 	}
 
-	// Check uniqueness by querying all projects
-	projects, err := s.repo.GetAll(ctx)
-	if err != nil {
+	for _, descendantID := range descendants {
+		if _, err := s.access.Require(ctx, descendantID, userID, role, actionManageProject); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return ErrProjectForbidden
+			}
+			return err
+		}
+	}
+	return s.repo.DeleteTree(ctx, id, userID)
+}
+
+// validateParent checks that the user manages the parent and, for an existing project, that no cycle is formed
+func (s *ProjectService) validateParent(ctx context.Context, id *uuid.UUID, parentID uuid.UUID, userID uuid.UUID, role string) error {
+	if id != nil && *id == parentID {
+		return ErrProjectParentCycle
+	}
+
+	if _, err := s.access.Require(ctx, parentID, userID, role, actionManageProject); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrParentProjectNotFound
+		}
 		return err
 	}
 
+	if id != nil {
+		descendants, err := s.repo.GetDescendantIDs(ctx, *id)
+		if err != nil {
+			return err
+		}
+		for _, descendantID := range descendants {
+			if descendantID == parentID {
+				return ErrProjectParentCycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameID reports whether two optional IDs are equal
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// BuildProjectTree nests projects under their parents; projects whose parent is not in the list become roots
+func BuildProjectTree(projects []models.Project) []models.ProjectNode {
+	present := make(map[uuid.UUID]bool, len(projects))
 	for _, p := range projects {
-		// Skip the project being updated
-		if excludeID != nil && p.ID == *excludeID {
-			continue
+		present[p.ID] = true
+	}
+
+	children := make(map[uuid.UUID][]models.Project)
+	var roots []models.Project
+	for _, p := range projects {
+		if p.ParentID != nil && present[*p.ParentID] {
+			children[*p.ParentID] = append(children[*p.ParentID], p)
+		} else {
+			roots = append(roots, p)
 		}
-		if p.Identifier == identifier {
-			return fmt.Errorf("identifier '%s' is already in use", identifier)
+	}
+
+	var build func(level []models.Project) []models.ProjectNode
+	build = func(level []models.Project) []models.ProjectNode {
+		nodes := make([]models.ProjectNode, 0, len(level))
+		for _, p := range level {
+			nodes = append(nodes, models.ProjectNode{Project: p, Children: build(children[p.ID])})
 		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+// resolveNewProjectStatus validates the status of a new project; new projects start in the first open
+// status unless one is given
+func (s *ProjectService) resolveNewProjectStatus(ctx context.Context, name string) (string, error) {
+	if name != "" {
+		return name, s.ValidateProjectStatus(ctx, name)
+	}
+	status, err := s.statusRepo.GetDefault(ctx)
+	if err != nil {
+		return "", err
+	}
+	if status == nil {
+		return "", ErrUnknownProjectStatus
+	}
+	return status.Name, nil
+}
+
+// ValidateProjectStatus checks the status is one of the configured project statuses
+func (s *ProjectService) ValidateProjectStatus(ctx context.Context, name string) error {
+	status, err := s.statusRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		return ErrUnknownProjectStatus
+	}
+	return nil
+}
+
+// ValidateProjectIdentifier validates the project identifier format; uniqueness is left to the database
+func (s *ProjectService) ValidateProjectIdentifier(identifier string) error {
+	if identifier == "" {
+		return errors.New("identifier is required")
+	}
+
+	// Validate format: only alphanumeric, underscore, and hyphen allowed
+	matched, err := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, identifier)
+	if err != nil {
+		return err
+	}
+	if !matched {
+		return errors.New("identifier can only contain alphanumeric characters, underscores, and hyphens")
 	}
 
 	return nil
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"project-management/models"
+	"project-management/repositories"
+)
+
+var (
+	ErrProjectStatusNotFound    = errors.New("وضعیت پروژه یافت نشد")
+	ErrProjectStatusExists      = errors.New("وضعیتی با این نام قبلاً تعریف شده است")
+	ErrInvalidProjectStatusName = errors.New("نام وضعیت الزامی است و حداکثر ۵۰ کاراکتر دارد")
+	ErrProjectStatusInUse       = errors.New("پروژه‌هایی با این وضعیت وجود دارند")
+	ErrLastOpenProjectStatus    = errors.New("حداقل یک وضعیت باز باید باقی بماند")
+)
+
+const projectStatusMaxNameLength = 50
+
+type ProjectStatusService interface {
+	ListStatuses(ctx context.Context) ([]models.ProjectStatus, error)
+	CreateStatus(ctx context.Context, req models.CreateProjectStatusRequest) (*models.ProjectStatus, error)
+	UpdateStatus(ctx context.Context, name string, req models.UpdateProjectStatusRequest) (*models.ProjectStatus, error)
+	DeleteStatus(ctx context.Context, name string) error
+}
+
+type projectStatusService struct {
+	repo         repositories.ProjectStatusRepository
+	auditService AuditService
+}
+
+func NewProjectStatusService(repo repositories.ProjectStatusRepository, auditService AuditService) ProjectStatusService {
+	return &projectStatusService{
+		repo:         repo,
+		auditService: auditService,
+	}
+}
+
+func (s *projectStatusService) ListStatuses(ctx context.Context) ([]models.ProjectStatus, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *projectStatusService) CreateStatus(ctx context.Context, req models.CreateProjectStatusRequest) (*models.ProjectStatus, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > projectStatusMaxNameLength {
+		return nil, ErrInvalidProjectStatusName
+	}
+
+	status := &models.ProjectStatus{
+		Name:       name,
+		IsClosed:   req.IsClosed,
+		IsReadOnly: req.IsReadOnly,
+		Position:   req.Position,
+	}
+	created, err := s.repo.Create(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrProjectStatusExists
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventProjectStatusCreated,
+		Outcome:   models.AuditOutcomeSuccess,
+		Details:   projectStatusAuditDetails(status.Name, status.IsClosed, status.IsReadOnly),
+	})
+
+	return status, nil
+}
+
+// UpdateStatus replaces the flags and position; the last open status cannot be closed
+func (s *projectStatusService) UpdateStatus(ctx context.Context, name string, req models.UpdateProjectStatusRequest) (*models.ProjectStatus, error) {
+	existing, err := s.getStatus(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !existing.IsClosed && req.IsClosed {
+		if err := s.requireAnotherOpenStatus(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	status, err := s.repo.Update(ctx, name, req)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, ErrProjectStatusNotFound
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventProjectStatusUpdated,
+		Outcome:   models.AuditOutcomeSuccess,
+		Details:   projectStatusAuditDetails(status.Name, status.IsClosed, status.IsReadOnly),
+	})
+
+	return status, nil
+}
+
+// DeleteStatus removes a status no project has; the last open status cannot be removed
+func (s *projectStatusService) DeleteStatus(ctx context.Context, name string) error {
+	existing, err := s.getStatus(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !existing.IsClosed {
+		if err := s.requireAnotherOpenStatus(ctx); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := s.repo.Delete(ctx, name)
+	if errors.Is(err, repositories.ErrProjectStatusInUse) {
+		return ErrProjectStatusInUse
+	}
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrProjectStatusNotFound
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventProjectStatusDeleted,
+		Outcome:   models.AuditOutcomeSuccess,
+		Details:   map[string]string{"status": name},
+	})
+
+	return nil
+}
+
+func (s *projectStatusService) getStatus(ctx context.Context, name string) (*models.ProjectStatus, error) {
+	status, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return nil, ErrProjectStatusNotFound
+	}
+	return status, nil
+}
+
+// requireAnotherOpenStatus keeps an open status around for new projects to start in
+func (s *projectStatusService) requireAnotherOpenStatus(ctx context.Context) error {
+	open, err := s.repo.CountOpen(ctx)
+	if err != nil {
+		return err
+	}
+	if open <= 1 {
+		return ErrLastOpenProjectStatus
+	}
+	return nil
+}
+
+func projectStatusAuditDetails(name string, closed, readOnly bool) map[string]string {
+	return map[string]string{
+		"status":    name,
+		"closed":    strconv.FormatBool(closed),
+		"read_only": strconv.FormatBool(readOnly),
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+// fakeProjectStatusRepository keeps statuses in memory; only the lookups the guards use are implemented
+type fakeProjectStatusRepository struct {
+	repositories.ProjectStatusRepository
+	statuses map[string]models.ProjectStatus
+}
+
+func (r *fakeProjectStatusRepository) GetByName(ctx context.Context, name string) (*models.ProjectStatus, error) {
+	status, ok := r.statuses[name]
+	if !ok {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+func (r *fakeProjectStatusRepository) CountOpen(ctx context.Context) (int, error) {
+	open := 0
+	for _, status := range r.statuses {
+		if !status.IsClosed {
+			open++
+		}
+	}
+	return open, nil
+}
+
+// IsProjectReadOnly reports every project as writable
+func (r *fakeProjectStatusRepository) IsProjectReadOnly(ctx context.Context, projectID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func TestProjectStatusKeepsAnOpenStatus(t *testing.T) {
+	repo := &fakeProjectStatusRepository{statuses: map[string]models.ProjectStatus{
+		"active":   {Name: "active"},
+		"archived": {Name: "archived", IsClosed: true, IsReadOnly: true},
+	}}
+	service := NewProjectStatusService(repo, nil)
+	ctx := context.Background()
+
+	if _, err := service.UpdateStatus(ctx, "active", models.UpdateProjectStatusRequest{IsClosed: true}); err != ErrLastOpenProjectStatus {
+		t.Fatalf("closing the last open status: err = %v, want ErrLastOpenProjectStatus", err)
+	}
+	if err := service.DeleteStatus(ctx, "active"); err != ErrLastOpenProjectStatus {
+		t.Fatalf("deleting the last open status: err = %v, want ErrLastOpenProjectStatus", err)
+	}
+	if err := service.DeleteStatus(ctx, "missing"); err != ErrProjectStatusNotFound {
+		t.Fatalf("deleting an unknown status: err = %v, want ErrProjectStatusNotFound", err)
+	}
+}
+
+func TestProjectStatusNameValidation(t *testing.T) {
+	service := NewProjectStatusService(&fakeProjectStatusRepository{}, nil)
+
+	for _, name := range []string{"", "   ", strings.Repeat("و", projectStatusMaxNameLength+1)} {
+		if _, err := service.CreateStatus(context.Background(), models.CreateProjectStatusRequest{Name: name}); err != ErrInvalidProjectStatusName {
+			t.Fatalf("CreateStatus(%q): err = %v, want ErrInvalidProjectStatusName", name, err)
+		}
+	}
+}
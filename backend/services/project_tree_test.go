@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildProjectTree(t *testing.T) {
+	root, child, grandchild, orphan, hidden := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	projects := []models.Project{
+		{ID: grandchild, Title: "grandchild", ParentID: &child},
+		{ID: root, Title: "root"},
+		{ID: child, Title: "child", ParentID: &root},
+		// The parent is not visible to the user, so this becomes a root
+		{ID: orphan, Title: "orphan", ParentID: &hidden},
+	}
+
+	tree := BuildProjectTree(projects)
+	if len(tree) != 2 {
+		t.Fatalf("got %d roots, want 2", len(tree))
+	}
+	// Roots keep the order of the input list
+	if tree[0].ID != root || tree[1].ID != orphan {
+		t.Fatalf("roots = [%s %s], want [root orphan]", tree[0].Title, tree[1].Title)
+	}
+
+	if len(tree[0].Children) != 1 || tree[0].Children[0].ID != child {
+		t.Fatalf("root children = %+v, want [child]", tree[0].Children)
+	}
+	if len(tree[0].Children[0].Children) != 1 || tree[0].Children[0].Children[0].ID != grandchild {
+		t.Fatalf("child children = %+v, want [grandchild]", tree[0].Children[0].Children)
+	}
+
+	// Leaves serialize children as [] rather than null
+	if tree[1].Children == nil {
+		t.Error("leaf children is nil, want empty slice")
+	}
+
+	if got := BuildProjectTree(nil); len(got) != 0 || got == nil {
+		t.Errorf("BuildProjectTree(nil) = %#v, want empty slice", got)
+	}
+}
+
+func TestSameID(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	aCopy := a
+
+	cases := []struct {
+		name string
+		x, y *uuid.UUID
+		want bool
+	}{
+		{"both nil", nil, nil, true},
+		{"one nil", &a, nil, false},
+		{"equal", &a, &aCopy, true},
+		{"different", &a, &b, false},
+	}
+
+	for _, tc := range cases {
+		if got := sameID(tc.x, tc.y); got != tc.want {
+			t.Errorf("%s: sameID = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
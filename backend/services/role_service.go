@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"project-management/models"
+	"project-management/repositories"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("نقش یافت نشد")
+	ErrRoleExists        = errors.New("نقشی با این نام قبلاً تعریف شده است")
+	ErrInvalidRoleName   = errors.New("نام نقش باید با حرف کوچک انگلیسی شروع شود و فقط شامل حروف کوچک، عدد، - و _ باشد (حداکثر ۲۰ کاراکتر)")
+	ErrInvalidPermission = errors.New("دسترسی نامعتبر است")
+	ErrBuiltinRole       = errors.New("نقش‌های پیش‌فرض قابل حذف نیستند")
+	ErrAdminRoleLocked   = errors.New("دسترسی‌های نقش ادمین قابل تغییر نیست")
+	ErrRoleInUse         = errors.New("این نقش به کاربران یا دعوت‌نامه‌ها اختصاص داده شده است")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,19}$`)
+
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	GetRole(ctx context.Context, name string) (*models.Role, error)
+	CreateRole(ctx context.Context, req models.CreateRoleRequest) (*models.Role, error)
+	UpdateRole(ctx context.Context, name string, req models.UpdateRoleRequest) (*models.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+type roleService struct {
+	roleRepo     repositories.RoleRepository
+	auditService AuditService
+}
+
+func NewRoleService(roleRepo repositories.RoleRepository, auditService AuditService) RoleService {
+	return &roleService{
+		roleRepo:     roleRepo,
+		auditService: auditService,
+	}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+func (s *roleService) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (s *roleService) CreateRole(ctx context.Context, req models.CreateRoleRequest) (*models.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, ErrInvalidRoleName
+	}
+
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Permissions: permissions,
+	}
+	created, err := s.roleRepo.Create(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrRoleExists
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventRoleCreated,
+		Outcome:   models.AuditOutcomeSuccess,
+		Details:   map[string]string{"role": name, "permissions": strings.Join(permissions, ",")},
+	})
+
+	return role, nil
+}
+
+func (s *roleService) UpdateRole(ctx context.Context, name string, req models.UpdateRoleRequest) (*models.Role, error) {
+	existing, err := s.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Admins must never be able to lock everyone out of administration
+	if existing.Name == models.SystemRoleAdmin && strings.Join(permissions, ",") != strings.Join(existing.Permissions, ",") {
+		return nil, ErrAdminRoleLocked
+	}
+
+	role, err := s.roleRepo.Update(ctx, name, strings.TrimSpace(req.Description), permissions)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventRoleUpdated,
+		Outcome:   models.AuditOutcomeSuccess,
+		Details: map[string]string{
+			"role": name,
+			"from": strings.Join(existing.Permissions, ","),
+			"to":   strings.Join(permissions, ","),
+		},
+	})
+
+	return role, nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, name string) error {
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.IsBuiltin {
+		return ErrBuiltinRole
+	}
+
+	deleted, err := s.roleRepo.Delete(ctx, name)
+	if errors.Is(err, repositories.ErrRoleInUse) {
+		return ErrRoleInUse
+	}
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrRoleNotFound
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventRoleDeleted,
+		Outcome:   models.AuditOutcomeSuccess,
+		Details:   map[string]string{"role": name},
+	})
+
+	return nil
+}
+
+// HasPermission reports whether the system role grants the permission (used by RequirePermission)
+func (s *roleService) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	return s.roleRepo.HasPermission(ctx, role, permission)
+}
+
+// requireRoleExists returns ErrInvalidRole unless the system role is defined
+func requireRoleExists(ctx context.Context, roleRepo repositories.RoleRepository, name string) error {
+	role, err := roleRepo.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// isAdministrator reports whether the role grants role.manage; holders can grant themselves anything
+// else, so at least one must stay active and they are asked to set up two-factor authentication
+func isAdministrator(ctx context.Context, roleRepo repositories.RoleRepository, role string) (bool, error) {
+	return roleRepo.HasPermission(ctx, role, models.PermissionRoleManage)
+}
+
+// normalizePermissions rejects unknown permissions and returns the rest deduplicated in catalogue order
+func normalizePermissions(permissions []string) ([]string, error) {
+	requested := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !models.IsValidPermission(p) {
+			return nil, ErrInvalidPermission
+		}
+		requested[p] = true
+	}
+
+	normalized := []string{}
+	for _, p := range models.AllPermissions {
+		if requested[p] {
+			normalized = append(normalized, p)
+		}
+	}
+	return normalized, nil
+}
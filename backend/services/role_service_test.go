@@ -0,0 +1,44 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"project-management/models"
+)
+
+func TestNormalizePermissions(t *testing.T) {
+	got, err := normalizePermissions([]string{models.PermissionAuditView, " task.edit ", models.PermissionAuditView})
+	if err != nil {
+		t.Fatalf("normalizePermissions: %v", err)
+	}
+	// Deduplicated and in catalogue order
+	want := []string{models.PermissionTaskEdit, models.PermissionAuditView}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizePermissions = %v, want %v", got, want)
+	}
+
+	if got, err := normalizePermissions(nil); err != nil || len(got) != 0 {
+		t.Fatalf("normalizePermissions(nil) = %v, %v; want empty", got, err)
+	}
+
+	if _, err := normalizePermissions([]string{"task.fly"}); err != ErrInvalidPermission {
+		t.Fatalf("unknown permission: err = %v, want ErrInvalidPermission", err)
+	}
+}
+
+func TestRoleNamePattern(t *testing.T) {
+	valid := []string{"reviewer", "qa_lead", "team-1"}
+	invalid := []string{"", "a", "Reviewer", "1team", "name with space", "averyveryverylongrolename"}
+
+	for _, name := range valid {
+		if !roleNamePattern.MatchString(name) {
+			t.Fatalf("%q should be a valid role name", name)
+		}
+	}
+	for _, name := range invalid {
+		if roleNamePattern.MatchString(name) {
+			t.Fatalf("%q should be rejected", name)
+		}
+	}
+}
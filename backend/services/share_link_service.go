@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrShareLinkInvalid  = errors.New("share link not found or expired")
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrShareLinkLabel    = errors.New("label must be at most 100 characters")
+	ErrShareLinkExpiry   = errors.New("expiry must be in the future and at most one year away")
+	ErrShareNotIncluded  = errors.New("this share link does not include that content")
+)
+
+const (
+	shareLinkTokenPrefix    = "pmshr_"
+	shareLinkDisplayLength  = 12 // Prefix plus a few random characters, shown in listings
+	shareLinkDefaultExpiry  = 30 * 24 * time.Hour
+	shareLinkMaxExpiry      = 365 * 24 * time.Hour
+	shareLinkMaxLabelLength = 100
+	shareLinkAccessLogLimit = 100
+)
+
+// ShareVisit describes an anonymous request made through a share link
+type ShareVisit struct {
+	Path      string
+	IPAddress string
+	UserAgent string
+}
+
+type ShareLinkService struct {
+	repo           *repositories.ShareLinkRepository
+	projectRepo    *repositories.ProjectRepository
+	taskRepo       *repositories.TaskRepository
+	commentRepo    *repositories.CommentRepository
+	attachmentRepo *repositories.AttachmentRepository
+	access         *ProjectAccess
+}
+
+func NewShareLinkService(repo *repositories.ShareLinkRepository, projectRepo *repositories.ProjectRepository, taskRepo *repositories.TaskRepository, commentRepo *repositories.CommentRepository, attachmentRepo *repositories.AttachmentRepository, access *ProjectAccess) *ShareLinkService {
+	return &ShareLinkService{
+		repo:           repo,
+		projectRepo:    projectRepo,
+		taskRepo:       taskRepo,
+		commentRepo:    commentRepo,
+		attachmentRepo: attachmentRepo,
+		access:         access,
+	}
+}
+
+// ListLinks returns every share link of the project (managers only)
+func (s *ShareLinkService) ListLinks(ctx context.Context, projectID, userID uuid.UUID, systemRole string) ([]models.ShareLink, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageProject); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByProject(ctx, projectID)
+}
+
+// CreateLink issues a new share link (managers only). The plaintext token is only returned here.
+func (s *ShareLinkService) CreateLink(ctx context.Context, projectID, userID uuid.UUID, systemRole string, req models.CreateShareLinkRequest) (*models.CreatedShareLink, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageProject); err != nil {
+		return nil, err
+	}
+
+	label := strings.TrimSpace(req.Label)
+	if len([]rune(label)) > shareLinkMaxLabelLength {
+		return nil, ErrShareLinkLabel
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(shareLinkDefaultExpiry)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(shareLinkMaxExpiry)) {
+		return nil, ErrShareLinkExpiry
+	}
+
+	plaintext, err := generateShareLinkToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.ShareLink{
+		ProjectID:          projectID,
+		Label:              label,
+		TokenHash:          hashToken(plaintext),
+		TokenPrefix:        plaintext[:shareLinkDisplayLength],
+		IncludeAttachments: req.IncludeAttachments,
+		IncludeComments:    req.IncludeComments,
+		CreatedBy:          &userID,
+		ExpiresAt:          expiresAt,
+	}
+	if err := s.repo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedShareLink{ShareLink: *link, Token: plaintext}, nil
+}
+
+// RevokeLink disables a share link immediately (managers only)
+func (s *ShareLinkService) RevokeLink(ctx context.Context, projectID, linkID, userID uuid.UUID, systemRole string) error {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageProject); err != nil {
+		return err
+	}
+
+	revoked, err := s.repo.Revoke(ctx, projectID, linkID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// ListAccesses returns the latest requests made through a link (managers only)
+func (s *ShareLinkService) ListAccesses(ctx context.Context, projectID, linkID, userID uuid.UUID, systemRole string) ([]models.ShareLinkAccess, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, systemRole, actionManageProject); err != nil {
+		return nil, err
+	}
+
+	link, err := s.repo.GetByID(ctx, projectID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, ErrShareLinkNotFound
+	}
+	return s.repo.ListAccesses(ctx, link.ID, shareLinkAccessLogLimit)
+}
+
+// Open resolves an active share link and logs the visit
+func (s *ShareLinkService) Open(ctx context.Context, token string, visit ShareVisit) (*models.ShareLink, error) {
+	if !strings.HasPrefix(token, shareLinkTokenPrefix) {
+		return nil, ErrShareLinkInvalid
+	}
+
+	link, err := s.repo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if link == nil || link.RevokedAt != nil || !time.Now().Before(link.ExpiresAt) {
+		return nil, ErrShareLinkInvalid
+	}
+
+	// Logging must not fail the request
+	if err := s.repo.RecordAccess(ctx, link.ID, visit.Path, visit.IPAddress, visit.UserAgent); err != nil {
+		log.Printf("Failed to log share link %s access: %v", link.ID, err)
+	}
+
+	return link, nil
+}
+
+// GetProject returns the shared project with its tasks and progress
+func (s *ShareLinkService) GetProject(ctx context.Context, link *models.ShareLink) (*models.SharedProject, error) {
+	project, err := s.projectRepo.GetByID(ctx, link.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrShareLinkInvalid
+	}
+
+	tasks, err := s.taskRepo.GetByProjectID(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := &models.SharedProject{
+		Title:              project.Title,
+		Description:        project.Description,
+		Status:             project.Status,
+		StartDate:          project.StartDate,
+		DueDate:            project.DueDate,
+		TotalTasks:         len(tasks),
+		IncludeAttachments: link.IncludeAttachments,
+		IncludeComments:    link.IncludeComments,
+		ExpiresAt:          link.ExpiresAt,
+		Tasks:              make([]models.SharedTask, 0, len(tasks)),
+	}
+	for _, t := range tasks {
+		if t.Completed {
+			shared.CompletedTasks++
+		}
+		shared.Tasks = append(shared.Tasks, models.SharedTask{
+			ID:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    t.Priority,
+			Completed:   t.Completed,
+			Category:    t.Category,
+			StartDate:   t.StartDate,
+			DueDate:     t.DueDate,
+			DoneRatio:   t.DoneRatio,
+			UpdatedAt:   t.UpdatedAt,
+		})
+	}
+	if shared.TotalTasks > 0 {
+		shared.Progress = shared.CompletedTasks * 100 / shared.TotalTasks
+	}
+
+	return shared, nil
+}
+
+// GetComments returns a shared task's comments when the link includes them
+func (s *ShareLinkService) GetComments(ctx context.Context, link *models.ShareLink, taskID uuid.UUID) ([]models.SharedComment, error) {
+	if !link.IncludeComments {
+		return nil, ErrShareNotIncluded
+	}
+	if err := s.requireSharedTask(ctx, link, taskID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.GetByTaskIDWithUser(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make([]models.SharedComment, 0, len(comments))
+	for _, c := range comments {
+		shared = append(shared, models.SharedComment{Author: c.Username, Content: c.Content, CreatedAt: c.CreatedAt})
+	}
+	return shared, nil
+}
+
+// GetAttachments lists a shared task's attachments when the link includes them
+func (s *ShareLinkService) GetAttachments(ctx context.Context, link *models.ShareLink, taskID uuid.UUID) ([]models.SharedAttachment, error) {
+	if !link.IncludeAttachments {
+		return nil, ErrShareNotIncluded
+	}
+	if err := s.requireSharedTask(ctx, link, taskID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.GetByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make([]models.SharedAttachment, 0, len(attachments))
+	for _, a := range attachments {
+		shared = append(shared, models.SharedAttachment{
+			ID:               a.ID,
+			OriginalFilename: a.OriginalFilename,
+			FileSize:         a.FileSize,
+			MimeType:         a.MimeType,
+			CreatedAt:        a.CreatedAt,
+		})
+	}
+	return shared, nil
+}
+
+// GetAttachment returns an attachment for download when it belongs to the shared project
+func (s *ShareLinkService) GetAttachment(ctx context.Context, link *models.ShareLink, attachmentID uuid.UUID) (*models.TaskAttachment, error) {
+	if !link.IncludeAttachments {
+		return nil, ErrShareNotIncluded
+	}
+
+	attachment, err := s.attachmentRepo.GetByID(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil {
+		return nil, models.ErrNotFound
+	}
+	if err := s.requireSharedTask(ctx, link, attachment.TaskID); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// requireSharedTask returns ErrNotFound unless the task belongs to the shared project
+func (s *ShareLinkService) requireSharedTask(ctx context.Context, link *models.ShareLink, taskID uuid.UUID) error {
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if task == nil || task.ProjectID != link.ProjectID {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// generateShareLinkToken returns a random, recognisably prefixed token that is safe in URLs
+func generateShareLinkToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return shareLinkTokenPrefix + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGenerateShareLinkToken(t *testing.T) {
+	token, err := generateShareLinkToken()
+	if err != nil {
+		t.Fatalf("generateShareLinkToken: %v", err)
+	}
+
+	if !strings.HasPrefix(token, shareLinkTokenPrefix) {
+		t.Errorf("token %q lacks prefix %q", token, shareLinkTokenPrefix)
+	}
+	// Tokens travel in the URL path, so they must not need escaping
+	if url.PathEscape(token) != token {
+		t.Errorf("token %q is not URL path safe", token)
+	}
+	if len(token) < shareLinkDisplayLength {
+		t.Errorf("token %q is shorter than its display prefix", token)
+	}
+
+	other, _ := generateShareLinkToken()
+	if other == token {
+		t.Error("two generated tokens are identical")
+	}
+}
+
+func TestOpenRejectsForeignTokens(t *testing.T) {
+	// Rejected before any lookup, so no repository is needed
+	service := &ShareLinkService{}
+	for _, token := range []string{"", "pmat_abc", "abc"} {
+		if _, err := service.Open(context.Background(), token, ShareVisit{}); !errors.Is(err, ErrShareLinkInvalid) {
+			t.Errorf("Open(%q) error = %v, want ErrShareLinkInvalid", token, err)
+		}
+	}
+}
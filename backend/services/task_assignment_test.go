@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"project-management/models"
+
+	"github.com/google/uuid"
+)
+
+func TestNewAssignments(t *testing.T) {
+	user, otherUser := uuid.New(), uuid.New()
+	group, otherGroup := uuid.New(), uuid.New()
+	task := &models.Task{AssigneeID: &user, AssigneeGroupID: &group}
+
+	cases := []struct {
+		name      string
+		assignee  *uuid.UUID
+		group     *uuid.UUID
+		wantUser  *uuid.UUID
+		wantGroup *uuid.UUID
+	}{
+		{"unchanged", &user, &group, nil, nil},
+		{"cleared", nil, nil, nil, nil},
+		{"new assignee", &otherUser, &group, &otherUser, nil},
+		{"new group", &user, &otherGroup, nil, &otherGroup},
+		{"both changed", &otherUser, &otherGroup, &otherUser, &otherGroup},
+	}
+
+	for _, tc := range cases {
+		gotUser, gotGroup := newAssignments(task, tc.assignee, tc.group)
+		if !sameID(gotUser, tc.wantUser) || !sameID(gotGroup, tc.wantGroup) {
+			t.Errorf("%s: newAssignments = (%v, %v), want (%v, %v)", tc.name, gotUser, gotGroup, tc.wantUser, tc.wantGroup)
+		}
+	}
+
+	// A task without assignees treats every assignment as new
+	gotUser, gotGroup := newAssignments(&models.Task{}, &user, &group)
+	if !sameID(gotUser, &user) || !sameID(gotGroup, &group) {
+		t.Errorf("unassigned task: newAssignments = (%v, %v), want both assigned", gotUser, gotGroup)
+	}
+}
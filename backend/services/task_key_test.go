@@ -0,0 +1,29 @@
+package services
+
+import "testing"
+
+func TestParseTaskKey(t *testing.T) {
+	tests := []struct {
+		key        string
+		identifier string
+		sequence   int
+		ok         bool
+	}{
+		{"WEB-142", "WEB", 142, true},
+		{"test-web-dev-7", "test-web-dev", 7, true},
+		{"web_app-1", "web_app", 1, true},
+		{"WEB", "", 0, false},
+		{"-5", "", 0, false},
+		{"WEB-", "", 0, false},
+		{"WEB-0", "", 0, false},
+		{"WEB-abc", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		identifier, sequence, ok := parseTaskKey(tt.key)
+		if identifier != tt.identifier || sequence != tt.sequence || ok != tt.ok {
+			t.Errorf("parseTaskKey(%q) = %q, %d, %v; want %q, %d, %v",
+				tt.key, identifier, sequence, ok, tt.identifier, tt.sequence, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import "testing"
+
+func TestHighlightHTML(t *testing.T) {
+	tests := []struct {
+		snippet string
+		want    string
+	}{
+		{"", ""},
+		{"no match here", ""},
+		{"fix the \x02login\x03 page", "fix the <mark>login</mark> page"},
+		{"\x02گزارش\x03 ماهانه", "<mark>گزارش</mark> ماهانه"},
+		{"<script>\x02alert\x03</script> & more", "&lt;script&gt;<mark>alert</mark>&lt;/script&gt; &amp; more"},
+	}
+
+	for _, tt := range tests {
+		if got := highlightHTML(tt.snippet); got != tt.want {
+			t.Errorf("highlightHTML(%q) = %q, want %q", tt.snippet, got, tt.want)
+		}
+	}
+}
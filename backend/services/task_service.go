@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
 	"project-management/models"
 	"project-management/repositories"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,10 +17,15 @@ import (
 type TaskService struct {
 	repo        *repositories.TaskRepository
 	projectRepo *repositories.ProjectRepository
+	userRepo    repositories.UserRepository
+	groupRepo   repositories.GroupRepository
+	versionRepo *repositories.VersionRepository
+	access      *ProjectAccess
+	notifier    *NotificationService
 }
 
-func NewTaskService(repo *repositories.TaskRepository, projectRepo *repositories.ProjectRepository) *TaskService {
-	return &TaskService{repo: repo, projectRepo: projectRepo}
+func NewTaskService(repo *repositories.TaskRepository, projectRepo *repositories.ProjectRepository, userRepo repositories.UserRepository, groupRepo repositories.GroupRepository, versionRepo *repositories.VersionRepository, access *ProjectAccess, notifier *NotificationService) *TaskService {
+	return &TaskService{repo: repo, projectRepo: projectRepo, userRepo: userRepo, groupRepo: groupRepo, versionRepo: versionRepo, access: access, notifier: notifier}
 }
 
 func (s *TaskService) GetTasksByProjectID(ctx context.Context, projectID uuid.UUID) ([]models.Task, error) {
@@ -29,51 +36,36 @@ func (s *TaskService) GetTasksByProjectID(ctx context.Context, projectID uuid.UU
 	return s.repo.GetByProjectID(ctx, projectID)
 }
 
-// GetTasksByUser returns tasks filtered by user role
-// Admins can see all tasks, regular users only see tasks from projects they own
+// GetTasksByUser returns the project's tasks when the user can see the project
 func (s *TaskService) GetTasksByUser(ctx context.Context, userID uuid.UUID, role string, projectID uuid.UUID) ([]models.Task, error) {
-	// Verify project exists
-	project, err := s.projectRepo.GetByID(ctx, projectID)
-	if err != nil || project == nil {
-		return nil, models.ErrNotFound
-	}
-
-	// Admins can see all tasks
-	if role == "admin" {
-		return s.repo.GetByProjectID(ctx, projectID)
-	}
-
-	// Regular users can only see tasks from projects they own
-	if (project.UserID != nil && *project.UserID == userID) || (project.CreatedBy != nil && *project.CreatedBy == userID) {
-		return s.repo.GetByProjectID(ctx, projectID)
+	if _, err := s.access.Role(ctx, projectID, userID, role); err != nil {
+		return nil, err
 	}
-
-	// User doesn't own this project, return empty list
-	return []models.Task{}, nil
+	return s.repo.GetByProjectID(ctx, projectID)
 }
 
-func (s *TaskService) GetTasksByUserPaginated(ctx context.Context, userID uuid.UUID, role string, projectID uuid.UUID, page int, pageSize int) (*models.PaginatedTasksResponse, error) {
-	project, err := s.projectRepo.GetByID(ctx, projectID)
-	if err != nil || project == nil {
-		return nil, models.ErrNotFound
+// GetTasksByUserPaginated lists a project's tasks; includeSubprojects adds tasks of subprojects the user can see
+func (s *TaskService) GetTasksByUserPaginated(ctx context.Context, userID uuid.UUID, role string, projectID uuid.UUID, page int, pageSize int, includeSubprojects bool) (*models.PaginatedTasksResponse, error) {
+	if _, err := s.access.Role(ctx, projectID, userID, role); err != nil {
+		return nil, err
 	}
 
 	offset := (page - 1) * pageSize
 
-	total, err := s.repo.GetTotalTasksByProject(ctx, projectID)
-	if err != nil {
-		return nil, err
-	}
-
+	var total int
 	var tasks []models.Task
-	if role == "admin" {
-		tasks, err = s.repo.GetByProjectIDPaginated(ctx, projectID, pageSize, offset)
-	} else if (project.UserID != nil && *project.UserID == userID) || (project.CreatedBy != nil && *project.CreatedBy == userID) {
-		tasks, err = s.repo.GetByProjectIDPaginated(ctx, projectID, pageSize, offset)
+	var err error
+	if includeSubprojects {
+		total, err = s.repo.GetTotalTasksBySubtree(ctx, projectID, userID, role)
+		if err == nil {
+			tasks, err = s.repo.GetBySubtreePaginated(ctx, projectID, userID, role, pageSize, offset)
+		}
 	} else {
-		tasks = []models.Task{}
+		total, err = s.repo.GetTotalTasksByProject(ctx, projectID)
+		if err == nil {
+			tasks, err = s.repo.GetByProjectIDPaginated(ctx, projectID, pageSize, offset)
+		}
 	}
-
 	if err != nil {
 		return nil, err
 	}
@@ -89,15 +81,99 @@ func (s *TaskService) GetTasksByUserPaginated(ctx context.Context, userID uuid.U
 	}, nil
 }
 
+// SearchTasks searches all of a project's tasks on the server, not just the page loaded by the client
+func (s *TaskService) SearchTasks(ctx context.Context, userID uuid.UUID, role string, projectID uuid.UUID, filter models.TaskSearchFilter, page int, pageSize int) (*models.TaskSearchResponse, error) {
+	if _, err := s.access.Role(ctx, projectID, userID, role); err != nil {
+		return nil, err
+	}
+
+	results, total, err := s.repo.Search(ctx, projectID, userID, role, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].TitleHighlight = highlightHTML(results[i].TitleHighlight)
+		results[i].DescriptionHighlight = highlightHTML(results[i].DescriptionHighlight)
+		results[i].CommentHighlight = highlightHTML(results[i].CommentHighlight)
+	}
+
+	return &models.TaskSearchResponse{
+		Tasks:    results,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  (page * pageSize) < total,
+	}, nil
+}
+
+var highlightReplacer = strings.NewReplacer(
+	repositories.TaskSearchHighlightStart, "<mark>",
+	repositories.TaskSearchHighlightStop, "</mark>",
+)
+
+// highlightHTML escapes a search snippet and marks its matched words; snippets without a match become empty
+func highlightHTML(snippet string) string {
+	if !strings.Contains(snippet, repositories.TaskSearchHighlightStart) {
+		return ""
+	}
+	return highlightReplacer.Replace(html.EscapeString(snippet))
+}
+
 func (s *TaskService) GetTaskByID(ctx context.Context, id uuid.UUID) (*models.Task, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *TaskService) GetTaskByIDWithUsers(ctx context.Context, id uuid.UUID) (*models.TaskWithUsers, error) {
-	return s.repo.GetByIDWithUsers(ctx, id)
+// GetTaskByIDWithUsers returns the task when the user can see its project
+func (s *TaskService) GetTaskByIDWithUsers(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) (*models.TaskWithUsers, error) {
+	task, err := s.repo.GetByIDWithUsers(ctx, id)
+	if err != nil || task == nil {
+		return nil, models.ErrNotFound
+	}
+	if _, err := s.access.Role(ctx, task.ProjectID, userID, role); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetTaskByKey resolves a key such as WEB-142 to the task when the user can see its project.
+// moved reports that the key is an old one the task had before it moved to another project.
+func (s *TaskService) GetTaskByKey(ctx context.Context, key string, userID uuid.UUID, role string) (task *models.TaskWithUsers, moved bool, err error) {
+	identifier, sequence, ok := parseTaskKey(key)
+	if !ok {
+		return nil, false, models.ErrNotFound
+	}
+
+	id, moved, err := s.repo.FindByKey(ctx, identifier, sequence)
+	if err != nil {
+		return nil, false, err
+	}
+
+	task, err = s.GetTaskByIDWithUsers(ctx, id, userID, role)
+	if err != nil {
+		return nil, false, err
+	}
+	return task, moved, nil
+}
+
+// parseTaskKey splits a key at its last hyphen, since project identifiers may contain hyphens themselves
+func parseTaskKey(key string) (string, int, bool) {
+	i := strings.LastIndex(key, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	sequence, err := strconv.Atoi(key[i+1:])
+	if err != nil || sequence < 1 {
+		return "", 0, false
+	}
+	return key[:i], sequence, true
 }
 
-func (s *TaskService) CreateTask(ctx context.Context, projectID uuid.UUID, req models.CreateTaskRequest) (*models.Task, error) {
+func (s *TaskService) CreateTask(ctx context.Context, projectID uuid.UUID, userID uuid.UUID, role string, req models.CreateTaskRequest) (*models.Task, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, role, actionCreateTask); err != nil {
+		return nil, err
+	}
+
 	if req.Title == "" {
 		return nil, models.ErrValidation
 	}
@@ -123,10 +199,32 @@ func (s *TaskService) CreateTask(ctx context.Context, projectID uuid.UUID, req m
 		return nil, err
 	}
 
-	return s.repo.Create(ctx, projectID, req)
+	// Validate assignee
+	if err := s.ValidateAssignee(ctx, projectID, req.AssigneeID); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateAssigneeGroup(ctx, projectID, req.AssigneeGroupID); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateFixedVersion(ctx, projectID, req.FixedVersionID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.repo.Create(ctx, projectID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.TaskAssigned(task, req.AssigneeID, req.AssigneeGroupID, userID)
+	return task, nil
 }
 
-func (s *TaskService) UpdateTask(ctx context.Context, id uuid.UUID, req models.UpdateTaskRequest) (*models.Task, error) {
+func (s *TaskService) UpdateTask(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string, req models.UpdateTaskRequest) (*models.Task, error) {
+	task, err := s.authorizeTask(ctx, id, userID, role, actionEditTask)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Title == "" {
 		return nil, models.ErrValidation
 	}
@@ -152,32 +250,107 @@ func (s *TaskService) UpdateTask(ctx context.Context, id uuid.UUID, req models.U
 		return nil, err
 	}
 
-	return s.repo.Update(ctx, id, req)
+	// Moving the task requires the right to create tasks in the target project
+	targetProjectID := task.ProjectID
+	moving := req.ProjectID != nil && *req.ProjectID != task.ProjectID
+	if moving {
+		if _, err := s.access.Require(ctx, *req.ProjectID, userID, role, actionCreateTask); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, errors.New("target project not found")
+			}
+			return nil, err
+		}
+		targetProjectID = *req.ProjectID
+	}
+
+	// Validate assignees only when they change, so existing assignments stay editable;
+	// a moved task's assignees must all be able to work in the target project
+	newAssignee, newGroup := newAssignments(task, req.AssigneeID, req.AssigneeGroupID)
+	checkAssignee, checkGroup := newAssignee, newGroup
+	if moving {
+		checkAssignee, checkGroup = req.AssigneeID, req.AssigneeGroupID
+	}
+	if err := s.ValidateAssignee(ctx, targetProjectID, checkAssignee); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateAssigneeGroup(ctx, targetProjectID, checkGroup); err != nil {
+		return nil, err
+	}
+
+	// Tasks may stay in a version that was locked after they were added
+	if !sameID(req.FixedVersionID, task.FixedVersionID) {
+		if err := s.ValidateFixedVersion(ctx, targetProjectID, req.FixedVersionID); err != nil {
+			return nil, err
+		}
+	} else if moving && req.FixedVersionID != nil {
+		// A moved task drops a version the target project cannot use
+		version, err := s.versionRepo.GetSharedWith(ctx, *req.FixedVersionID, targetProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if version == nil {
+			req.FixedVersionID = nil
+		}
+	}
+
+	var updated *models.Task
+	if moving {
+		updated, err = s.repo.Move(ctx, id, targetProjectID, req)
+	} else {
+		updated, err = s.repo.Update(ctx, id, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.TaskAssigned(updated, newAssignee, newGroup, userID)
+	return updated, nil
 }
 
-func (s *TaskService) ToggleTaskCompletion(ctx context.Context, id uuid.UUID) (*models.Task, error) {
-	task, err := s.repo.GetByID(ctx, id)
-	if err != nil || task == nil {
-		return nil, models.ErrNotFound
+func (s *TaskService) ToggleTaskCompletion(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) (*models.Task, error) {
+	task, err := s.authorizeTask(ctx, id, userID, role, actionEditTask)
+	if err != nil {
+		return nil, err
 	}
 
 	return s.repo.Update(ctx, id, models.UpdateTaskRequest{
-		Title:          task.Title,
-		Description:    task.Description,
-		Priority:       task.Priority,
-		Completed:      !task.Completed,
-		AssigneeID:     task.AssigneeID,
-		AuthorID:       task.AuthorID,
-		Category:       task.Category,
-		StartDate:      task.StartDate,
-		DueDate:        task.DueDate,
-		EstimatedHours: task.EstimatedHours,
-		DoneRatio:      task.DoneRatio,
+		Title:           task.Title,
+		Description:     task.Description,
+		Priority:        task.Priority,
+		Completed:       !task.Completed,
+		AssigneeID:      task.AssigneeID,
+		AssigneeGroupID: task.AssigneeGroupID,
+		FixedVersionID:  task.FixedVersionID,
+		AuthorID:        task.AuthorID,
+		Category:        task.Category,
+		StartDate:       task.StartDate,
+		DueDate:         task.DueDate,
+		EstimatedHours:  task.EstimatedHours,
+		DoneRatio:       task.DoneRatio,
 	})
 }
 
-func (s *TaskService) DeleteTask(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+// DeleteTask moves the task to the trash
+func (s *TaskService) DeleteTask(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) error {
+	if _, err := s.authorizeTask(ctx, id, userID, role, actionDeleteTask); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, userID)
+}
+
+// authorizeTask loads the task after checking the user may perform the action in its project
+func (s *TaskService) authorizeTask(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string, action projectAction) (*models.Task, error) {
+	if _, err := s.access.RequireTask(ctx, id, userID, role, action); err != nil {
+		return nil, err
+	}
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, models.ErrNotFound
+	}
+	return task, nil
 }
 
 // ValidateTaskDates ensures due_date >= start_date when both are provided
@@ -206,6 +379,62 @@ func (s *TaskService) ValidateEstimatedHours(estimatedHours *float64) error {
 	return nil
 }
 
+// ValidateAssignee ensures the assignee exists, has verified their email and can see the project
+func (s *TaskService) ValidateAssignee(ctx context.Context, projectID uuid.UUID, assigneeID *uuid.UUID) error {
+	if assigneeID == nil {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, *assigneeID)
+	if err != nil || user == nil {
+		return errors.New("assignee not found")
+	}
+	if !user.IsEmailVerified() {
+		return errors.New("assignee has not verified their email address")
+	}
+
+	if _, err := s.access.Role(ctx, projectID, user.ID, user.Role); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return errors.New("assignee has no access to the project")
+		}
+		return err
+	}
+	return nil
+}
+
+// ValidateAssigneeGroup ensures the assigned group exists and is a member of the project,
+// so assignment notifications only reach users who can open the task
+func (s *TaskService) ValidateAssigneeGroup(ctx context.Context, projectID uuid.UUID, groupID *uuid.UUID) error {
+	if groupID == nil {
+		return nil
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, *groupID)
+	if err != nil || group == nil {
+		return errors.New("assignee group not found")
+	}
+
+	onProject, err := s.access.HasGroup(ctx, projectID, *groupID)
+	if err != nil {
+		return err
+	}
+	if !onProject {
+		return errors.New("assignee group is not a member of the project")
+	}
+	return nil
+}
+
+// newAssignments returns the user and group an update assigns that the task did not already have
+func newAssignments(task *models.Task, assigneeID, groupID *uuid.UUID) (*uuid.UUID, *uuid.UUID) {
+	if assigneeID != nil && task.AssigneeID != nil && *assigneeID == *task.AssigneeID {
+		assigneeID = nil
+	}
+	if groupID != nil && task.AssigneeGroupID != nil && *groupID == *task.AssigneeGroupID {
+		groupID = nil
+	}
+	return assigneeID, groupID
+}
+
 func normalizeTaskPriority(priority string) (string, error) {
 	p := strings.TrimSpace(priority)
 	if p == "" {
@@ -234,3 +463,22 @@ func normalizeTaskPriority(priority string) (string, error) {
 		return "", fmt.Errorf("invalid priority: must be one of Low, Medium, High")
 	}
 }
+
+// ValidateFixedVersion ensures the version is open and can be used in the task's project
+func (s *TaskService) ValidateFixedVersion(ctx context.Context, projectID uuid.UUID, versionID *uuid.UUID) error {
+	if versionID == nil {
+		return nil
+	}
+
+	version, err := s.versionRepo.GetSharedWith(ctx, *versionID, projectID)
+	if err != nil {
+		return err
+	}
+	if version == nil {
+		return ErrVersionNotFound
+	}
+	if version.Status != models.VersionStatusOpen {
+		return errors.New("tasks can only be added to open versions")
+	}
+	return nil
+}
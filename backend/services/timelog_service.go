@@ -9,37 +9,70 @@ import (
 )
 
 type TimeLogService struct {
-	repo     *repositories.TimeLogRepository
-	taskRepo *repositories.TaskRepository
+	repo   *repositories.TimeLogRepository
+	access *ProjectAccess
 }
 
-func NewTimeLogService(repo *repositories.TimeLogRepository, taskRepo *repositories.TaskRepository) *TimeLogService {
-	return &TimeLogService{repo: repo, taskRepo: taskRepo}
+func NewTimeLogService(repo *repositories.TimeLogRepository, access *ProjectAccess) *TimeLogService {
+	return &TimeLogService{repo: repo, access: access}
 }
 
-func (s *TimeLogService) GetTimeLogsByTaskID(ctx context.Context, taskID uuid.UUID) ([]models.TimeLog, error) {
-	task, err := s.taskRepo.GetByID(ctx, taskID)
-	if err != nil || task == nil {
-		return nil, models.ErrNotFound
+// GetTimeLogsByTaskID returns the task's time logs when the user can see its project
+func (s *TimeLogService) GetTimeLogsByTaskID(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, role string) ([]models.TimeLog, error) {
+	if err := s.checkViewAccess(ctx, taskID, userID, role); err != nil {
+		return nil, err
 	}
 	return s.repo.GetByTaskID(ctx, taskID)
 }
 
-func (s *TimeLogService) GetTimeLogByID(ctx context.Context, id uuid.UUID) (*models.TimeLog, error) {
-	return s.repo.GetByID(ctx, id)
+// GetTimeLogByID returns the time log when the user can see its project
+func (s *TimeLogService) GetTimeLogByID(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) (*models.TimeLog, error) {
+	timeLog, err := s.repo.GetByID(ctx, id)
+	if err != nil || timeLog == nil {
+		return nil, models.ErrNotFound
+	}
+	if err := s.checkViewAccess(ctx, timeLog.TaskID, userID, role); err != nil {
+		return nil, err
+	}
+	return timeLog, nil
+}
+
+// checkViewAccess lets users read time logs of projects they can see, or of every project
+// when their system role grants timelog.view_all
+func (s *TimeLogService) checkViewAccess(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, role string) error {
+	_, err := s.access.TaskRole(ctx, taskID, userID, role)
+	if err != models.ErrNotFound {
+		return err
+	}
+	viewAll, permErr := s.access.HasPermission(ctx, role, models.PermissionTimeLogViewAll)
+	if permErr != nil {
+		return permErr
+	}
+	if !viewAll {
+		return err
+	}
+	return nil
 }
 
-func (s *TimeLogService) CreateTimeLog(ctx context.Context, taskID uuid.UUID, req models.CreateTimeLogRequest) (*models.TimeLog, error) {
+// CreateTimeLog logs time on a task when the user's project role allows it
+func (s *TimeLogService) CreateTimeLog(ctx context.Context, taskID uuid.UUID, userID uuid.UUID, role string, req models.CreateTimeLogRequest) (*models.TimeLog, error) {
+	if _, err := s.access.RequireTask(ctx, taskID, userID, role, actionLogTime); err != nil {
+		return nil, err
+	}
 	if req.DurationMinutes <= 0 {
 		return nil, models.ErrValidation
 	}
-	task, err := s.taskRepo.GetByID(ctx, taskID)
-	if err != nil || task == nil {
-		return nil, models.ErrNotFound
-	}
 	return s.repo.Create(ctx, taskID, req)
 }
 
-func (s *TimeLogService) DeleteTimeLog(ctx context.Context, id uuid.UUID) error {
+// DeleteTimeLog removes a time log when the user's project role allows it
+func (s *TimeLogService) DeleteTimeLog(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) error {
+	timeLog, err := s.repo.GetByID(ctx, id)
+	if err != nil || timeLog == nil {
+		return models.ErrNotFound
+	}
+	if _, err := s.access.RequireTask(ctx, timeLog.TaskID, userID, role, actionLogTime); err != nil {
+		return err
+	}
 	return s.repo.Delete(ctx, id)
 }
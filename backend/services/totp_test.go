@@ -0,0 +1,76 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B test vectors (SHA1, 8 digits)
+func TestHOTPCode_RFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+	}
+
+	for _, tc := range cases {
+		got := hotpCode(key, totpStep(time.Unix(tc.unix, 0)), 8)
+		if got != tc.want {
+			t.Fatalf("hotpCode(T=%d) = %q, want %q", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestVerifyTOTP_AcceptsAdjacentSteps(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key, _ := totpEncoding.DecodeString(secret)
+	now := time.Unix(1700000000, 0)
+
+	for _, offset := range []int64{-1, 0, 1} {
+		step := totpStep(now) + offset
+		code := hotpCode(key, step, totpDigits)
+		got, ok := verifyTOTP(secret, code, now)
+		if !ok || got != step {
+			t.Fatalf("offset %d: verifyTOTP = (%d, %v), want (%d, true)", offset, got, ok, step)
+		}
+	}
+}
+
+func TestVerifyTOTP_RejectsOutOfWindow(t *testing.T) {
+	secret, _ := generateTOTPSecret()
+	key, _ := totpEncoding.DecodeString(secret)
+	now := time.Unix(1700000000, 0)
+
+	code := hotpCode(key, totpStep(now)+3, totpDigits)
+	if _, ok := verifyTOTP(secret, code, now); ok {
+		t.Fatalf("expected code three steps ahead to be rejected")
+	}
+
+	if _, ok := verifyTOTP(secret, "12345", now); ok {
+		t.Fatalf("expected short code to be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := totpProvisioningURI("JBSWY3DPEHPK3PXP", "admin@example.com")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/") {
+		t.Fatalf("unexpected scheme: %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Fatalf("uri %q missing %q", uri, part)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+// ErrRestoreParentDeleted is returned when restoring something whose project or parent project is still in the trash
+var ErrRestoreParentDeleted = errors.New("restore the parent project first")
+
+type TrashService interface {
+	GetTrash(ctx context.Context, userID uuid.UUID, role string, all bool) (*models.Trash, error)
+	RestoreProject(ctx context.Context, id, userID uuid.UUID, role string) error
+	RestoreTask(ctx context.Context, id, userID uuid.UUID, role string) error
+	Purge(ctx context.Context) error
+}
+
+type trashService struct {
+	repo        repositories.TrashRepository
+	access      *ProjectAccess
+	fileStorage *FileStorageService
+	retention   time.Duration
+}
+
+func NewTrashService(repo repositories.TrashRepository, access *ProjectAccess, fileStorage *FileStorageService, retentionDays int) TrashService {
+	return &trashService{
+		repo:        repo,
+		access:      access,
+		fileStorage: fileStorage,
+		retention:   time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// GetTrash lists what the user deleted; all lists everyone's deletions and needs trash.manage
+func (s *trashService) GetTrash(ctx context.Context, userID uuid.UUID, role string, all bool) (*models.Trash, error) {
+	deletedBy := &userID
+	if all {
+		granted, err := s.access.HasPermission(ctx, role, models.PermissionTrashManage)
+		if err != nil {
+			return nil, err
+		}
+		if !granted {
+			return nil, ErrProjectForbidden
+		}
+		deletedBy = nil
+	}
+
+	projects, err := s.repo.ListProjects(ctx, deletedBy)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.repo.ListTasks(ctx, deletedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range projects {
+		projects[i].PurgeAt = projects[i].DeletedAt.Add(s.retention)
+	}
+	for i := range tasks {
+		tasks[i].PurgeAt = tasks[i].DeletedAt.Add(s.retention)
+	}
+	return &models.Trash{Projects: projects, Tasks: tasks}, nil
+}
+
+// RestoreProject brings back a deleted project and the subprojects deleted with it
+func (s *trashService) RestoreProject(ctx context.Context, id, userID uuid.UUID, role string) error {
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return models.ErrNotFound
+	}
+	if err := s.authorizeRestore(ctx, project.DeletedBy, project.ParentDeleted, userID, role); err != nil {
+		return err
+	}
+	// A subproject goes back under its parent; a top-level project is checked on itself
+	if project.ParentID != nil {
+		_, err = s.access.Require(ctx, *project.ParentID, userID, role, actionManageProject)
+	} else {
+		err = s.access.RequireDeleted(ctx, project.ID, userID, role, actionManageProject)
+	}
+	if err != nil {
+		return err
+	}
+
+	restored, err := s.repo.RestoreProject(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// RestoreTask brings back a deleted task; its project must not be in the trash
+func (s *trashService) RestoreTask(ctx context.Context, id, userID uuid.UUID, role string) error {
+	task, err := s.repo.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return models.ErrNotFound
+	}
+	if err := s.authorizeRestore(ctx, task.DeletedBy, task.ProjectDeleted, userID, role); err != nil {
+		return err
+	}
+	if _, err := s.access.Require(ctx, task.ProjectID, userID, role, actionDeleteTask); err != nil {
+		return err
+	}
+
+	restored, err := s.repo.RestoreTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// authorizeRestore lets the user who deleted an item, or anyone with trash.manage, restore it.
+// Other users get models.ErrNotFound, as the item is not in their trash. The item's project or
+// parent project must be out of the trash; the callers then check the user's role there.
+func (s *trashService) authorizeRestore(ctx context.Context, deletedBy *uuid.UUID, parentDeleted bool, userID uuid.UUID, role string) error {
+	if deletedBy == nil || *deletedBy != userID {
+		granted, err := s.access.HasPermission(ctx, role, models.PermissionTrashManage)
+		if err != nil {
+			return err
+		}
+		if !granted {
+			return models.ErrNotFound
+		}
+	}
+	if parentDeleted {
+		return ErrRestoreParentDeleted
+	}
+	return nil
+}
+
+// Purge permanently deletes what has been in the trash longer than the retention window,
+// then removes the stored files of the purged attachments
+func (s *trashService) Purge(ctx context.Context) error {
+	projects, tasks, files, err := s.repo.Purge(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		thumbnailPath := ""
+		if file.ThumbnailPath != nil {
+			thumbnailPath = *file.ThumbnailPath
+		}
+		if err := s.fileStorage.DeleteFileWithThumbnail(file.FilePath, thumbnailPath); err != nil {
+			log.Printf("Failed to delete purged attachment file %s: %v", file.FilePath, err)
+		}
+	}
+
+	if projects > 0 || tasks > 0 {
+		log.Printf("Purged %d projects, %d tasks and %d attachment files from the trash", projects, tasks, len(files))
+	}
+	return nil
+}
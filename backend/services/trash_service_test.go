@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+// fakeTrashRepository holds one trashed project and task; restores are only counted
+type fakeTrashRepository struct {
+	repositories.TrashRepository
+	project  *models.TrashedProject
+	task     *models.TrashedTask
+	restored int
+}
+
+func (r *fakeTrashRepository) GetProject(ctx context.Context, id uuid.UUID) (*models.TrashedProject, error) {
+	if r.project == nil || r.project.ID != id {
+		return nil, nil
+	}
+	return r.project, nil
+}
+
+func (r *fakeTrashRepository) GetTask(ctx context.Context, id uuid.UUID) (*models.TrashedTask, error) {
+	if r.task == nil || r.task.ID != id {
+		return nil, nil
+	}
+	return r.task, nil
+}
+
+func (r *fakeTrashRepository) RestoreProject(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.restored++
+	return true, nil
+}
+
+func (r *fakeTrashRepository) RestoreTask(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.restored++
+	return true, nil
+}
+
+func (r *fakeTrashRepository) ListProjects(ctx context.Context, deletedBy *uuid.UUID) ([]models.TrashedProject, error) {
+	return []models.TrashedProject{*r.project}, nil
+}
+
+func (r *fakeTrashRepository) ListTasks(ctx context.Context, deletedBy *uuid.UUID) ([]models.TrashedTask, error) {
+	return []models.TrashedTask{}, nil
+}
+
+// fakeRoleRepository grants trash.manage to the admin role only
+type fakeRoleRepository struct {
+	repositories.RoleRepository
+}
+
+func (r *fakeRoleRepository) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	return role == models.SystemRoleAdmin && permission == models.PermissionTrashManage, nil
+}
+
+// fakeProjectMemberRepository gives each user the same role in every project, and admins manager.
+// Like project_role(), GetProjectRole hides the deleted projects.
+type fakeProjectMemberRepository struct {
+	ProjectRoleRepository
+	roles   map[uuid.UUID]string
+	deleted map[uuid.UUID]bool
+}
+
+func (r *fakeProjectMemberRepository) role(userID uuid.UUID, systemRole string) string {
+	if systemRole == models.SystemRoleAdmin {
+		return models.ProjectRoleManager
+	}
+	return r.roles[userID]
+}
+
+func (r *fakeProjectMemberRepository) GetProjectRole(ctx context.Context, projectID, userID uuid.UUID, systemRole string) (string, bool, error) {
+	if r.deleted[projectID] {
+		return "", true, nil
+	}
+	return r.role(userID, systemRole), true, nil
+}
+
+func (r *fakeProjectMemberRepository) GetDeletedProjectRole(ctx context.Context, projectID, userID uuid.UUID, systemRole string) (string, bool, error) {
+	return r.role(userID, systemRole), true, nil
+}
+
+// newTestProjectAccess gives the users their project roles; the trashed projects are hidden from Require
+func newTestProjectAccess(roles map[uuid.UUID]string, deleted ...uuid.UUID) *ProjectAccess {
+	memberRepo := &fakeProjectMemberRepository{roles: roles, deleted: map[uuid.UUID]bool{}}
+	for _, id := range deleted {
+		memberRepo.deleted[id] = true
+	}
+	return NewProjectAccess(memberRepo, &fakeRoleRepository{}, &fakeProjectStatusRepository{})
+}
+
+func TestTrashRestoreIsLimitedToDeleterAndTrashManagers(t *testing.T) {
+	deleter, other := uuid.New(), uuid.New()
+	repo := &fakeTrashRepository{
+		project: &models.TrashedProject{ID: uuid.New(), DeletedBy: &deleter},
+		task:    &models.TrashedTask{ID: uuid.New(), ProjectID: uuid.New(), DeletedBy: &deleter},
+	}
+	access := newTestProjectAccess(map[uuid.UUID]string{deleter: models.ProjectRoleManager}, repo.project.ID)
+	service := NewTrashService(repo, access, nil, 30)
+	ctx := context.Background()
+
+	if err := service.RestoreTask(ctx, repo.task.ID, other, models.SystemRoleUser); err != models.ErrNotFound {
+		t.Fatalf("restore by another user: err = %v, want ErrNotFound", err)
+	}
+	if err := service.RestoreTask(ctx, repo.task.ID, deleter, models.SystemRoleUser); err != nil {
+		t.Fatalf("restore by deleter: %v", err)
+	}
+	if err := service.RestoreProject(ctx, repo.project.ID, other, models.SystemRoleAdmin); err != nil {
+		t.Fatalf("restore by trash manager: %v", err)
+	}
+	if err := service.RestoreProject(ctx, uuid.New(), deleter, models.SystemRoleAdmin); err != models.ErrNotFound {
+		t.Fatalf("restore of unknown project: err = %v, want ErrNotFound", err)
+	}
+	if repo.restored != 2 {
+		t.Fatalf("restored = %d, want 2", repo.restored)
+	}
+}
+
+func TestTrashRestoreNeedsProjectRole(t *testing.T) {
+	deleter := uuid.New()
+	repo := &fakeTrashRepository{
+		project: &models.TrashedProject{ID: uuid.New(), DeletedBy: &deleter},
+		task:    &models.TrashedTask{ID: uuid.New(), ProjectID: uuid.New(), DeletedBy: &deleter},
+	}
+	ctx := context.Background()
+
+	// The deleter has since been made a developer: no longer enough for the project or its deleted tasks
+	service := NewTrashService(repo, newTestProjectAccess(map[uuid.UUID]string{deleter: models.ProjectRoleDeveloper}, repo.project.ID), nil, 30)
+	if err := service.RestoreProject(ctx, repo.project.ID, deleter, models.SystemRoleUser); err != ErrProjectForbidden {
+		t.Fatalf("restore of a top-level project by a developer: err = %v, want ErrProjectForbidden", err)
+	}
+	if err := service.RestoreTask(ctx, repo.task.ID, deleter, models.SystemRoleUser); err != ErrProjectForbidden {
+		t.Fatalf("restore of a task by a developer: err = %v, want ErrProjectForbidden", err)
+	}
+
+	// Removed from the project altogether
+	service = NewTrashService(repo, newTestProjectAccess(nil, repo.project.ID), nil, 30)
+	if err := service.RestoreProject(ctx, repo.project.ID, deleter, models.SystemRoleUser); err != models.ErrNotFound {
+		t.Fatalf("restore of a top-level project by a former member: err = %v, want ErrNotFound", err)
+	}
+	if repo.restored != 0 {
+		t.Fatalf("restored = %d, want 0", repo.restored)
+	}
+}
+
+func TestTrashRestoreNeedsLiveParent(t *testing.T) {
+	deleter := uuid.New()
+	repo := &fakeTrashRepository{
+		project: &models.TrashedProject{ID: uuid.New(), DeletedBy: &deleter, ParentDeleted: true},
+		task:    &models.TrashedTask{ID: uuid.New(), DeletedBy: &deleter, ProjectDeleted: true},
+	}
+	service := NewTrashService(repo, NewProjectAccess(nil, &fakeRoleRepository{}, nil), nil, 30)
+	ctx := context.Background()
+
+	if err := service.RestoreProject(ctx, repo.project.ID, deleter, models.SystemRoleUser); err != ErrRestoreParentDeleted {
+		t.Fatalf("restore under a deleted parent: err = %v, want ErrRestoreParentDeleted", err)
+	}
+	if err := service.RestoreTask(ctx, repo.task.ID, deleter, models.SystemRoleUser); err != ErrRestoreParentDeleted {
+		t.Fatalf("restore in a deleted project: err = %v, want ErrRestoreParentDeleted", err)
+	}
+	if repo.restored != 0 {
+		t.Fatalf("restored = %d, want 0", repo.restored)
+	}
+}
+
+func TestTrashListingNeedsPermissionForAll(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeTrashRepository{project: &models.TrashedProject{ID: uuid.New(), DeletedAt: deletedAt}}
+	service := NewTrashService(repo, NewProjectAccess(nil, &fakeRoleRepository{}, nil), nil, 30)
+	ctx := context.Background()
+
+	if _, err := service.GetTrash(ctx, uuid.New(), models.SystemRoleUser, true); err != ErrProjectForbidden {
+		t.Fatalf("all trash without trash.manage: err = %v, want ErrProjectForbidden", err)
+	}
+
+	trash, err := service.GetTrash(ctx, uuid.New(), models.SystemRoleAdmin, true)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	if want := deletedAt.AddDate(0, 0, 30); !trash.Projects[0].PurgeAt.Equal(want) {
+		t.Fatalf("purge_at = %s, want %s", trash.Projects[0].PurgeAt, want)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	"project-management/models"
 	"project-management/repositories"
@@ -19,15 +20,24 @@ type UserService interface {
 	GetUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) (*models.User, error)
 	UpdateUserActivation(ctx context.Context, userID uuid.UUID, isActive bool) (*models.User, error)
+	GetUserSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) (int, error)
 }
 
 type userService struct {
-	userRepo repositories.UserRepository
+	userRepo     repositories.UserRepository
+	sessionRepo  repositories.SessionRepository
+	roleRepo     repositories.RoleRepository
+	auditService AuditService
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
+func NewUserService(userRepo repositories.UserRepository, sessionRepo repositories.SessionRepository, roleRepo repositories.RoleRepository, auditService AuditService) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		roleRepo:     roleRepo,
+		auditService: auditService,
 	}
 }
 
@@ -47,13 +57,25 @@ func (s *userService) UpdateUserRole(ctx context.Context, userID uuid.UUID, role
 		return nil, errors.New("کاربر یافت نشد")
 	}
 
+	if err := requireRoleExists(ctx, s.roleRepo, role); err != nil {
+		return nil, err
+	}
+
 	// Update role
+	previousRole := user.Role
 	user.Role = role
 	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventRoleChanged,
+		Outcome:   models.AuditOutcomeSuccess,
+		TargetID:  &user.ID,
+		Details:   map[string]string{"from": previousRole, "to": role},
+	})
+
 	return user, nil
 }
 
@@ -65,15 +87,25 @@ func (s *userService) UpdateUserActivation(ctx context.Context, userID uuid.UUID
 	}
 
 	// If deactivating an admin, check if they're the last admin
-	if !isActive && user.Role == "admin" {
+	administrator, err := isAdministrator(ctx, s.roleRepo, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !isActive && administrator {
 		// Count active admins
-		activeAdminCount, err := s.userRepo.CountActiveAdmins(ctx)
+		activeAdminCount, err := s.userRepo.CountActiveWithPermission(ctx, models.PermissionRoleManage)
 		if err != nil {
 			return nil, err
 		}
 
 		// If this is the last active admin, prevent deactivation
 		if activeAdminCount <= 1 {
+			s.auditService.Record(ctx, models.AuditEntry{
+				EventType: models.AuditEventUserDeactivated,
+				Outcome:   models.AuditOutcomeFailure,
+				TargetID:  &user.ID,
+				Details:   map[string]string{"reason": "last_admin"},
+			})
 			return nil, ErrCannotDeactivateLastAdmin
 		}
 	}
@@ -85,5 +117,67 @@ func (s *userService) UpdateUserActivation(ctx context.Context, userID uuid.UUID
 		return nil, err
 	}
 
+	eventType := models.AuditEventUserActivated
+	if !isActive {
+		eventType = models.AuditEventUserDeactivated
+	}
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: eventType,
+		Outcome:   models.AuditOutcomeSuccess,
+		TargetID:  &user.ID,
+	})
+
+	// Deactivated users are signed out everywhere
+	if !isActive {
+		if _, err := s.sessionRepo.RevokeAllByUser(ctx, userID, uuid.Nil); err != nil {
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
+
+// GetUserSessions returns the active sessions of any user (admin view)
+func (s *userService) GetUserSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.New("کاربر یافت نشد")
+	}
+
+	return s.sessionRepo.ListActiveByUser(ctx, userID)
+}
+
+// RevokeUserSession revokes a single session of any user (admin action)
+func (s *userService) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	revoked, err := s.sessionRepo.RevokeByID(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventUserSessionsRevoked,
+		Outcome:   models.AuditOutcomeSuccess,
+		TargetID:  &userID,
+		Details:   map[string]string{"session_id": sessionID.String()},
+	})
+	return nil
+}
+
+// RevokeAllUserSessions signs a user out of every device (admin action)
+func (s *userService) RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	revoked, err := s.sessionRepo.RevokeAllByUser(ctx, userID, uuid.Nil)
+	if err != nil {
+		return 0, err
+	}
+
+	s.auditService.Record(ctx, models.AuditEntry{
+		EventType: models.AuditEventUserSessionsRevoked,
+		Outcome:   models.AuditOutcomeSuccess,
+		TargetID:  &userID,
+		Details:   map[string]string{"revoked": strconv.Itoa(revoked)},
+	})
+	return revoked, nil
+}
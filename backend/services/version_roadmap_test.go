@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"project-management/models"
+)
+
+func hours(h float64) *float64 { return &h }
+
+func TestSummarizeVersion(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name      string
+		version   models.Version
+		tasks     []models.VersionTaskProgress
+		open      int
+		closed    int
+		estimated float64
+		doneRatio int
+		late      bool
+	}{
+		{
+			name:    "no tasks",
+			version: models.Version{Status: models.VersionStatusOpen, DueDate: &yesterday},
+		},
+		{
+			name:    "weighted by estimated hours",
+			version: models.Version{Status: models.VersionStatusOpen, DueDate: &tomorrow},
+			tasks: []models.VersionTaskProgress{
+				{DoneRatio: 0, EstimatedHours: hours(30)},
+				{Completed: true, DoneRatio: 40, EstimatedHours: hours(10)},
+			},
+			open: 1, closed: 1, estimated: 40, doneRatio: 25,
+		},
+		{
+			name:    "unestimated tasks weigh the average estimate",
+			version: models.Version{Status: models.VersionStatusOpen},
+			tasks: []models.VersionTaskProgress{
+				{DoneRatio: 50, EstimatedHours: hours(2)},
+				{DoneRatio: 50, EstimatedHours: hours(6)},
+				{DoneRatio: 100},
+			},
+			open: 3, estimated: 8, doneRatio: 67,
+		},
+		{
+			name:    "all estimates zero",
+			version: models.Version{Status: models.VersionStatusOpen},
+			tasks: []models.VersionTaskProgress{
+				{DoneRatio: 20, EstimatedHours: hours(0)},
+				{DoneRatio: 60, EstimatedHours: hours(0)},
+			},
+			open: 2, doneRatio: 40,
+		},
+		{
+			name:    "past due with open tasks is late",
+			version: models.Version{Status: models.VersionStatusLocked, DueDate: &yesterday},
+			tasks:   []models.VersionTaskProgress{{DoneRatio: 90}},
+			open:    1, doneRatio: 90, late: true,
+		},
+		{
+			name:    "due today is not late",
+			version: models.Version{Status: models.VersionStatusOpen, DueDate: &today},
+			tasks:   []models.VersionTaskProgress{{}},
+			open:    1,
+		},
+		{
+			name:    "closed version is never late",
+			version: models.Version{Status: models.VersionStatusClosed, DueDate: &yesterday},
+			tasks:   []models.VersionTaskProgress{{}},
+			open:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeVersion(tt.version, tt.tasks, today)
+			if got.TotalTasks != len(tt.tasks) || got.OpenTasks != tt.open || got.ClosedTasks != tt.closed {
+				t.Errorf("tasks = %d total, %d open, %d closed; want %d, %d, %d",
+					got.TotalTasks, got.OpenTasks, got.ClosedTasks, len(tt.tasks), tt.open, tt.closed)
+			}
+			if got.EstimatedHours != tt.estimated {
+				t.Errorf("estimated hours = %v, want %v", got.EstimatedHours, tt.estimated)
+			}
+			if got.DoneRatio != tt.doneRatio {
+				t.Errorf("done ratio = %d, want %d", got.DoneRatio, tt.doneRatio)
+			}
+			if got.Late != tt.late {
+				t.Errorf("late = %v, want %v", got.Late, tt.late)
+			}
+		})
+	}
+}
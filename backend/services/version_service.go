@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"project-management/models"
+	"project-management/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrVersionNotFound      = errors.New("version not found")
+	ErrInvalidVersionName   = errors.New("version name is required and must be at most 100 characters")
+	ErrInvalidVersionStatus = errors.New("status must be open, locked or closed")
+	ErrInvalidSharing       = errors.New("sharing must be none, descendants, hierarchy, tree or system")
+	ErrSystemSharing        = errors.New("only administrators can share a version with all projects")
+	ErrVersionHasOpenTasks  = errors.New("version has open tasks; close them or pass move_open_tasks_to")
+	ErrVersionMoveTarget    = errors.New("open tasks can only be moved to another open version shared with their projects")
+)
+
+const versionMaxNameLength = 100
+
+type VersionService struct {
+	repo   *repositories.VersionRepository
+	access *ProjectAccess
+}
+
+func NewVersionService(repo *repositories.VersionRepository, access *ProjectAccess) *VersionService {
+	return &VersionService{repo: repo, access: access}
+}
+
+// ListVersions returns the versions usable in the project, including those shared by other projects
+func (s *VersionService) ListVersions(ctx context.Context, projectID, userID uuid.UUID, role string) ([]models.Version, error) {
+	if _, err := s.access.Role(ctx, projectID, userID, role); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSharedWith(ctx, projectID)
+}
+
+func (s *VersionService) CreateVersion(ctx context.Context, projectID, userID uuid.UUID, role string, req models.CreateVersionRequest) (*models.Version, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, role, actionManageProject); err != nil {
+		return nil, err
+	}
+
+	if req.Status == "" {
+		req.Status = models.VersionStatusOpen
+	}
+	if req.Sharing == "" {
+		req.Sharing = models.VersionSharingNone
+	}
+	name, err := s.validateVersion(ctx, role, req.Name, req.Status, req.Sharing)
+	if err != nil {
+		return nil, err
+	}
+	req.Name = name
+
+	return s.repo.Create(ctx, projectID, req)
+}
+
+// UpdateVersion saves the version; closing it with open tasks requires moving them elsewhere
+func (s *VersionService) UpdateVersion(ctx context.Context, projectID, versionID, userID uuid.UUID, role string, req models.UpdateVersionRequest) (*models.Version, error) {
+	if _, err := s.access.Require(ctx, projectID, userID, role, actionManageProject); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetByProject(ctx, projectID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	if req.Status == "" {
+		req.Status = current.Status
+	}
+	if req.Sharing == "" {
+		req.Sharing = current.Sharing
+	}
+	// Keeping an existing system-wide sharing does not need administrator rights
+	checkSharing := req.Sharing
+	if checkSharing == current.Sharing {
+		checkSharing = models.VersionSharingNone
+	}
+	name, err := s.validateVersion(ctx, role, req.Name, req.Status, checkSharing)
+	if err != nil {
+		return nil, err
+	}
+	req.Name = name
+
+	var moveTo *uuid.UUID
+	if req.Status == models.VersionStatusClosed && current.Status != models.VersionStatusClosed {
+		openTasks, err := s.repo.CountOpenTasks(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+		if openTasks > 0 {
+			if req.MoveOpenTasksTo == nil {
+				return nil, ErrVersionHasOpenTasks
+			}
+			if err := s.validateMoveTarget(ctx, projectID, versionID, *req.MoveOpenTasksTo); err != nil {
+				return nil, err
+			}
+			moveTo = req.MoveOpenTasksTo
+		}
+	}
+
+	updated, err := s.repo.Update(ctx, projectID, versionID, req, moveTo)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrVersionNotFound
+	}
+	return updated, nil
+}
+
+func (s *VersionService) DeleteVersion(ctx context.Context, projectID, versionID, userID uuid.UUID, role string) error {
+	if _, err := s.access.Require(ctx, projectID, userID, role, actionManageProject); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.Delete(ctx, projectID, versionID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrVersionNotFound
+	}
+	return nil
+}
+
+// GetRoadmap returns the project's versions with task progress; closed versions only when includeClosed is set
+func (s *VersionService) GetRoadmap(ctx context.Context, projectID, userID uuid.UUID, role string, includeClosed bool) ([]models.RoadmapVersion, error) {
+	if _, err := s.access.Role(ctx, projectID, userID, role); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.ListSharedWith(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(versions))
+	for _, v := range versions {
+		if includeClosed || v.Status != models.VersionStatusClosed {
+			ids = append(ids, v.ID)
+		}
+	}
+
+	progress, err := s.repo.GetTaskProgress(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	tasksByVersion := make(map[uuid.UUID][]models.VersionTaskProgress)
+	for _, p := range progress {
+		tasksByVersion[p.VersionID] = append(tasksByVersion[p.VersionID], p)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	roadmap := make([]models.RoadmapVersion, 0, len(ids))
+	for _, v := range versions {
+		if !includeClosed && v.Status == models.VersionStatusClosed {
+			continue
+		}
+		roadmap = append(roadmap, summarizeVersion(v, tasksByVersion[v.ID], today))
+	}
+	return roadmap, nil
+}
+
+// validateVersion checks the fields and returns the trimmed name
+func (s *VersionService) validateVersion(ctx context.Context, role, name, status, sharing string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > versionMaxNameLength {
+		return "", ErrInvalidVersionName
+	}
+
+	switch status {
+	case models.VersionStatusOpen, models.VersionStatusLocked, models.VersionStatusClosed:
+	default:
+		return "", ErrInvalidVersionStatus
+	}
+
+	switch sharing {
+	case models.VersionSharingNone, models.VersionSharingDescendants, models.VersionSharingHierarchy, models.VersionSharingTree:
+	case models.VersionSharingSystem:
+		allowed, err := s.access.HasPermission(ctx, role, models.PermissionProjectManageAll)
+		if err != nil {
+			return "", err
+		}
+		if !allowed {
+			return "", ErrSystemSharing
+		}
+	default:
+		return "", ErrInvalidSharing
+	}
+
+	return name, nil
+}
+
+// validateMoveTarget checks the target is another open version every affected task may use
+func (s *VersionService) validateMoveTarget(ctx context.Context, projectID, versionID, targetID uuid.UUID) error {
+	if targetID == versionID {
+		return ErrVersionMoveTarget
+	}
+
+	target, err := s.repo.GetSharedWith(ctx, targetID, projectID)
+	if err != nil {
+		return err
+	}
+	if target == nil || target.Status != models.VersionStatusOpen {
+		return ErrVersionMoveTarget
+	}
+
+	unshared, err := s.repo.CountOpenTasksNotSharedWith(ctx, versionID, targetID)
+	if err != nil {
+		return err
+	}
+	if unshared > 0 {
+		return ErrVersionMoveTarget
+	}
+	return nil
+}
+
+// summarizeVersion computes task counts and the done ratio weighted by estimated hours.
+// Tasks without an estimate weigh as much as the average estimate (1 hour when none is estimated)
+// and completed tasks count as 100% done.
+func summarizeVersion(v models.Version, tasks []models.VersionTaskProgress, today time.Time) models.RoadmapVersion {
+	summary := models.RoadmapVersion{Version: v, TotalTasks: len(tasks)}
+
+	estimatedCount := 0
+	for _, t := range tasks {
+		if t.Completed {
+			summary.ClosedTasks++
+		} else {
+			summary.OpenTasks++
+		}
+		if t.EstimatedHours != nil {
+			summary.EstimatedHours += *t.EstimatedHours
+			estimatedCount++
+		}
+	}
+
+	averageEstimate := 1.0
+	if estimatedCount > 0 && summary.EstimatedHours > 0 {
+		averageEstimate = summary.EstimatedHours / float64(estimatedCount)
+	}
+
+	var weighted, totalWeight, unweighted float64
+	for _, t := range tasks {
+		weight := averageEstimate
+		if t.EstimatedHours != nil {
+			weight = *t.EstimatedHours
+		}
+		done := float64(t.DoneRatio)
+		if t.Completed {
+			done = 100
+		}
+		weighted += weight * done
+		totalWeight += weight
+		unweighted += done
+	}
+	switch {
+	case totalWeight > 0:
+		summary.DoneRatio = int(math.Round(weighted / totalWeight))
+	case len(tasks) > 0:
+		// Every task is estimated at zero hours, so count them equally
+		summary.DoneRatio = int(math.Round(unweighted / float64(len(tasks))))
+	}
+
+	summary.Late = v.Status != models.VersionStatusClosed && v.DueDate != nil &&
+		v.DueDate.Before(today) && summary.OpenTasks > 0
+
+	return summary
+}
@@ -1,6 +1,24 @@
 const API_BASE = '/api';
 
-async function apiCall(endpoint, options = {}) {
+// Shared in-flight refresh so parallel 401s only rotate the refresh token once
+let refreshPromise = null;
+
+function refreshSession() {
+  if (!refreshPromise) {
+    refreshPromise = fetch(`${API_BASE}/auth/refresh`, {
+      method: 'POST',
+      credentials: 'include',
+    })
+      .then((response) => response.ok)
+      .catch(() => false)
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+}
+
+async function apiCall(endpoint, options = {}, retried = false) {
   const response = await fetch(`${API_BASE}${endpoint}`, {
     headers: {
       'Content-Type': 'application/json',
@@ -11,6 +29,11 @@ async function apiCall(endpoint, options = {}) {
   });
 
   if (!response.ok) {
+    // Access token expired - rotate the refresh token and retry once
+    if (response.status === 401 && !retried && (await refreshSession())) {
+      return apiCall(endpoint, options, true);
+    }
+
     // Handle 401 Unauthorized - user needs to login
     if (response.status === 401) {
       // Redirect to login by reloading the page
@@ -32,19 +55,53 @@ async function apiCall(endpoint, options = {}) {
 
 export const api = {
   projects: {
-    getAll: () => apiCall('/projects'),
+    // Filters: q, status, closed, owner_id, is_public, created_from/to, due_from/to, sort, order.
+    // Passing limit or cursor returns { projects, next_cursor, has_more } instead of an array.
+    getAll: (params = {}) => {
+      const query = new URLSearchParams(params).toString();
+      return apiCall(query ? `/projects?${query}` : '/projects');
+    },
     create: (data) => apiCall('/projects', { method: 'POST', body: JSON.stringify(data) }),
+    getTemplates: () => apiCall('/projects/templates'),
+    // Options: tasks, categories, members, versions, attachments; start_date shifts copied dates
+    copy: (id, data) => apiCall(`/projects/${id}/copy`, { method: 'POST', body: JSON.stringify(data) }),
     get: (id) => apiCall(`/projects/${id}`),
     update: (id, data) => apiCall(`/projects/${id}`, { method: 'PUT', body: JSON.stringify(data) }),
-    delete: (id) => apiCall(`/projects/${id}`, { method: 'DELETE' }),
+    delete: (id, cascade = false) => apiCall(`/projects/${id}${cascade ? '?cascade=true' : ''}`, { method: 'DELETE' }),
+    getMembers: (id) => apiCall(`/projects/${id}/members`),
+    addMember: (id, data) => apiCall(`/projects/${id}/members`, { method: 'POST', body: JSON.stringify(data) }),
+    updateMember: (id, userId, role) => apiCall(`/projects/${id}/members/${userId}`, { method: 'PUT', body: JSON.stringify({ role }) }),
+    removeMember: (id, userId) => apiCall(`/projects/${id}/members/${userId}`, { method: 'DELETE' }),
+    getGroups: (id) => apiCall(`/projects/${id}/groups`),
+    addGroup: (id, data) => apiCall(`/projects/${id}/groups`, { method: 'POST', body: JSON.stringify(data) }),
+    updateGroup: (id, groupId, role) => apiCall(`/projects/${id}/groups/${groupId}`, { method: 'PUT', body: JSON.stringify({ role }) }),
+    removeGroup: (id, groupId) => apiCall(`/projects/${id}/groups/${groupId}`, { method: 'DELETE' }),
+    getShareLinks: (id) => apiCall(`/projects/${id}/share-links`),
+    createShareLink: (id, data) => apiCall(`/projects/${id}/share-links`, { method: 'POST', body: JSON.stringify(data) }),
+    revokeShareLink: (id, linkId) => apiCall(`/projects/${id}/share-links/${linkId}`, { method: 'DELETE' }),
+    getShareLinkAccesses: (id, linkId) => apiCall(`/projects/${id}/share-links/${linkId}/accesses`),
+    getVersions: (id) => apiCall(`/projects/${id}/versions`),
+    createVersion: (id, data) => apiCall(`/projects/${id}/versions`, { method: 'POST', body: JSON.stringify(data) }),
+    updateVersion: (id, versionId, data) => apiCall(`/projects/${id}/versions/${versionId}`, { method: 'PUT', body: JSON.stringify(data) }),
+    deleteVersion: (id, versionId) => apiCall(`/projects/${id}/versions/${versionId}`, { method: 'DELETE' }),
+    getRoadmap: (id, includeClosed = false) => apiCall(`/projects/${id}/roadmap${includeClosed ? '?include_closed=true' : ''}`),
   },
   tasks: {
-    getByProject: (projectId, page = 1, limit = 10) => {
-      console.log(`API call: GET /projects/${projectId}/tasks?page=${page}&limit=${limit}`);
-      return apiCall(`/projects/${projectId}/tasks?page=${page}&limit=${limit}`);
+    getByProject: (projectId, page = 1, limit = 10, includeSubprojects = false) => {
+      const subprojects = includeSubprojects ? '&include_subprojects=true' : '';
+      console.log(`API call: GET /projects/${projectId}/tasks?page=${page}&limit=${limit}${subprojects}`);
+      return apiCall(`/projects/${projectId}/tasks?page=${page}&limit=${limit}${subprojects}`);
+    },
+    // Params: q, start_date_from/to, due_date_from/to (YYYY-MM-DD). Matching tasks carry rank and
+    // title_highlight, description_highlight and comment_highlight, escaped HTML with <mark> tags.
+    search: (projectId, params, page = 1, limit = 10, includeSubprojects = false) => {
+      const query = new URLSearchParams({ ...params, page, limit });
+      if (includeSubprojects) query.set('include_subprojects', 'true');
+      return apiCall(`/projects/${projectId}/tasks/search?${query.toString()}`);
     },
     create: (projectId, data) => apiCall(`/projects/${projectId}/tasks`, { method: 'POST', body: JSON.stringify(data) }),
     get: (id) => apiCall(`/tasks/${id}`),
+    getByKey: (key) => apiCall(`/tasks/by-key/${encodeURIComponent(key)}`),
     update: (id, data) => apiCall(`/tasks/${id}`, { method: 'PUT', body: JSON.stringify(data) }),
     toggleComplete: (id) => apiCall(`/tasks/${id}/complete`, { method: 'PATCH' }),
     delete: (id) => apiCall(`/tasks/${id}`, { method: 'DELETE' }),
@@ -66,10 +123,63 @@ export const api = {
     getById: (id) => apiCall(`/users/${id}`),
     updateRole: (id, role) => apiCall(`/users/${id}/role`, { method: 'PUT', body: JSON.stringify({ role }) }),
     updateActivation: (id, isActive) => apiCall(`/users/${id}/activate`, { method: 'PUT', body: JSON.stringify({ is_active: isActive }) }),
+    getSessions: (id) => apiCall(`/users/${id}/sessions`),
+    revokeSession: (id, sessionId) => apiCall(`/users/${id}/sessions/${sessionId}`, { method: 'DELETE' }),
+    revokeAllSessions: (id) => apiCall(`/users/${id}/sessions`, { method: 'DELETE' }),
+    getInvitations: () => apiCall('/users/invitations'),
+    invite: (data) => apiCall('/users/invitations', { method: 'POST', body: JSON.stringify(data) }),
+    resendInvitation: (id) => apiCall(`/users/invitations/${id}/resend`, { method: 'POST' }),
+    revokeInvitation: (id) => apiCall(`/users/invitations/${id}`, { method: 'DELETE' }),
+    getRoles: () => apiCall('/users/roles'),
+  },
+  groups: {
+    getAll: () => apiCall('/groups'),
+  },
+  projectStatuses: {
+    getAll: () => apiCall('/project-statuses'),
+  },
+  trash: {
+    // all=true lists everyone's deletions (trash.manage)
+    get: (all = false) => apiCall(`/trash${all ? '?all=true' : ''}`),
+    restoreProject: (id) => apiCall(`/trash/projects/${id}/restore`, { method: 'POST' }),
+    restoreTask: (id) => apiCall(`/trash/tasks/${id}/restore`, { method: 'POST' }),
+  },
+  admin: {
+    getAudit: (params = {}) => {
+      const query = new URLSearchParams(params).toString();
+      return apiCall(`/admin/audit?${query}`);
+    },
+    auditExportUrl: (params = {}) => {
+      const query = new URLSearchParams({ ...params, format: 'csv' }).toString();
+      return `${API_BASE}/admin/audit?${query}`;
+    },
+    verifyAudit: () => apiCall('/admin/audit/verify'),
+    getRoles: () => apiCall('/admin/roles'),
+    createRole: (data) => apiCall('/admin/roles', { method: 'POST', body: JSON.stringify(data) }),
+    updateRole: (name, data) => apiCall(`/admin/roles/${name}`, { method: 'PUT', body: JSON.stringify(data) }),
+    deleteRole: (name) => apiCall(`/admin/roles/${name}`, { method: 'DELETE' }),
+    getGroups: () => apiCall('/admin/groups'),
+    createGroup: (data) => apiCall('/admin/groups', { method: 'POST', body: JSON.stringify(data) }),
+    updateGroup: (id, data) => apiCall(`/admin/groups/${id}`, { method: 'PUT', body: JSON.stringify(data) }),
+    deleteGroup: (id) => apiCall(`/admin/groups/${id}`, { method: 'DELETE' }),
+    getGroupMembers: (id) => apiCall(`/admin/groups/${id}/members`),
+    addGroupMember: (id, data) => apiCall(`/admin/groups/${id}/members`, { method: 'POST', body: JSON.stringify(data) }),
+    removeGroupMember: (id, userId) => apiCall(`/admin/groups/${id}/members/${userId}`, { method: 'DELETE' }),
+    getProjectStatuses: () => apiCall('/admin/project-statuses'),
+    createProjectStatus: (data) => apiCall('/admin/project-statuses', { method: 'POST', body: JSON.stringify(data) }),
+    updateProjectStatus: (name, data) => apiCall(`/admin/project-statuses/${encodeURIComponent(name)}`, { method: 'PUT', body: JSON.stringify(data) }),
+    deleteProjectStatus: (name) => apiCall(`/admin/project-statuses/${encodeURIComponent(name)}`, { method: 'DELETE' }),
   },
   dashboard: {
     get: () => apiCall('/dashboard'),
   },
+  // Anonymous read-only access through a project share link
+  share: {
+    getProject: (token) => apiCall(`/share/${token}`),
+    getComments: (token, taskId) => apiCall(`/share/${token}/tasks/${taskId}/comments`),
+    getAttachments: (token, taskId) => apiCall(`/share/${token}/tasks/${taskId}/attachments`),
+    downloadUrl: (token, attachmentId) => `${API_BASE}/share/${token}/attachments/${attachmentId}/download`,
+  },
   meetings: {
     getNext: () => apiCall('/meetings/next'),
     create: (data) => apiCall('/meetings', { method: 'POST', body: JSON.stringify(data) }),
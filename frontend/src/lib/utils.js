@@ -162,3 +162,13 @@ export function isoStringToDate(isoString) {
     return null;
   }
 }
+
+/**
+ * Display label for a system role; custom roles are shown by name
+ * @param {string} role - Role name (e.g., "admin")
+ * @returns {string} Persian label for built-in roles, otherwise the name itself
+ */
+export function systemRoleLabel(role) {
+  const labels = { admin: 'ادمین', user: 'کاربر عادی' };
+  return labels[role] || role;
+}
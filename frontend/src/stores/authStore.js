@@ -57,10 +57,20 @@ function createAuthStore() {
 
         const data = await response.json();
 
+        // Password accepted but expired; a new one must be chosen first
+        if (!data.success && data.error?.code === 'PASSWORD_EXPIRED') {
+          return { success: false, passwordExpired: true, challengeToken: data.error.challenge_token, error: data.error.message };
+        }
+
         if (!data.success) {
           throw new Error(data.error.message || 'ورود ناموفق بود');
         }
 
+        // Password accepted but the account needs a TOTP/recovery code
+        if (data.data.two_factor_required) {
+          return { success: false, twoFactorRequired: true, challengeToken: data.data.challenge_token };
+        }
+
         set({
           user: data.data.user,
           isAuthenticated: true,
@@ -85,13 +95,88 @@ function createAuthStore() {
       }
     },
 
+    // Complete a two-factor login with the challenge token from login()
+    async verifyTwoFactor(challengeToken, code) {
+      try {
+        const response = await fetch('/api/auth/login/2fa', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          credentials: 'include',
+          body: JSON.stringify({ challenge_token: challengeToken, code }),
+        });
+
+        const data = await response.json();
+
+        if (!data.success) {
+          throw new Error(data.error.message || 'ورود ناموفق بود');
+        }
+
+        set({
+          user: data.data.user,
+          isAuthenticated: true,
+          isLoading: false,
+        });
+        await projects.load().catch((err) => console.error('[authStore] failed to load projects after login:', err));
+
+        return { success: true };
+      } catch (error) {
+        return { success: false, error: error.message };
+      }
+    },
+
+    // Replace an expired password with the challenge token from login()
+    async changeExpiredPassword(challengeToken, newPassword) {
+      try {
+        const response = await fetch('/api/auth/login/password', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          credentials: 'include',
+          body: JSON.stringify({ challenge_token: challengeToken, new_password: newPassword }),
+        });
+
+        const data = await response.json();
+
+        if (!data.success) {
+          throw new Error(data.error.message || 'تغییر رمز عبور ناموفق بود');
+        }
+
+        if (data.data.two_factor_required) {
+          return { success: false, twoFactorRequired: true, challengeToken: data.data.challenge_token };
+        }
+
+        set({
+          user: data.data.user,
+          isAuthenticated: true,
+          isLoading: false,
+        });
+        await projects.load().catch((err) => console.error('[authStore] failed to load projects after login:', err));
+
+        return { success: true };
+      } catch (error) {
+        return { success: false, error: error.message };
+      }
+    },
+
     // Check if user is already authenticated
     async checkAuth() {
       try {
-        const response = await fetch('/api/auth/me', {
+        let response = await fetch('/api/auth/me', {
           credentials: 'include', // Send cookies
         });
 
+        // Access token expired - try to rotate the refresh token once
+        if (response.status === 401) {
+          const refreshResponse = await fetch('/api/auth/refresh', {
+            method: 'POST',
+            credentials: 'include',
+          });
+          if (refreshResponse.ok) {
+            response = await fetch('/api/auth/me', {
+              credentials: 'include',
+            });
+          }
+        }
+
         if (response.ok) {
           const data = await response.json();
           // Support two shapes: { data: { user: { ... } } } or { data: { user_id, role } }
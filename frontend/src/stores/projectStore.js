@@ -20,7 +20,17 @@ function createProjectStore() {
     },
     create: async (projectData) => {
       const project = await api.projects.create(projectData);
-      update(currentProjects => [project, ...(currentProjects || [])]);
+      if (!project.is_template) {
+        update(currentProjects => [project, ...(currentProjects || [])]);
+      }
+      return project;
+    },
+    copy: async (id, copyData) => {
+      const project = await api.projects.copy(id, copyData);
+      // Templates stay out of the regular project list
+      if (!project.is_template) {
+        update(currentProjects => [project, ...(currentProjects || [])]);
+      }
       return project;
     },
     update: async (id, projectData) => {
@@ -28,9 +38,23 @@ function createProjectStore() {
       update(currentProjects => (currentProjects || []).map(p => p.id === id ? project : p));
       return project;
     },
-    delete: async (id) => {
-      await api.projects.delete(id);
-      update(currentProjects => (currentProjects || []).filter(p => p.id !== id));
+    delete: async (id, cascade = false) => {
+      await api.projects.delete(id, cascade);
+      // A cascading delete also removes every subproject
+      update(currentProjects => {
+        const removed = new Set([id]);
+        let grew = true;
+        while (grew) {
+          grew = false;
+          for (const p of currentProjects || []) {
+            if (p.parent_id && removed.has(p.parent_id) && !removed.has(p.id)) {
+              removed.add(p.id);
+              grew = true;
+            }
+          }
+        }
+        return (currentProjects || []).filter(p => !removed.has(p.id));
+      });
     }
   };
 }
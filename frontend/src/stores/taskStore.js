@@ -9,17 +9,29 @@ function createTaskStore() {
     total: 0,
     hasMore: false,
     currentProjectId: null,
+    includeSubprojects: false,
+    // Server-side search parameters; null lists the project's tasks
+    searchParams: null,
     loadingMore: false
   };
 
   const { subscribe, set, update } = writable(initialState);
 
-  const load = async (projectId, reset = false) => {
+  // Searches are sent as the user types; only the latest load may replace the list
+  let latestLoad = 0;
+
+  const fetchPage = (projectId, page, pageSize, includeSubprojects, searchParams) => searchParams
+    ? api.tasks.search(projectId, searchParams, page, pageSize, includeSubprojects)
+    : api.tasks.getByProject(projectId, page, pageSize, includeSubprojects);
+
+  const load = async (projectId, reset = false, includeSubprojects = false, searchParams = null) => {
     try {
       // Always update currentProjectId when loading, and reset page to 1
-      update(state => ({ ...state, currentPage: 1, currentProjectId: projectId, loadingMore: true }));
+      update(state => ({ ...state, currentPage: 1, currentProjectId: projectId, includeSubprojects, searchParams, loadingMore: true }));
 
-      const response = await api.tasks.getByProject(projectId, 1, initialState.pageSize);
+      const thisLoad = ++latestLoad;
+      const response = await fetchPage(projectId, 1, initialState.pageSize, includeSubprojects, searchParams);
+      if (thisLoad !== latestLoad) return;
       console.log('Initial load response:', { projectId, pageSize: initialState.pageSize, taskCount: response.tasks?.length, total: response.total, hasMore: response.has_more });
 
       update(state => ({
@@ -71,7 +83,9 @@ function createTaskStore() {
       
       const nextPage = latestState.currentPage + 1;
       console.log('Fetching page:', nextPage, 'for project:', latestState.currentProjectId);
-      const response = await api.tasks.getByProject(latestState.currentProjectId, nextPage, latestState.pageSize);
+      const thisLoad = latestLoad;
+      const response = await fetchPage(latestState.currentProjectId, nextPage, latestState.pageSize, latestState.includeSubprojects, latestState.searchParams);
+      if (thisLoad !== latestLoad) return;
       const newTasks = Array.isArray(response.tasks) ? response.tasks : [];
       console.log('LoadMore response:', { nextPage, tasksReceived: newTasks.length, hasMore: response.has_more });
 
@@ -104,7 +118,9 @@ function createTaskStore() {
     reset,
     create: async (projectId, taskData) => {
       const task = await api.tasks.create(projectId, taskData);
-      await load(projectId, true);
+      let current;
+      subscribe(state => current = state)();
+      await load(projectId, true, current.includeSubprojects, current.searchParams);
       return task;
     },
     update: async (id, taskData) => {